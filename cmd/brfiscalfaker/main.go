@@ -6,32 +6,59 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/mayckol/brfiscalfaker/pkg/anonymize"
 	"github.com/mayckol/brfiscalfaker/pkg/nfs"
+	"github.com/mayckol/brfiscalfaker/pkg/universe"
 
 	"golang.org/x/term"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "anonymize" {
+		os.Exit(runAnonymize(os.Args[2:]))
+	}
+
 	cpf := flag.String("cpf", "", "Optional CPF to include in the invoice")
 	cnpj := flag.String("cnpj", "", "Optional CNPJ to include in the invoice")
-	templateType := flag.String("type", "NFCe", "Type of invoice to generate (CFe, NFe, NFCe, NFeDevolucao)")
+	templateType := flag.String("type", "NFCe", "Type of invoice to generate (CFe, NFe, NFCe, NFeDevolucao, NFeComplementar, NFeAjuste)")
 	blockTags := flag.String("block-tags", "", "Comma-separated list of placeholders to block (e.g., emitCNPJ,CNPJ,CPF)")
+	icms := flag.String("icms", "", "Optional comma-separated list of ICMS situations, one item each (e.g., CST00,CST10,ICMSRandom)")
+	pis := flag.String("pis", "", "Optional comma-separated list of PIS/COFINS situations, one item each (e.g., PISAliq,PISST,PISRandom)")
+	ipi := flag.String("ipi", "", "Optional comma-separated list of IPI situations, one item each (e.g., IPITrib,IPINT)")
+	services := flag.String("services", "", "Optional comma-separated list of item numbers that are services taxed by the ISSQN (e.g., 2,3)")
+	taxReform := flag.String("tax-reform", "", "Optional tax reform layout adding the IBS, CBS and IS groups (RTC2026, RTC2027)")
+	operation := flag.String("operation", "", "Optional NF-e operation profile (e.g., VendaInterestadual, Transferencia, DevolucaoCompra, Exportacao)")
+	sector := flag.String("sector", "", "Optional product sectors of the goods items, one item each in turn (e.g., Fuel, Medicine|NewVehicle|Weapon)")
+	payments := flag.String("payments", "", "Optional comma-separated list of means of payment, one detPag each (e.g., Cash,CreditCard,PIX,Boleto,None,Random)")
+	marketplace := flag.Bool("marketplace", false, "Issue a sale intermediated by a marketplace (NF-e) or delivery app (NFC-e), with the infIntermed group")
+	marketplaceCNPJ := flag.String("marketplace-cnpj", "", "Optional CNPJ of the marketplace of the sale (implies --marketplace)")
+	marketplaceID := flag.String("marketplace-id", "", "Optional identifier of the seller on the marketplace, idCadIntTran (implies --marketplace)")
+	emitter := flag.String("emitter", "", "Optional emitter profile (e.g., SimplesNacional, SimplesSublimite, RegimeNormal, MEI, ProdutorRural)")
+	contingency := flag.String("contingency", "", "Optional contingency mode (e.g., FSDA, SVCAN, SVCRS, EPEC, OfflineNFCe)")
+	emissionDate := flag.String("emission-date", "", "Optional emission moment (RFC 3339, e.g. 2025-01-31T10:00:00-03:00, or a date in Brasília time)")
+	emissionFrom := flag.String("emission-from", "", "Optional start of the emission range (RFC 3339 or a date in Brasília time)")
+	emissionTo := flag.String("emission-to", "", "Optional end of the emission range, inclusive (RFC 3339 or a date in Brasília time)")
+	numbering := flag.String("numbering", "", "Optional series and number of the invoice, separated by a comma (e.g., 1,1500)")
+	universeSeed := flag.String("universe", "", "Optional seed of a universe of companies and consumers that issue and receive the invoice; the same seed gives the same parties")
+	companies := flag.Int("companies", 20, "Number of companies of the universe (with --universe)")
+	consumers := flag.Int("consumers", 100, "Number of consumers of the universe (with --universe)")
+	recipient := flag.String("recipient", "", "Optional kind of recipient: CPF, CNPJ, Foreign (idEstrangeiro) or None (NFC-e and CF-e without dest)")
+	reference := flag.String("reference", "", "Optional NF-e or NFC-e file referenced by an NFeDevolucao, NFeComplementar or NFeAjuste, whose items are returned, complemented or adjusted")
+	partialReturn := flag.Int("partial-return", 100, "Percentage of the quantity of each referenced item that is returned (1 to 100)")
+	fault := flag.String("fault", "", "Optional fault to inject for negative tests (e.g., FaultTotalMismatch); the expected rejection is printed to stderr")
 
 	flag.Parse()
 
-	var tt nfs.TemplateType
-	switch *templateType {
-	case "CFe":
-		tt = nfs.CFe
-	case "NFe":
-		tt = nfs.NFe
-	case "NFCe":
-		tt = nfs.NFCe
-	case "NFeDevolucao":
-		tt = nfs.NFeDevolucao
-	default:
+	tt, err := nfs.ParseTemplateType(*templateType)
+	if err != nil {
 		log.Fatalf("Unsupported template type: %s", *templateType)
 	}
 
@@ -59,11 +86,173 @@ func main() {
 		}
 	}
 
+	if *icms != "" {
+		var situations []nfs.ICMSSituation
+		for _, name := range splitAndTrim(*icms, ",") {
+			situation, err := nfs.ParseICMSSituation(name)
+			if err != nil {
+				log.Fatalf("Unsupported ICMS situation: %s", name)
+			}
+			situations = append(situations, situation)
+		}
+		options = append(options, nfs.WithICMS(situations...))
+	}
+
+	if *pis != "" {
+		var situations []nfs.PISSituation
+		for _, name := range splitAndTrim(*pis, ",") {
+			situation, err := nfs.ParsePISSituation(name)
+			if err != nil {
+				log.Fatalf("Unsupported PIS situation: %s", name)
+			}
+			situations = append(situations, situation)
+		}
+		options = append(options, nfs.WithPIS(situations...))
+	}
+
+	if *ipi != "" {
+		var situations []nfs.IPISituation
+		for _, name := range splitAndTrim(*ipi, ",") {
+			situation, err := nfs.ParseIPISituation(name)
+			if err != nil {
+				log.Fatalf("Unsupported IPI situation: %s", name)
+			}
+			situations = append(situations, situation)
+		}
+		options = append(options, nfs.WithIPI(situations...))
+	}
+
+	if *services != "" {
+		var numbers []int
+		for _, value := range splitAndTrim(*services, ",") {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				log.Fatalf("Invalid service item number: %s", value)
+			}
+			numbers = append(numbers, number)
+		}
+		options = append(options, nfs.WithServiceItems(numbers...))
+	}
+
+	if *taxReform != "" {
+		layout, err := nfs.ParseTaxReformLayout(*taxReform)
+		if err != nil {
+			log.Fatalf("Unsupported tax reform layout: %s", *taxReform)
+		}
+		options = append(options, nfs.WithTaxReform(layout))
+	}
+
+	if *operation != "" {
+		op, err := nfs.ParseOperation(*operation)
+		if err != nil {
+			log.Fatalf("Unsupported operation: %s", *operation)
+		}
+		options = append(options, nfs.WithOperation(op))
+	}
+
+	if *sector != "" {
+		sectors, err := nfs.ParseProductSector(*sector)
+		if err != nil {
+			log.Fatalf("Unsupported product sector: %s", *sector)
+		}
+		options = append(options, nfs.WithProductSector(sectors))
+	}
+
+	if *payments != "" {
+		var methods []nfs.PaymentMethod
+		for _, name := range splitAndTrim(*payments, ",") {
+			method, err := nfs.ParsePaymentMethod(name)
+			if err != nil {
+				log.Fatalf("Unsupported payment method: %s", name)
+			}
+			methods = append(methods, method)
+		}
+		options = append(options, nfs.WithPayments(methods...))
+	}
+
+	if *marketplace || *marketplaceCNPJ != "" || *marketplaceID != "" {
+		options = append(options, nfs.WithMarketplace(*marketplaceCNPJ, *marketplaceID))
+	}
+
+	if *emitter != "" {
+		profile, err := nfs.ParseEmitterProfile(*emitter)
+		if err != nil {
+			log.Fatalf("Unsupported emitter profile: %s", *emitter)
+		}
+		options = append(options, nfs.WithEmitterProfile(profile))
+	}
+
+	if *contingency != "" {
+		mode, err := nfs.ParseContingency(*contingency)
+		if err != nil {
+			log.Fatalf("Unsupported contingency mode: %s", *contingency)
+		}
+		options = append(options, nfs.WithContingency(mode))
+	}
+
+	emission, err := emissionDateOptions(*emissionDate, *emissionFrom, *emissionTo)
+	if err != nil {
+		log.Fatalf("Invalid emission date: %v", err)
+	}
+	options = append(options, emission...)
+
+	if *numbering != "" {
+		parts := splitAndTrim(*numbering, ",")
+		if len(parts) != 2 {
+			log.Fatalf("Invalid numbering: %s", *numbering)
+		}
+		serie, err := strconv.Atoi(parts[0])
+		if err != nil {
+			log.Fatalf("Invalid series: %s", parts[0])
+		}
+		number, err := strconv.Atoi(parts[1])
+		if err != nil {
+			log.Fatalf("Invalid number: %s", parts[1])
+		}
+		options = append(options, nfs.WithNumbering(serie, number))
+	}
+
+	if *universeSeed != "" {
+		seed, err := strconv.ParseInt(*universeSeed, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid universe seed: %s", *universeSeed)
+		}
+		options = append(options, nfs.WithUniverse(universe.New(seed, *companies, *consumers)))
+	}
+
+	if *recipient != "" {
+		kind, err := nfs.ParseRecipientKind(*recipient)
+		if err != nil {
+			log.Fatalf("Unsupported recipient kind: %s", *recipient)
+		}
+		options = append(options, nfs.WithRecipientKind(kind))
+	}
+
+	if *reference != "" {
+		original, err := os.ReadFile(*reference)
+		if err != nil {
+			log.Fatalf("Failed to read the referenced invoice: %v", err)
+		}
+		options = append(options, nfs.WithReferencedInvoice(original), nfs.WithPartialReturn(*partialReturn))
+	}
+
+	var f nfs.Fault
+	if *fault != "" {
+		if f, err = nfs.ParseFault(*fault); err != nil {
+			log.Fatalf("Unsupported fault: %s", *fault)
+		}
+		options = append(options, nfs.WithFault(f))
+	}
+
 	// Generate the invoice
 	xmlBytes, err := generator.Generate(options...)
 	if err != nil {
 		log.Fatalf("Failed to generate invoice: %v", err)
 	}
+	if *fault != "" {
+		report := f.Report(xmlBytes)
+		fmt.Fprintf(os.Stderr, "%s: <%s> corrupted, expected cStat %s %s\n", report.Fault, report.Element, report.CStat, report.Message)
+	}
 
 	// Determine if running via Docker by checking if stdout is a terminal
 	if isTerminal(os.Stdout) {
@@ -75,11 +264,148 @@ func main() {
 	}
 }
 
+// runValidate implements the "validate" subcommand: brfiscalfaker validate [--type NFe] file.xml.
+// It prints every schema violation found and returns the process exit code.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	templateType := fs.String("type", "", "Type of the document (CFe, NFe, NFCe, NFeDevolucao, NFeComplementar, NFeAjuste); detected from the XML when omitted")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: brfiscalfaker validate [--type TYPE] file.xml")
+		return 2
+	}
+
+	xmlBytes, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		log.Printf("Failed to read document: %v", err)
+		return 2
+	}
+
+	var tt nfs.TemplateType
+	if *templateType != "" {
+		tt, err = nfs.ParseTemplateType(*templateType)
+	} else {
+		tt, err = nfs.DetectTemplateType(xmlBytes)
+	}
+	if err != nil {
+		log.Printf("Failed to determine the document type: %v", err)
+		return 2
+	}
+
+	errs := nfs.Validate(tt, xmlBytes)
+	for _, e := range errs {
+		fmt.Println(e.Error())
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "%s: %d schema violation(s) found (%s)\n", fs.Arg(0), len(errs), tt)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: valid %s document\n", fs.Arg(0), tt)
+	return 0
+}
+
+// runAnonymize implements the "anonymize" subcommand: brfiscalfaker anonymize [--resign] in.xml...
+// A single document is written to stdout; several ones are written to the output directory, with the
+// same values replaced by the same fakes in all of them. It returns the process exit code.
+func runAnonymize(args []string) int {
+	fs := flag.NewFlagSet("anonymize", flag.ExitOnError)
+	resign := fs.Bool("resign", false, "Sign the documents again with new mock values (DigestValue, SignatureValue, digVal)")
+	seed := fs.Int64("seed", 0, "Optional seed of the fakes; the same seed and documents give the same fakes")
+	out := fs.String("out", "", "Directory where the anonymized documents are written, with their file names (required for several documents)")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 || (fs.NArg() > 1 && *out == "") {
+		fmt.Fprintln(os.Stderr, "Usage: brfiscalfaker anonymize [--resign] [--seed N] [--out DIR] file.xml...")
+		return 2
+	}
+
+	var options []anonymize.Option
+	if *seed != 0 {
+		options = append(options, anonymize.WithSeed(*seed))
+	}
+	if *resign {
+		options = append(options, anonymize.WithResign())
+	}
+	anonymizer := anonymize.New(options...)
+	for _, path := range fs.Args() {
+		document, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Failed to read document: %v", err)
+			return 2
+		}
+		anonymized, err := anonymizer.Anonymize(document)
+		if err != nil {
+			log.Printf("Failed to anonymize %s: %v", path, err)
+			return 1
+		}
+		if *out == "" {
+			os.Stdout.Write(anonymized)
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(*out, filepath.Base(path)), anonymized, 0o644); err != nil {
+			log.Printf("Failed to write document: %v", err)
+			return 2
+		}
+	}
+	return 0
+}
+
 // isTerminal checks if the file descriptor is a terminal.
 func isTerminal(f *os.File) bool {
 	return term.IsTerminal(int(f.Fd()))
 }
 
+// emissionDateOptions returns the option of the --emission-date flag, or of the --emission-from and
+// --emission-to flags, which are given together and never with --emission-date.
+func emissionDateOptions(date, from, to string) ([]nfs.Option, error) {
+	switch {
+	case date != "" && (from != "" || to != ""):
+		return nil, fmt.Errorf("--emission-date cannot be combined with --emission-from or --emission-to")
+	case from != "" && to == "":
+		return nil, fmt.Errorf("--emission-from requires --emission-to")
+	case to != "" && from == "":
+		return nil, fmt.Errorf("--emission-to requires --emission-from")
+	}
+
+	if date != "" {
+		moment, err := parseEmissionDate(date, false)
+		if err != nil {
+			return nil, fmt.Errorf("%s is neither an RFC 3339 moment nor a date", date)
+		}
+		return []nfs.Option{nfs.WithEmissionDate(moment)}, nil
+	}
+	if from == "" {
+		return nil, nil
+	}
+	start, err := parseEmissionDate(from, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither an RFC 3339 moment nor a date", from)
+	}
+	end, err := parseEmissionDate(to, true)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither an RFC 3339 moment nor a date", to)
+	}
+	return []nfs.Option{nfs.WithEmissionDateRange(start, end)}, nil
+}
+
+// parseEmissionDate parses an RFC 3339 moment or a date in Brasília time (-03:00), at the start of
+// the day or, for the end of a range, at its last second.
+func parseEmissionDate(value string, endOfDay bool) (time.Time, error) {
+	if moment, err := time.Parse(time.RFC3339, value); err == nil {
+		return moment, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.FixedZone("BRT", -3*60*60))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		day = day.Add(24*time.Hour - time.Second)
+	}
+	return day, nil
+}
+
 // splitAndTrim splits a string by a separator and trims whitespace from each element.
 func splitAndTrim(s string, sep string) []string {
 	raw := strings.Split(s, sep)
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mayckol/brfiscalfaker/pkg/nfs"
+)
+
+func TestEmissionDateOptions(t *testing.T) {
+	dhEmiRe := regexp.MustCompile(`<dhEmi>([^<]+)</dhEmi>`)
+	tests := []struct {
+		name     string
+		date     string
+		from     string
+		to       string
+		earliest string
+		latest   string
+	}{
+		{"None", "", "", "", "", ""},
+		{"Date", "2025-01-31T10:00:00-03:00", "", "", "2025-01-31T10:00:00-03:00", "2025-01-31T10:00:00-03:00"},
+		{"Day", "2025-01-31", "", "", "2025-01-31T00:00:00-03:00", "2025-01-31T00:00:00-03:00"},
+		{"Range", "", "2025-01-01", "2025-01-31", "2025-01-01T00:00:00-03:00", "2025-01-31T23:59:59-03:00"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			options, err := emissionDateOptions(tc.date, tc.from, tc.to)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if tc.earliest == "" {
+				if len(options) != 0 {
+					t.Errorf("Expected no options, got %d", len(options))
+				}
+				return
+			}
+			xmlBytes, err := nfs.NewNFCeGenerator().Generate(options...)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			dhEmi, _ := time.Parse(time.RFC3339, string(dhEmiRe.FindSubmatch(xmlBytes)[1]))
+			earliest, _ := time.Parse(time.RFC3339, tc.earliest)
+			latest, _ := time.Parse(time.RFC3339, tc.latest)
+			if dhEmi.Before(earliest) || dhEmi.After(latest) {
+				t.Errorf("Expected the emission from %v to %v, got %v", earliest, latest, dhEmi)
+			}
+		})
+	}
+}
+
+func TestEmissionDateOptions_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		date    string
+		from    string
+		to      string
+		message string
+	}{
+		{"FromWithoutTo", "", "2025-01-01", "", "--emission-from requires --emission-to"},
+		{"ToWithoutFrom", "", "", "2025-01-31", "--emission-to requires --emission-from"},
+		{"DateWithFrom", "2025-01-15", "2025-01-01", "", "cannot be combined"},
+		{"DateWithTo", "2025-01-15", "", "2025-01-31", "cannot be combined"},
+		{"DateWithRange", "2025-01-15", "2025-01-01", "2025-01-31", "cannot be combined"},
+		{"InvalidDate", "31/01/2025", "", "", "31/01/2025"},
+		{"InvalidFrom", "", "yesterday", "2025-01-31", "yesterday"},
+		{"InvalidTo", "", "2025-01-01", "tomorrow", "tomorrow"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := emissionDateOptions(tc.date, tc.from, tc.to)
+			if err == nil || !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("Expected an error with %q, got %v", tc.message, err)
+			}
+		})
+	}
+}
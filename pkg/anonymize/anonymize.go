@@ -0,0 +1,474 @@
+// Package anonymize replaces the personal data of real NF-e, NFC-e and CF-e documents with valid fakes,
+// keeping their items and totals, so that they can be shared as fixtures.
+package anonymize
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+	"github.com/mayckol/brfiscalfaker/pkg/br_locale"
+	"github.com/mayckol/brfiscalfaker/pkg/nfs"
+)
+
+// homologationRecipientName is the recipient name required by SEFAZ in the homologation environment,
+// which is kept.
+const homologationRecipientName = "NF-E EMITIDA EM AMBIENTE DE HOMOLOGACAO - SEM VALOR FISCAL"
+
+// parties are the elements that identify a party of the document, whose data is replaced together.
+var parties = map[string]bool{
+	"emit":        true,
+	"dest":        true,
+	"retirada":    true,
+	"entrega":     true,
+	"autXML":      true,
+	"transporta":  true,
+	"infIntermed": true,
+	"infRespTec":  true,
+	"avulsa":      true,
+}
+
+// freeTexts are the elements of free text, where the replaced values are replaced too. The brand of the
+// volumes (marca) is often the name of the emitter.
+var freeTexts = map[string]bool{
+	"infCpl":     true,
+	"infAdFisco": true,
+	"xTexto":     true,
+	"xTextoDet":  true,
+	"xObs":       true,
+	"marca":      true,
+}
+
+// accessKeyRe matches the 44 digits of an access key.
+var accessKeyRe = regexp.MustCompile(`^[0-9]{44}$`)
+
+// Option defines a function type for Anonymizer configuration options.
+type Option func(*Anonymizer)
+
+// WithSeed returns an Option that draws the fakes from the seed, so that the same seed and documents
+// give the same fakes.
+func WithSeed(seed int64) Option {
+	return func(a *Anonymizer) {
+		a.seed = seed
+	}
+}
+
+// WithResign returns an Option that signs the documents again with new mock values, as generated
+// documents are: DigestValue, SignatureValue and X509Certificate, the digVal of the authorization
+// protocol and the signAC and assinaturaQRCODE of the CF-e. Without it, the original values are kept,
+// and no longer match the document.
+func WithResign() Option {
+	return func(a *Anonymizer) {
+		a.resign = true
+	}
+}
+
+// Anonymizer replaces the personal data of documents: CPF, CNPJ, foreign IDs, IE, names, addresses,
+// phones, emails and plates. A value found in several documents gets the same fake in all of them, and the
+// establishments of a company keep the same CNPJ root. The access keys are rebuilt with the fake CNPJ
+// or CPF of the emitter, in the Id, chNFe and Reference URI of the document and in the keys of the
+// documents it references. An Anonymizer is safe for concurrent use.
+type Anonymizer struct {
+	mu        sync.Mutex
+	seed      int64
+	resign    bool
+	rand      *rand.Rand
+	documents *br_documents.Generator
+	locale    *br_locale.Generator
+	fakes     map[string]string // the fake of each value, by kind and value
+	used      map[string]bool   // the fake CPFs and CNPJ roots given so far
+}
+
+// New returns an Anonymizer with the options.
+func New(options ...Option) *Anonymizer {
+	a := &Anonymizer{seed: time.Now().UnixNano()}
+	for _, option := range options {
+		option(a)
+	}
+	a.rand = rand.New(rand.NewSource(a.seed))
+	a.documents = br_documents.New(a.rand.Int63())
+	a.locale = br_locale.New(a.rand.Int63())
+	a.fakes = make(map[string]string)
+	a.used = make(map[string]bool)
+	return a
+}
+
+// textNode is the text of an element of the document, at the byte offsets of its raw value.
+type textNode struct {
+	name  string
+	start int64
+	end   int64
+	value string
+	party *party
+}
+
+// party holds what is known about a party of the document: its state and whether it is a person.
+type party struct {
+	uf     string
+	person bool
+	name   string // the fake xNome
+}
+
+// Anonymize returns the document with its personal data replaced. Values other than the replaced
+// ones, such as the products and totals, are kept as they are, byte for byte.
+func (a *Anonymizer) Anonymize(document []byte) ([]byte, error) {
+	tt, err := nfs.DetectTemplateType(document)
+	if err != nil {
+		return nil, err
+	}
+	nodes, keys, err := scanDocument(document)
+	if err != nil {
+		return nil, fmt.Errorf("malformed XML: %v", err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("the %v document has no access key", tt)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, node := range nodes {
+		if accessKeyRe.MatchString(node.value) && br_documents.ValidateAccessKey(node.value) {
+			keys = append(keys, node.value)
+		}
+	}
+	newKeys := make(map[string]string)
+	for _, key := range keys {
+		newKeys[key] = a.accessKey(key)
+	}
+
+	var result bytes.Buffer
+	var offset int64
+	digest := ""
+	for _, node := range nodes {
+		value, ok := a.replace(node, newKeys[keys[0]], &digest)
+		if !ok || value == node.value {
+			continue
+		}
+		result.Write(document[offset:node.start])
+		result.WriteString(html.EscapeString(value))
+		offset = node.end
+	}
+	result.Write(document[offset:])
+
+	// The keys are replaced wherever they are: in attributes (Id, URI), elements and the QR Code.
+	anonymized := result.String()
+	for _, key := range sortedKeys(newKeys) {
+		anonymized = strings.ReplaceAll(anonymized, key, newKeys[key])
+	}
+	return []byte(anonymized), nil
+}
+
+// scanDocument returns the text nodes of the document, with the party they belong to, and the access
+// key of the document, taken from the Id of infNFe or infCFe.
+func scanDocument(document []byte) ([]textNode, []string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var nodes []textNode
+	var keys []string
+	var stack []string
+	var partyStack []*party
+	for {
+		start := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			current := (*party)(nil)
+			if len(partyStack) > 0 {
+				current = partyStack[len(partyStack)-1]
+			}
+			if parties[t.Name.Local] {
+				current = &party{}
+			}
+			if t.Name.Local == "infNFe" || t.Name.Local == "infCFe" {
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "Id" && len(attr.Value) == 47 {
+						keys = append(keys, attr.Value[3:])
+					}
+				}
+			}
+			stack = append(stack, t.Name.Local)
+			partyStack = append(partyStack, current)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+			partyStack = partyStack[:len(partyStack)-1]
+		case xml.CharData:
+			if len(stack) == 0 || strings.TrimSpace(string(t)) == "" {
+				continue
+			}
+			node := textNode{
+				name:  stack[len(stack)-1],
+				start: start,
+				end:   decoder.InputOffset(),
+				value: string(t),
+				party: partyStack[len(partyStack)-1],
+			}
+			if node.party != nil {
+				switch node.name {
+				case "UF":
+					node.party.uf = node.value
+				case "CPF":
+					node.party.person = true
+				}
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, keys, nil
+}
+
+// replace returns the fake value of the text node, and false when it is kept. digest holds the new
+// DigestValue of the document, which the digVal of the protocol repeats. The nodes are replaced once
+// the whole document is scanned, so the party already knows its state when its IE comes before its
+// UF, as in the carrier (transporta).
+func (a *Anonymizer) replace(node textNode, newKey string, digest *string) (string, bool) {
+	p := node.party
+	if p == nil {
+		p = &party{}
+	}
+	switch node.name {
+	case "CNPJ":
+		return a.cnpj(node.value), true
+	case "CPF":
+		return a.fake("CPF", node.value, a.cpf), true
+	case "idEstrangeiro":
+		return a.fake("idEstrangeiro", node.value, func() string { return a.shaped(node.value) }), true
+	case "IE", "IEST":
+		if node.value == "ISENTO" {
+			return "", false
+		}
+		return a.fake("IE"+p.uf, node.value, func() string {
+			if ie := a.documents.IE(p.uf); ie != "" {
+				return ie
+			}
+			return a.digits(len(node.value))
+		}), true
+	case "xNome":
+		if node.value == homologationRecipientName || node.party == nil {
+			return "", false
+		}
+		p.name = a.name(node.value, p.person)
+		return p.name, true
+	case "xFant":
+		if p.name == "" {
+			return a.fake("xFant", node.value, a.locale.CompanyName), true
+		}
+		return br_locale.TradeName(p.name), true
+	case "xContato":
+		p.name = a.fake("person", node.value, a.locale.PersonName)
+		return p.name, true
+	case "xLgr":
+		return a.fake("xLgr", node.value, a.locale.Street), true
+	case "nro":
+		return a.fake("nro", node.value, func() string { return strconv.Itoa(a.rand.Intn(9999) + 1) }), true
+	case "xCpl":
+		return a.fake("xCpl", node.value, a.locale.Complement), true
+	case "xBairro":
+		return a.fake("xBairro", node.value, a.locale.Neighborhood), true
+	case "xEnder":
+		return a.fake("xEnder", node.value, func() string {
+			return fmt.Sprintf("%s, %d", a.locale.Street(), a.rand.Intn(9999)+1)
+		}), true
+	case "CEP":
+		return a.fake("CEP", node.value, func() string { return a.digits(8) }), true
+	case "fone":
+		return a.fake("fone", node.value, func() string { return a.locale.Phone(p.uf) }), true
+	case "email":
+		return a.fake("email", node.value, func() string {
+			if p.name != "" {
+				return a.locale.Email(p.name)
+			}
+			return a.locale.Email(a.locale.PersonName())
+		}), true
+	case "placa":
+		return a.fake("placa", node.value, a.documents.Plate), true
+	case "cDV":
+		if newKey == "" {
+			return "", false
+		}
+		return newKey[len(newKey)-1:], true
+	}
+
+	if freeTexts[node.name] {
+		return a.replaceFreeText(node.value), true
+	}
+	if !a.resign {
+		return "", false
+	}
+	switch node.name {
+	case "DigestValue":
+		*digest = a.base64(20)
+		return *digest, true
+	case "digVal":
+		if *digest == "" {
+			*digest = a.base64(20)
+		}
+		return *digest, true
+	case "SignatureValue", "signAC", "assinaturaQRCODE":
+		return a.base64(256), true
+	case "X509Certificate":
+		return "MIIH" + a.base64(96), true
+	}
+	return "", false
+}
+
+// fake returns the fake of the value of the kind, generating it the first time the value is found. A
+// value is never its own fake, which names drawn from small pools would otherwise be now and then.
+func (a *Anonymizer) fake(kind, value string, generate func() string) string {
+	key := kind + ":" + value
+	if fake, ok := a.fakes[key]; ok {
+		return fake
+	}
+	fake := generate()
+	for fake == value {
+		fake = generate()
+	}
+	a.fakes[key] = fake
+	return fake
+}
+
+// name returns the fake name of a person or company. Names of the same value get the same fake.
+func (a *Anonymizer) name(value string, person bool) string {
+	if person {
+		return a.fake("name", value, a.locale.PersonName)
+	}
+	return a.fake("name", value, a.locale.CompanyName)
+}
+
+// cpf returns a fake CPF not given before.
+func (a *Anonymizer) cpf() string {
+	for {
+		cpf := a.documents.CPF()
+		if !a.used[cpf] {
+			a.used[cpf] = true
+			return cpf
+		}
+	}
+}
+
+// cnpj returns the fake of the CNPJ, which keeps its branch number: the establishments of a company
+// get the same fake root.
+func (a *Anonymizer) cnpj(value string) string {
+	return a.fake("CNPJ", value, func() string {
+		if len(value) != 14 || !br_documents.ValidateCNPJ(value) {
+			return a.documents.CNPJ()
+		}
+		root := a.fake("CNPJroot", value[:8], func() string {
+			for {
+				cnpj := a.documents.CNPJ()
+				if !a.used[cnpj[:8]] {
+					a.used[cnpj[:8]] = true
+					return cnpj
+				}
+			}
+		})
+		branch, _ := strconv.Atoi(value[8:12])
+		if fake := br_documents.CNPJBranch(root, branch); fake != "" {
+			return fake
+		}
+		return root
+	})
+}
+
+// accessKey returns the access key with the fake CNPJ, or CPF, of its emitter and a new check digit.
+// A CPF takes the place of the CNPJ left-padded with zeros.
+func (a *Anonymizer) accessKey(key string) string {
+	emitter := key[6:20]
+	var fake string
+	if strings.HasPrefix(emitter, "000") && br_documents.ValidateCPF(emitter[3:]) && !br_documents.ValidateCNPJ(emitter) {
+		fake = "000" + a.fake("CPF", emitter[3:], a.cpf)
+	} else {
+		fake = a.cnpj(emitter)
+	}
+	partial := key[:6] + fake + key[20:43]
+	return partial + strconv.Itoa(br_documents.AccessKeyDV(partial))
+}
+
+// replaceFreeText replaces the identifiers, names, phones, emails and plates replaced so far wherever
+// they are in the text.
+func (a *Anonymizer) replaceFreeText(text string) string {
+	type original struct{ key, value string }
+	var originals []original
+	for key := range a.fakes {
+		kind, value, _ := strings.Cut(key, ":")
+		switch kind {
+		case "CPF", "CNPJ", "idEstrangeiro", "name", "person", "fone", "email", "placa":
+			if len(value) >= 5 {
+				originals = append(originals, original{key, value})
+			}
+		}
+	}
+	// Longer values first, so that a name is not replaced in part.
+	sort.Slice(originals, func(i, j int) bool {
+		if len(originals[i].value) != len(originals[j].value) {
+			return len(originals[i].value) > len(originals[j].value)
+		}
+		return originals[i].key < originals[j].key
+	})
+	for _, o := range originals {
+		text = strings.ReplaceAll(text, o.value, a.fakes[o.key])
+	}
+	return text
+}
+
+// digits returns a string of random digits of the length.
+func (a *Anonymizer) digits(length int) string {
+	digits := make([]byte, length)
+	for i := range digits {
+		digits[i] = byte('0' + a.rand.Intn(10))
+	}
+	return string(digits)
+}
+
+// shaped returns a random value of the shape of the value: a digit for each digit, an uppercase letter
+// for each letter, and the other characters as they are.
+func (a *Anonymizer) shaped(value string) string {
+	shaped := []rune(value)
+	for i, char := range shaped {
+		switch {
+		case char >= '0' && char <= '9':
+			shaped[i] = rune('0' + a.rand.Intn(10))
+		case char >= 'A' && char <= 'Z', char >= 'a' && char <= 'z':
+			shaped[i] = rune('A' + a.rand.Intn(26))
+		}
+	}
+	return string(shaped)
+}
+
+// base64 returns the base64 encoding of the number of random bytes.
+func (a *Anonymizer) base64(size int) string {
+	raw := make([]byte, size)
+	a.rand.Read(raw)
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// sortedKeys returns the original access keys in order, so that they are replaced in the same order.
+func sortedKeys(keys map[string]string) []string {
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
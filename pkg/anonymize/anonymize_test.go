@@ -0,0 +1,207 @@
+package anonymize
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+	"github.com/mayckol/brfiscalfaker/pkg/nfs"
+	"github.com/mayckol/brfiscalfaker/pkg/rules"
+	"github.com/mayckol/brfiscalfaker/pkg/universe"
+)
+
+var (
+	emitCNPJRe = regexp.MustCompile(`<emit>\s*<CNPJ>([0-9]+)</CNPJ>\s*<xNome>([^<]+)</xNome>`)
+	keyRe      = regexp.MustCompile(`Id="(?:NFe|CFe)([0-9]{44})"`)
+	detRe      = regexp.MustCompile(`(?s)<det .*</total>`)
+)
+
+func TestAnonymize(t *testing.T) {
+	anonymizer := New(WithSeed(1), WithResign())
+	for _, tt := range []nfs.TemplateType{nfs.NFe, nfs.NFCe, nfs.NFeDevolucao, nfs.CFe} {
+		t.Run(tt.String(), func(t *testing.T) {
+			generator, err := nfs.NewTemplateGenerator(tt)
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			for i := 0; i < 20; i++ {
+				original, err := generator.Generate()
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				anonymized, err := anonymizer.Anonymize(original)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+
+				before, after := emitCNPJRe.FindSubmatch(original), emitCNPJRe.FindSubmatch(anonymized)
+				if after == nil || bytes.Equal(before[1], after[1]) || bytes.Equal(before[2], after[2]) {
+					t.Fatalf("Expected another emitter, got %s\n%s", after, anonymized)
+				}
+				key := keyRe.FindSubmatch(anonymized)
+				if !br_documents.ValidateAccessKey(string(key[1])) || !bytes.Contains(key[1], after[1]) {
+					t.Errorf("Expected a valid access key with the CNPJ %s, got %s", after[1], key[1])
+				}
+				if bytes.Contains(anonymized, keyRe.FindSubmatch(original)[1]) {
+					t.Errorf("Expected the original access key to be replaced everywhere\n%s", anonymized)
+				}
+				if !bytes.Equal(detRe.Find(original), detRe.Find(anonymized)) {
+					t.Errorf("Expected the items and totals to be kept\n%s", anonymized)
+				}
+
+				if errs := nfs.Validate(tt, anonymized); len(errs) > 0 {
+					t.Fatalf("Expected no schema violations, got %v\n%s", errs, anonymized)
+				}
+				if tt == nfs.CFe {
+					continue
+				}
+				if violations := rules.Check(anonymized); len(violations) > 0 {
+					t.Fatalf("Expected no violations, got %d (first: %v)\n%s", len(violations), violations[0], anonymized)
+				}
+			}
+		})
+	}
+}
+
+func TestAnonymize_CarrierIE(t *testing.T) {
+	// The IE of the carrier comes before its state, which its check digits depend on.
+	carrierRe := regexp.MustCompile(`(?s)<transporta>.*?<IE>([0-9]+)</IE>.*?<UF>([A-Z]{2})</UF>`)
+	anonymizer := New(WithSeed(1))
+	checked := 0
+	for i := 0; i < 100 && checked < 20; i++ {
+		original, err := nfs.NewNFeGenerator().Generate()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		before := carrierRe.FindSubmatch(original)
+		if before == nil {
+			continue
+		}
+		anonymized, err := anonymizer.Anonymize(original)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		after := carrierRe.FindSubmatch(anonymized)
+		if after == nil || bytes.Equal(before[1], after[1]) {
+			t.Fatalf("Expected another carrier IE, got %s\n%s", after, anonymized)
+		}
+		if !br_documents.ValidateIE(string(after[2]), string(after[1])) {
+			t.Errorf("Expected a valid IE of %s, got %s", after[2], after[1])
+		}
+		checked++
+	}
+	if checked == 0 {
+		t.Fatalf("Expected carriers with an IE")
+	}
+}
+
+func TestAnonymize_ForeignRecipient(t *testing.T) {
+	idRe := regexp.MustCompile(`<idEstrangeiro>([^<]+)</idEstrangeiro>`)
+	for i := 0; i < 20; i++ {
+		original, err := nfs.NewNFeGenerator().Generate(nfs.WithRecipientKind(nfs.RecipientForeign))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		anonymized, err := New().Anonymize(original)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		id := idRe.FindSubmatch(original)[1]
+		if fake := idRe.FindSubmatch(anonymized); fake == nil || bytes.Equal(fake[1], id) || len(fake[1]) != len(id) {
+			t.Errorf("Expected another foreign ID of the shape of %s, got %s", id, fake)
+		}
+		// The name of the emitter is the brand of the volumes too.
+		if name := emitCNPJRe.FindSubmatch(original)[2]; bytes.Contains(anonymized, name) {
+			t.Errorf("Expected the name %s to be replaced everywhere\n%s", name, anonymized)
+		}
+		if errs := nfs.Validate(nfs.NFe, anonymized); len(errs) > 0 {
+			t.Fatalf("Expected no schema violations, got %v\n%s", errs, anonymized)
+		}
+	}
+}
+
+func TestAnonymize_ProducerKey(t *testing.T) {
+	original, err := nfs.NewNFeGenerator().Generate(nfs.WithEmitterProfile(nfs.EmitterProdutorRural))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	anonymizer := New()
+	anonymized, err := anonymizer.Anonymize(original)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The key holds the fake CPF of the producer, which has no fake CNPJ.
+	cpf := regexp.MustCompile(`<emit>\s*<CPF>([0-9]+)</CPF>`).FindSubmatch(anonymized)[1]
+	if key := keyRe.FindSubmatch(anonymized)[1]; string(key[6:20]) != "000"+string(cpf) || !br_documents.ValidateAccessKey(string(key)) {
+		t.Errorf("Expected a valid access key with the CPF %s, got %s", cpf, key)
+	}
+	for key := range anonymizer.fakes {
+		if strings.HasPrefix(key, "CNPJ:000") {
+			t.Errorf("Expected no fake CNPJ for the CPF of the key, got %s", key)
+		}
+	}
+}
+
+func TestAnonymize_Consistent(t *testing.T) {
+	u := universe.New(5, 3, 10)
+	anonymizer := New()
+	fakes := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		original, err := nfs.NewNFeGenerator().Generate(nfs.WithUniverse(u))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		anonymized, err := anonymizer.Anonymize(original)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		cnpj := string(emitCNPJRe.FindSubmatch(original)[1])
+		fake := string(emitCNPJRe.FindSubmatch(anonymized)[1])
+		if previous, ok := fakes[cnpj]; ok && previous != fake {
+			t.Errorf("Expected the same fake of %s, got %s and %s", cnpj, previous, fake)
+		}
+		fakes[cnpj] = fake
+	}
+
+	// A return references the anonymized key of the sale.
+	sale, err := nfs.NewNFeGenerator().Generate(nfs.WithOperation(nfs.OperationVendaInterna))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	refund, err := nfs.NewNFeDevolucaoGenerator().Generate(nfs.WithReferencedInvoice(sale))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	anonymizedSale, err := anonymizer.Anonymize(sale)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	anonymizedRefund, err := anonymizer.Anonymize(refund)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	key := keyRe.FindSubmatch(anonymizedSale)[1]
+	if !bytes.Contains(anonymizedRefund, []byte("<refNFe>"+string(key)+"</refNFe>")) {
+		t.Errorf("Expected the return to reference %s\n%s", key, anonymizedRefund)
+	}
+}
+
+func TestAnonymize_Seed(t *testing.T) {
+	original, err := nfs.NewNFeGenerator().Generate()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	first, _ := New(WithSeed(9)).Anonymize(original)
+	second, _ := New(WithSeed(9)).Anonymize(original)
+	if !bytes.Equal(first, second) {
+		t.Errorf("Expected the same fakes for the same seed")
+	}
+
+	if _, err := New().Anonymize([]byte("<html></html>")); err == nil || !strings.Contains(err.Error(), "html") {
+		t.Errorf("Expected an error for an unknown document, got %v", err)
+	}
+}
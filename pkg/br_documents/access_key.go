@@ -2,6 +2,7 @@ package br_documents
 
 import (
 	"fmt"
+	"github.com/mayckol/brfiscalfaker/utils"
 	"math/rand"
 	"strconv"
 	"strings"
@@ -23,9 +24,18 @@ func AllDigitsAreIdentical(digits []int) bool {
 }
 
 // AccessKeyConfig holds configuration options for generating an Access Key.
+// Empty fields are filled with random values.
 type AccessKeyConfig struct {
-	Masked bool
-	CNPJ   string
+	Masked       bool
+	CNPJ         string
+	CPF          string    // emitter CPF of individuals, left-padded to 14 digits in place of the CNPJ
+	UF           string    // IBGE code of the state (cUF), e.g. "35"
+	Date         time.Time // emission date, used for the AAMM field
+	Model        string    // "55" (NF-e), "65" (NFC-e)
+	Series       int       // random only when Number is empty too, since 0 is the série única
+	Number       int
+	EmissionType string // tpEmis
+	NumericCode  string // cNF, 8 digits
 }
 
 // AccessKey generates a valid random Chave de Acesso for NF-e.
@@ -41,42 +51,61 @@ func AccessKey(configs ...AccessKeyConfig) string {
 	// Override defaults with provided configurations
 	if len(configs) > 0 {
 		config = configs[0]
-		if config.CNPJ == "" {
+		if config.CPF != "" {
+			config.CNPJ = fmt.Sprintf("%014s", config.CPF)
+		} else if config.CNPJ == "" {
 			config.CNPJ = CNPJ()
 		}
 	}
 
-	// List of valid UF (Federative Unit) codes
-	ufCodes := []string{
-		"11", "12", "13", "14", "15", "16", "17",
-		"21", "22", "23", "24", "25", "26", "27",
-		"28", "29", "31", "32", "33", "35",
-		"41", "42", "43", "50", "51", "52", "53",
-	}
-
 	// 1. UF Code: 2 digits
-	uf := ufCodes[rand.Intn(len(ufCodes))]
+	uf := config.UF
+	if uf == "" {
+		codes := make([]string, 0, len(ufCodes))
+		for _, code := range ufCodes {
+			codes = append(codes, code)
+		}
+		uf = codes[rand.Intn(len(codes))]
+	}
 
 	// 2. Year and Month: 4 digits (AAMM)
-	currentTime := time.Now()
+	currentTime := config.Date
+	if currentTime.IsZero() {
+		currentTime = time.Now()
+	}
 	yearMonth := fmt.Sprintf("%02d%02d", currentTime.Year()%100, currentTime.Month())
 
 	// 3. CNPJ: 14 digits (provided via config)
 
-	// 4. Model: 2 digits (assuming "55" for NF-e)
-	model := "55"
+	// 4. Model: 2 digits ("55" for NF-e unless configured)
+	model := config.Model
+	if model == "" {
+		model = "55"
+	}
 
 	// 5. Series: 3 digits (000 to 999)
-	series := fmt.Sprintf("%03d", rand.Intn(1000))
+	series := fmt.Sprintf("%03d", config.Series)
+	if config.Series == 0 && config.Number == 0 {
+		series = fmt.Sprintf("%03d", rand.Intn(1000))
+	}
 
 	// 6. Invoice Number (nNF): 9 digits (000000001 to 999999999)
-	invoiceNumber := fmt.Sprintf("%09d", rand.Intn(1000000000))
+	invoiceNumber := fmt.Sprintf("%09d", config.Number)
+	if config.Number == 0 {
+		invoiceNumber = fmt.Sprintf("%09d", rand.Intn(999999999)+1)
+	}
 
 	// 7. Emission Type (tpEmis): 1 digit (1 to 7)
-	emissionType := fmt.Sprintf("%d", rand.Intn(7)+1)
+	emissionType := config.EmissionType
+	if emissionType == "" {
+		emissionType = fmt.Sprintf("%d", rand.Intn(7)+1)
+	}
 
 	// 8. Numeric Code (cNF): 8 digits (00000000 to 99999999)
-	numericCode := fmt.Sprintf("%08d", rand.Intn(100000000))
+	numericCode := config.NumericCode
+	if numericCode == "" {
+		numericCode = fmt.Sprintf("%08d", rand.Intn(100000000))
+	}
 
 	// Assemble the first 43 digits of the Access Key
 	partialKey := uf + yearMonth + config.CNPJ + model + series + invoiceNumber + emissionType + numericCode
@@ -94,6 +123,20 @@ func AccessKey(configs ...AccessKeyConfig) string {
 	return fullKey
 }
 
+// ValidateAccessKey reports whether the key, raw or masked, has 44 digits and a valid check digit.
+func ValidateAccessKey(key string) bool {
+	digits, ok := parseDigits(key, 44)
+	if !ok {
+		return false
+	}
+	return calculateAccessKeyDV(utils.DigitsToString(digits[:43])) == digits[43]
+}
+
+// AccessKeyDV returns the check digit (cDV) of the first 43 digits of an NF-e, NFC-e or CF-e access key.
+func AccessKeyDV(partial string) int {
+	return calculateAccessKeyDV(partial)
+}
+
 // calculateAccessKeyDV calculates the Verification Digit (DV) for the Access Key.
 // It uses the modulo 11 algorithm as specified.
 func calculateAccessKeyDV(key string) int {
@@ -0,0 +1,59 @@
+package br_documents
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateAccessKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		valid bool
+	}{
+		{"Raw", "35230911222333000181550010000000011123456785", true},
+		{"Masked", "3523 0911 2223 3300 0181 5500 1000 0000 0111 2345 6785", true},
+		{"CheckDigit", "35230911222333000181550010000000011123456786", false},
+		{"Length", "3523091122233300018155001000000001112345678", false},
+		{"NonDigit", "3523091122233300018155001000000001112345678A", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if ValidateAccessKey(tc.key) != tc.valid {
+				t.Errorf("Expected ValidateAccessKey(%s) to be %v", tc.key, tc.valid)
+			}
+		})
+	}
+}
+
+func TestAccessKeyDV(t *testing.T) {
+	if dv := AccessKeyDV("3523091122233300018155001000000001112345678"); dv != 5 {
+		t.Errorf("Expected the check digit 5, got %d", dv)
+	}
+	// A remainder of 0 or 1 gives the check digit 0.
+	if dv := AccessKeyDV("0000000000000000000000000000000000000000000"); dv != 0 {
+		t.Errorf("Expected the check digit 0, got %d", dv)
+	}
+}
+
+func TestAccessKey_Config(t *testing.T) {
+	key := AccessKey(AccessKeyConfig{
+		CNPJ:         "11222333000181",
+		UF:           "35",
+		Date:         time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC),
+		Model:        "65",
+		Series:       1,
+		Number:       1,
+		EmissionType: "1",
+		NumericCode:  "12345678",
+	})
+	if expected := "35" + "2309" + "11222333000181" + "65" + "001" + "000000001" + "1" + "12345678"; key[:43] != expected {
+		t.Errorf("Expected the key to start with %s, got %s", expected, key)
+	}
+	if !ValidateAccessKey(key) {
+		t.Errorf("Expected a valid access key, got %s", key)
+	}
+	if key := AccessKey(AccessKeyConfig{CPF: "52998224725", UF: "35"}); key[6:20] != "00052998224725" {
+		t.Errorf("Expected the CPF padded to 14 digits, got %s", key[6:20])
+	}
+}
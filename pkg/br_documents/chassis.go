@@ -0,0 +1,71 @@
+package br_documents
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// vinCharacters are the characters allowed in a VIN, which leaves out I, O and Q.
+const vinCharacters = "0123456789ABCDEFGHJKLMNPRSTUVWXYZ"
+
+// vinYears are the model year codes of the VIN from 2010 on, repeated every 30 years.
+const vinYears = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// vinWeights are the weights of the positions of the VIN in its check digit (ISO 3779).
+var vinWeights = []int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// Chassis generates a valid random chassis number (VIN, ISO 3779) of a vehicle made by the
+// manufacturer (WMI, e.g. "9BW") in the model year, with its check digit in the ninth position.
+func Chassis(wmi string, modelYear int) string {
+	vin := []byte(wmi)
+	for len(vin) < 8 {
+		vin = append(vin, vinCharacters[10+rand.Intn(len(vinCharacters)-10)])
+	}
+	vin = append(vin, '0', vinYears[((modelYear-2010)%30+30)%30], vinCharacters[rand.Intn(len(vinCharacters))])
+	for len(vin) < 17 {
+		vin = append(vin, vinCharacters[rand.Intn(10)])
+	}
+	vin[8] = calculateVINCheckDigit(string(vin))
+	return string(vin)
+}
+
+// calculateVINCheckDigit calculates the check digit of the VIN: each character is transliterated to
+// a number (A=1 to I=9, J=1 to R=9, S=2 to Z=9), weighted by its position and summed modulo 11,
+// with 10 written as X.
+func calculateVINCheckDigit(vin string) byte {
+	sum := 0
+	for i := 0; i < len(vin); i++ {
+		sum += vinValue(vin[i]) * vinWeights[i]
+	}
+	if sum%11 == 10 {
+		return 'X'
+	}
+	return byte('0' + sum%11)
+}
+
+// vinValue transliterates a character of the VIN to its number.
+func vinValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'A' && c <= 'I':
+		return int(c-'A') + 1
+	case c >= 'J' && c <= 'R':
+		return int(c-'J') + 1
+	default:
+		return int(c-'S') + 2
+	}
+}
+
+// ValidateChassis reports whether the chassis number is a VIN of 17 valid characters with a valid check digit.
+func ValidateChassis(vin string) bool {
+	if len(vin) != 17 {
+		return false
+	}
+	for i := 0; i < len(vin); i++ {
+		if !strings.ContainsRune(vinCharacters, rune(vin[i])) {
+			return false
+		}
+	}
+	return calculateVINCheckDigit(vin) == vin[8]
+}
@@ -0,0 +1,38 @@
+package br_documents
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChassis(t *testing.T) {
+	for year := 2010; year < 2045; year++ {
+		chassis := Chassis("9BW", year)
+		if !ValidateChassis(chassis) {
+			t.Errorf("Expected a valid chassis, got %s", chassis)
+		}
+		if strings.ContainsAny(chassis, "IOQ") {
+			t.Errorf("Expected no I, O or Q in %s", chassis)
+		}
+	}
+}
+
+func TestValidateChassis(t *testing.T) {
+	tests := []struct {
+		name  string
+		vin   string
+		valid bool
+	}{
+		{"ISO3779Example", "1M8GDM9AXKP042788", true},
+		{"CheckDigit", "1M8GDM9A1KP042788", false},
+		{"Length", "1M8GDM9AXKP04278", false},
+		{"ForbiddenLetter", "1M8GDM9AXKO042788", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if ValidateChassis(tc.vin) != tc.valid {
+				t.Errorf("Expected ValidateChassis(%s) to be %v", tc.vin, tc.valid)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package br_documents
 
 import (
+	"fmt"
 	"github.com/mayckol/brfiscalfaker/utils"
 	"math/rand"
 	"strconv"
@@ -22,6 +23,11 @@ type CNPJConfig struct {
 // If Masked is true, it returns the formatted CNPJ (e.g., XX.XXX.XXX/XXXX-XX).
 // If Masked is false, it returns the raw CNPJ digits (e.g., XXXXXXXXXXXXXXX).
 func CNPJ(configs ...CNPJConfig) string {
+	return global.CNPJ(configs...)
+}
+
+// CNPJ generates a valid random CNPJ number, as the package function CNPJ.
+func (g *Generator) CNPJ(configs ...CNPJConfig) string {
 	// Initialize default configuration
 	config := CNPJConfig{
 		Masked: false,
@@ -33,7 +39,7 @@ func CNPJ(configs ...CNPJConfig) string {
 	}
 
 	// Generate the first 12 digits of the CNPJ
-	cnpjDigits := utils.GenerateRandomDigits(12)
+	cnpjDigits := g.digits(12)
 
 	// Calculate the first check digit
 	firstCheckDigit := calculateCNPJCheckDigit(cnpjDigits)
@@ -75,6 +81,50 @@ func calculateCNPJCheckDigit(cnpj []int) int {
 	return 11 - remainder
 }
 
+// ValidateCNPJ reports whether the CNPJ, raw or masked, has valid check digits.
+// Alphanumeric CNPJs are accepted, each character counting as its ASCII code minus 48.
+func ValidateCNPJ(cnpj string) bool {
+	cnpj = strings.NewReplacer(".", "", "/", "", "-", "").Replace(strings.ToUpper(strings.TrimSpace(cnpj)))
+	if len(cnpj) != 14 {
+		return false
+	}
+
+	values := make([]int, 14)
+	for i, char := range cnpj {
+		switch {
+		case char >= '0' && char <= '9':
+			values[i] = int(char - '0')
+		case char >= 'A' && char <= 'Z' && i < 12:
+			values[i] = int(char - '0')
+		default:
+			return false
+		}
+	}
+	if AllDigitsAreIdentical(values) {
+		return false
+	}
+
+	return calculateCNPJCheckDigit(values[:12]) == values[12] &&
+		calculateCNPJCheckDigit(values[:13]) == values[13]
+}
+
+// CNPJBranch returns the CNPJ of another establishment of the company of the numeric CNPJ: the same
+// 8-digit root with the branch number (1 is the head office) and new check digits. It returns ""
+// when the CNPJ is not a raw or masked numeric CNPJ or the branch is out of the 1 to 9999 range.
+func CNPJBranch(cnpj string, branch int) string {
+	digits, ok := parseDigits(cnpj, 14)
+	if !ok || branch < 1 || branch > 9999 {
+		return ""
+	}
+	digits = digits[:8]
+	for _, char := range fmt.Sprintf("%04d", branch) {
+		digits = append(digits, int(char-'0'))
+	}
+	digits = append(digits, calculateCNPJCheckDigit(digits))
+	digits = append(digits, calculateCNPJCheckDigit(digits))
+	return utils.DigitsToString(digits)
+}
+
 // formatCNPJ formats a slice of CNPJ digits into the standard format XX.XXX.XXX/XXXX-XX.
 func formatCNPJ(cnpj []int) string {
 	if len(cnpj) != 14 {
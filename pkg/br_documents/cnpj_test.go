@@ -0,0 +1,59 @@
+package br_documents
+
+import "testing"
+
+func TestValidateCNPJ(t *testing.T) {
+	tests := []struct {
+		name  string
+		cnpj  string
+		valid bool
+	}{
+		{"Raw", "11222333000181", true},
+		{"Masked", "11.222.333/0001-81", true},
+		{"Alphanumeric", "12.ABC.345/01DE-35", true},
+		{"AlphanumericLowercase", "12abc34501de35", true},
+		{"FirstCheckDigit", "11222333000191", false},
+		{"SecondCheckDigit", "11222333000182", false},
+		{"LetterInCheckDigits", "12ABC34501DE3A", false},
+		{"IdenticalDigits", "00000000000000", false},
+		{"Length", "1122233300018", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if ValidateCNPJ(tc.cnpj) != tc.valid {
+				t.Errorf("Expected ValidateCNPJ(%s) to be %v", tc.cnpj, tc.valid)
+			}
+		})
+	}
+}
+
+func TestCNPJBranch(t *testing.T) {
+	tests := []struct {
+		name     string
+		cnpj     string
+		branch   int
+		expected string
+	}{
+		{"HeadOffice", "11222333000262", 1, "11222333000181"},
+		{"Branch", "11.222.333/0001-81", 2, "11222333000262"},
+		{"BranchZero", "11222333000181", 0, ""},
+		{"BranchOutOfRange", "11222333000181", 10000, ""},
+		{"Alphanumeric", "12ABC34501DE35", 1, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := CNPJBranch(tc.cnpj, tc.branch); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCNPJ_Generated(t *testing.T) {
+	g := New(1)
+	for i := 0; i < 100; i++ {
+		if cnpj := g.CNPJ(CNPJConfig{Masked: i%2 == 0}); !ValidateCNPJ(cnpj) {
+			t.Fatalf("Expected a valid CNPJ, got %s", cnpj)
+		}
+	}
+}
@@ -22,6 +22,11 @@ type CPFConfig struct {
 // If Masked is true, it returns the formatted CPF (e.g., XXX.XXX.XXX-XX).
 // If Masked is false, it returns the raw CPF digits (e.g., XXXXXXXXXXX).
 func CPF(configs ...CPFConfig) string {
+	return global.CPF(configs...)
+}
+
+// CPF generates a valid random CPF number, as the package function CPF.
+func (g *Generator) CPF(configs ...CPFConfig) string {
 	config := CPFConfig{
 		Masked: false,
 	}
@@ -31,7 +36,7 @@ func CPF(configs ...CPFConfig) string {
 	}
 
 	// Generate the first 9 random digits of the CPF
-	cpfDigits := utils.GenerateRandomDigits(9)
+	cpfDigits := g.digits(9)
 
 	// Calculate the first check digit with a starting weight of 10
 	firstCheckDigit := calculateCPFCheckDigit(cpfDigits, 10)
@@ -63,6 +68,16 @@ func calculateCPFCheckDigit(cpf []int, weightStart int) int {
 	return 11 - remainder
 }
 
+// ValidateCPF reports whether the CPF, raw or masked, has valid check digits.
+func ValidateCPF(cpf string) bool {
+	digits, ok := parseDigits(cpf, 11)
+	if !ok || AllDigitsAreIdentical(digits) {
+		return false
+	}
+	return calculateCPFCheckDigit(digits[:9], 10) == digits[9] &&
+		calculateCPFCheckDigit(digits[:10], 11) == digits[10]
+}
+
 // formatCPF formats a slice of CPF digits into the standard format XXX.XXX.XXX-XX.
 func formatCPF(cpf []int) string {
 	if len(cpf) != 11 {
@@ -81,3 +96,20 @@ func formatCPF(cpf []int) string {
 	}
 	return formatted.String()
 }
+
+// parseDigits strips the usual mask characters and converts the value to digits.
+// It fails when the value has non-digit characters or a length other than size.
+func parseDigits(value string, size int) ([]int, bool) {
+	value = strings.NewReplacer(".", "", "/", "", "-", "", " ", "").Replace(value)
+	if len(value) != size {
+		return nil, false
+	}
+	digits := make([]int, size)
+	for i, char := range value {
+		if char < '0' || char > '9' {
+			return nil, false
+		}
+		digits[i] = int(char - '0')
+	}
+	return digits, true
+}
@@ -0,0 +1,35 @@
+package br_documents
+
+import "testing"
+
+func TestValidateCPF(t *testing.T) {
+	tests := []struct {
+		name  string
+		cpf   string
+		valid bool
+	}{
+		{"Raw", "52998224725", true},
+		{"Masked", "529.982.247-25", true},
+		{"FirstCheckDigit", "52998224735", false},
+		{"SecondCheckDigit", "52998224726", false},
+		{"IdenticalDigits", "11111111111", false},
+		{"Length", "5299822472", false},
+		{"NonDigit", "5299822472A", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if ValidateCPF(tc.cpf) != tc.valid {
+				t.Errorf("Expected ValidateCPF(%s) to be %v", tc.cpf, tc.valid)
+			}
+		})
+	}
+}
+
+func TestCPF_Generated(t *testing.T) {
+	g := New(1)
+	for i := 0; i < 100; i++ {
+		if cpf := g.CPF(CPFConfig{Masked: i%2 == 0}); !ValidateCPF(cpf) {
+			t.Fatalf("Expected a valid CPF, got %s", cpf)
+		}
+	}
+}
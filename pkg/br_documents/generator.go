@@ -0,0 +1,35 @@
+package br_documents
+
+import "math/rand"
+
+// Generator draws documents from its own source of randomness, so that the same seed gives the same
+// CNPJs, CPFs, IEs and GTINs. A Generator is not safe for concurrent use; the package functions,
+// which draw from the global source, are.
+type Generator struct {
+	rand *rand.Rand // nil for the package functions
+}
+
+// New returns a Generator seeded with the seed.
+func New(seed int64) *Generator {
+	return &Generator{rand: rand.New(rand.NewSource(seed))}
+}
+
+// global is the Generator of the package functions.
+var global = &Generator{}
+
+// intn returns a random number from 0 to n-1.
+func (g *Generator) intn(n int) int {
+	if g.rand == nil {
+		return rand.Intn(n)
+	}
+	return g.rand.Intn(n)
+}
+
+// digits returns a slice of random digits of the length.
+func (g *Generator) digits(length int) []int {
+	digits := make([]int, length)
+	for i := range digits {
+		digits[i] = g.intn(10)
+	}
+	return digits
+}
@@ -0,0 +1,46 @@
+package br_documents
+
+import "github.com/mayckol/brfiscalfaker/utils"
+
+// GTIN generates a valid random GTIN-13 (EAN-13) with a GS1 Brasil prefix (789 or 790).
+func GTIN() string {
+	return global.GTIN()
+}
+
+// GTIN generates a valid random GTIN-13 (EAN-13) with a GS1 Brasil prefix, as the package function GTIN.
+func (g *Generator) GTIN() string {
+	prefix := []int{7, 8, 9}
+	if g.intn(10) == 0 {
+		prefix = []int{7, 9, 0}
+	}
+	digits := append(prefix, g.digits(9)...)
+	digits = append(digits, calculateGTINCheckDigit(digits))
+	return utils.DigitsToString(digits)
+}
+
+// calculateGTINCheckDigit calculates the GS1 check digit: the digits are weighted 3 and 1
+// alternately from the rightmost one, and the check digit completes the sum to a multiple of 10.
+func calculateGTINCheckDigit(digits []int) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		weight := 3
+		if (len(digits)-1-i)%2 == 1 {
+			weight = 1
+		}
+		sum += digits[i] * weight
+	}
+	return (10 - sum%10) % 10
+}
+
+// ValidateGTIN reports whether the GTIN-8, GTIN-12, GTIN-13 or GTIN-14 has a valid check digit.
+func ValidateGTIN(gtin string) bool {
+	size := len(gtin)
+	if size != 8 && size != 12 && size != 13 && size != 14 {
+		return false
+	}
+	digits, ok := parseDigits(gtin, size)
+	if !ok {
+		return false
+	}
+	return calculateGTINCheckDigit(digits[:size-1]) == digits[size-1]
+}
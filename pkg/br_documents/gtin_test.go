@@ -0,0 +1,43 @@
+package br_documents
+
+import "testing"
+
+func TestValidateGTIN(t *testing.T) {
+	tests := []struct {
+		name  string
+		gtin  string
+		valid bool
+	}{
+		{"GTIN8", "96385074", true},
+		{"GTIN12", "036000291452", true},
+		{"GTIN13", "4006381333931", true},
+		{"GTIN13Brazil", "7891000100103", true},
+		{"GTIN14", "10012345678902", true},
+		{"CheckDigit8", "96385075", false},
+		{"CheckDigit13", "4006381333932", false},
+		{"CheckDigit14", "10012345678901", false},
+		{"Length", "40063813339", false},
+		{"NonDigit", "400638133393A", false},
+		{"Empty", "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if ValidateGTIN(tc.gtin) != tc.valid {
+				t.Errorf("Expected ValidateGTIN(%s) to be %v", tc.gtin, tc.valid)
+			}
+		})
+	}
+}
+
+func TestGTIN_Generated(t *testing.T) {
+	g := New(1)
+	for i := 0; i < 100; i++ {
+		gtin := g.GTIN()
+		if len(gtin) != 13 || !ValidateGTIN(gtin) {
+			t.Fatalf("Expected a valid GTIN-13, got %s", gtin)
+		}
+		if gtin[:3] != "789" && gtin[:3] != "790" {
+			t.Fatalf("Expected a GS1 Brasil prefix, got %s", gtin)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package br_documents
+
+import (
+	"strings"
+
+	"github.com/mayckol/brfiscalfaker/utils"
+)
+
+// ieSpec describes the Inscrição Estadual format of a state, as published by SINTEGRA.
+type ieSpec struct {
+	length   int
+	prefixes []string
+	// complete returns a copy of the digits with the check digits filled in,
+	// or false when no valid check digit exists for them.
+	complete func(digits []int) ([]int, bool)
+}
+
+// ieSpecs holds the Inscrição Estadual rules of each state.
+var ieSpecs = map[string]ieSpec{
+	"AC": {13, []string{"01"}, dvAt(at(11, mod11(4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2)), at(12, mod11(5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2)))},
+	"AL": {9, []string{"240", "243", "245", "247", "248"}, dvAt(at(8, times10Mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+	"AM": {9, nil, completeAM},
+	"AP": {9, []string{"03"}, completeAP},
+	"BA": {9, nil, completeBA},
+	"CE": {9, []string{"06"}, dvAt(at(8, mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+	"DF": {13, []string{"07"}, dvAt(at(11, mod11(4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2)), at(12, mod11(5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2)))},
+	"ES": {9, []string{"08"}, dvAt(at(8, mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+	"GO": {9, []string{"10", "11", "15"}, completeGO},
+	"MA": {9, []string{"12"}, dvAt(at(8, mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+	"MG": {13, nil, completeMG},
+	"MS": {9, []string{"28"}, dvAt(at(8, mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+	"MT": {11, []string{"00", "13"}, dvAt(at(10, mod11(3, 2, 9, 8, 7, 6, 5, 4, 3, 2)))},
+	"PA": {9, []string{"15"}, dvAt(at(8, mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+	"PB": {9, []string{"16"}, dvAt(at(8, mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+	"PE": {9, []string{"0", "1"}, dvAt(at(7, mod11(8, 7, 6, 5, 4, 3, 2)), at(8, mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+	"PI": {9, []string{"19"}, dvAt(at(8, mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+	"PR": {10, nil, dvAt(at(8, mod11(3, 2, 7, 6, 5, 4, 3, 2)), at(9, mod11(4, 3, 2, 7, 6, 5, 4, 3, 2)))},
+	"RJ": {8, []string{"7", "8", "9"}, dvAt(at(7, mod11(2, 7, 6, 5, 4, 3, 2)))},
+	"RN": {9, []string{"20"}, completeRN},
+	"RO": {14, []string{"00000000"}, dvAt(at(13, completeRO))},
+	"RR": {9, []string{"24"}, dvAt(at(8, mod9))},
+	"RS": {10, nil, dvAt(at(9, mod11(2, 9, 8, 7, 6, 5, 4, 3, 2)))},
+	"SC": {9, []string{"25"}, dvAt(at(8, mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+	"SE": {9, []string{"27"}, dvAt(at(8, mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+	"SP": {12, nil, dvAt(at(8, spDigit(1, 3, 4, 5, 6, 7, 8, 10)), at(11, spDigit(3, 2, 10, 9, 8, 7, 6, 5, 4, 3, 2)))},
+	"TO": {9, []string{"29"}, dvAt(at(8, mod11(9, 8, 7, 6, 5, 4, 3, 2)))},
+}
+
+// IE generates a valid random Inscrição Estadual for the state abbreviation (e.g., "SP").
+// It returns an empty string for an unknown state.
+func IE(uf string) string {
+	return global.IE(uf)
+}
+
+// IE generates a valid random Inscrição Estadual for the state abbreviation, as the package function IE.
+func (g *Generator) IE(uf string) string {
+	spec, ok := ieSpecs[strings.ToUpper(uf)]
+	if !ok {
+		return ""
+	}
+
+	for {
+		digits := g.digits(spec.length)
+		if len(spec.prefixes) > 0 {
+			prefix := spec.prefixes[g.intn(len(spec.prefixes))]
+			for i, char := range prefix {
+				digits[i] = int(char - '0')
+			}
+		}
+		if completed, ok := spec.complete(digits); ok && !AllDigitsAreIdentical(completed) {
+			return utils.DigitsToString(completed)
+		}
+	}
+}
+
+// ValidateIE reports whether the Inscrição Estadual, raw or masked, is valid for the state abbreviation.
+// "ISENTO" is not accepted.
+func ValidateIE(uf string, ie string) bool {
+	uf = strings.ToUpper(uf)
+	spec, ok := ieSpecs[uf]
+	if !ok {
+		return false
+	}
+	digits, ok := parseDigits(ie, spec.length)
+	if !ok && uf == "BA" {
+		digits, ok = parseDigits(ie, 8)
+	}
+	if !ok && uf == "RN" {
+		digits, ok = parseDigits(ie, 10)
+	}
+	if !ok || AllDigitsAreIdentical(digits) {
+		return false
+	}
+
+	completed, ok := spec.complete(digits)
+	if !ok {
+		return false
+	}
+	return utils.DigitsToString(completed) == utils.DigitsToString(digits)
+}
+
+// checkDigit calculates a check digit from the digits that precede it.
+type checkDigit func(digits []int) (int, bool)
+
+// checkDigitAt places a checkDigit at a position of the number.
+type checkDigitAt struct {
+	position int
+	digit    checkDigit
+}
+
+// at returns a checkDigitAt for the position.
+func at(position int, digit checkDigit) checkDigitAt {
+	return checkDigitAt{position: position, digit: digit}
+}
+
+// dvAt returns a completion that fills each check digit, in order, from the digits before it.
+func dvAt(digits ...checkDigitAt) func([]int) ([]int, bool) {
+	return func(number []int) ([]int, bool) {
+		completed := append([]int(nil), number...)
+		for _, d := range digits {
+			if d.position >= len(completed) {
+				return nil, false
+			}
+			dv, ok := d.digit(completed[:d.position])
+			if !ok {
+				return nil, false
+			}
+			completed[d.position] = dv
+		}
+		return completed, true
+	}
+}
+
+// weightedSum multiplies the last len(weights) digits by the weights and adds them up.
+func weightedSum(digits []int, weights []int) int {
+	offset := len(digits) - len(weights)
+	sum := 0
+	for i, weight := range weights {
+		if offset+i >= 0 {
+			sum += digits[offset+i] * weight
+		}
+	}
+	return sum
+}
+
+// mod11 is the common rule: 11 minus the remainder, 0 when the remainder is 0 or 1.
+func mod11(weights ...int) checkDigit {
+	return func(digits []int) (int, bool) {
+		remainder := weightedSum(digits, weights) % 11
+		if remainder < 2 {
+			return 0, true
+		}
+		return 11 - remainder, true
+	}
+}
+
+// times10Mod11 is the AL/RN rule: the sum times 10 modulo 11, 0 when it is 10.
+func times10Mod11(weights ...int) checkDigit {
+	return func(digits []int) (int, bool) {
+		dv := weightedSum(digits, weights) * 10 % 11
+		if dv == 10 {
+			return 0, true
+		}
+		return dv, true
+	}
+}
+
+// spDigit is the SP rule: the rightmost digit of the remainder of the sum by 11.
+func spDigit(weights ...int) checkDigit {
+	return func(digits []int) (int, bool) {
+		return weightedSum(digits, weights) % 11 % 10, true
+	}
+}
+
+// mod9 is the RR rule: weights 1 to 8 and the remainder by 9.
+func mod9(digits []int) (int, bool) {
+	return weightedSum(digits, []int{1, 2, 3, 4, 5, 6, 7, 8}) % 9, true
+}
+
+// completeRO is the RO rule: 11 minus the remainder, minus 10 when it is 10 or 11.
+func completeRO(digits []int) (int, bool) {
+	dv := 11 - weightedSum(digits, []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2})%11
+	if dv >= 10 {
+		dv -= 10
+	}
+	return dv, true
+}
+
+// completeAM applies the AM rule, where sums lower than 11 are subtracted from 11 directly.
+func completeAM(digits []int) ([]int, bool) {
+	return dvAt(at(8, func(base []int) (int, bool) {
+		sum := weightedSum(base, []int{9, 8, 7, 6, 5, 4, 3, 2})
+		if sum < 11 {
+			return 11 - sum, 11-sum <= 9
+		}
+		if remainder := sum % 11; remainder > 1 {
+			return 11 - remainder, true
+		}
+		return 0, true
+	}))(digits)
+}
+
+// completeAP applies the AP rule, whose constants depend on the range of the number.
+func completeAP(digits []int) ([]int, bool) {
+	return dvAt(at(8, func(base []int) (int, bool) {
+		number := 0
+		for _, digit := range base {
+			number = number*10 + digit
+		}
+		p, d := 0, 0
+		switch {
+		case number >= 3000001 && number <= 3017000:
+			p, d = 5, 0
+		case number >= 3017001 && number <= 3019022:
+			p, d = 9, 1
+		}
+		dv := 11 - (p+weightedSum(base, []int{9, 8, 7, 6, 5, 4, 3, 2}))%11
+		switch dv {
+		case 10:
+			return 0, true
+		case 11:
+			return d, true
+		}
+		return dv, true
+	}))(digits)
+}
+
+// completeRN applies the RN rule to the 9-digit numbers and to the 10-digit ones, whose check digit
+// is weighted from 10.
+func completeRN(digits []int) ([]int, bool) {
+	if len(digits) == 10 {
+		return dvAt(at(9, times10Mod11(10, 9, 8, 7, 6, 5, 4, 3, 2)))(digits)
+	}
+	return dvAt(at(8, times10Mod11(9, 8, 7, 6, 5, 4, 3, 2)))(digits)
+}
+
+// completeGO applies the GO rule, where a remainder of 1 yields 1 for a reserved range.
+func completeGO(digits []int) ([]int, bool) {
+	return dvAt(at(8, func(base []int) (int, bool) {
+		remainder := weightedSum(base, []int{9, 8, 7, 6, 5, 4, 3, 2}) % 11
+		switch remainder {
+		case 0:
+			return 0, true
+		case 1:
+			number := 0
+			for _, digit := range base {
+				number = number*10 + digit
+			}
+			if number >= 10103105 && number <= 10119997 {
+				return 1, true
+			}
+			return 0, true
+		}
+		return 11 - remainder, true
+	}))(digits)
+}
+
+// completeBA applies the BA rule: the last check digit is calculated first, modulo 10 or 11
+// depending on the first digit (second digit for 9-digit numbers).
+func completeBA(digits []int) ([]int, bool) {
+	size := len(digits)
+	if size != 8 && size != 9 {
+		return nil, false
+	}
+	base := append([]int(nil), digits[:size-2]...)
+
+	modulo := 10
+	switch digits[size-8] {
+	case 6, 7, 9:
+		modulo = 11
+	}
+	dv := func(values []int, firstWeight int) int {
+		sum := 0
+		for i, value := range values {
+			sum += value * (firstWeight - i)
+		}
+		remainder := sum % modulo
+		if modulo == 10 {
+			if remainder == 0 {
+				return 0
+			}
+			return 10 - remainder
+		}
+		if remainder < 2 {
+			return 0
+		}
+		return 11 - remainder
+	}
+
+	second := dv(base, size-1)
+	first := dv(append(append([]int(nil), base...), second), size)
+	return append(base, first, second), true
+}
+
+// completeMG applies the MG rule: the first check digit uses alternating 1/2 weights over the
+// number with a 0 inserted after the municipality code, the second a modulo 11.
+func completeMG(digits []int) ([]int, bool) {
+	if len(digits) != 13 {
+		return nil, false
+	}
+	completed := append([]int(nil), digits...)
+
+	expanded := append(append(append([]int(nil), digits[:3]...), 0), digits[3:11]...)
+	sum := 0
+	for i, digit := range expanded {
+		product := digit * (1 + i%2)
+		sum += product/10 + product%10
+	}
+	completed[11] = (10 - sum%10) % 10
+
+	second, _ := mod11(3, 2, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2)(completed[:12])
+	completed[12] = second
+	return completed, true
+}
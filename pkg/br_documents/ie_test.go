@@ -0,0 +1,82 @@
+package br_documents
+
+import "testing"
+
+func TestValidateIE_Published(t *testing.T) {
+	// The examples published by SINTEGRA and the state tax offices.
+	tests := []struct {
+		uf string
+		ie string
+	}{
+		{"AC", "01.004.823/001-12"},
+		{"AL", "240000048"},
+		{"AM", "99.999.999-0"},
+		{"AP", "030123459"},
+		{"BA", "123456-63"},
+		{"BA", "612345-57"},
+		{"BA", "1000003-06"},
+		{"CE", "06000001-5"},
+		{"DF", "07.300001.001-09"},
+		{"ES", "999999990"},
+		{"GO", "10.987.654-7"},
+		{"MA", "12000038-5"},
+		{"MG", "062.307.904/0081"},
+		{"MS", "283115947"},
+		{"MT", "0013000001-9"},
+		{"PA", "15-999999-5"},
+		{"PB", "06000001-5"},
+		{"PE", "0321418-40"},
+		{"PI", "01234567-9"},
+		{"PR", "123.45678-50"},
+		{"RJ", "99.999.99-3"},
+		{"RN", "20.040.040-1"},
+		{"RN", "20.0.040.040-0"},
+		{"RO", "0000000062521-3"},
+		{"RR", "24006628-1"},
+		{"RS", "224/3658792"},
+		{"SC", "251.040.852"},
+		{"SE", "27123456-3"},
+		{"SP", "110.042.490.114"},
+		{"TO", "290227836"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.uf+" "+tc.ie, func(t *testing.T) {
+			if !ValidateIE(tc.uf, tc.ie) {
+				t.Errorf("Expected %s to be a valid IE of %s", tc.ie, tc.uf)
+			}
+		})
+	}
+}
+
+func TestValidateIE_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		uf   string
+		ie   string
+	}{
+		{"CheckDigit", "SP", "110.042.490.115"},
+		{"CheckDigit10Digits", "RN", "20.0.040.040-1"},
+		{"Length", "SC", "25104085"},
+		{"IdenticalDigits", "ES", "000000000"},
+		{"Isento", "SP", "ISENTO"},
+		{"UnknownState", "XX", "110042490114"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if ValidateIE(tc.uf, tc.ie) {
+				t.Errorf("Expected %s to be an invalid IE of %s", tc.ie, tc.uf)
+			}
+		})
+	}
+}
+
+func TestIE_Generated(t *testing.T) {
+	g := New(1)
+	for uf := range ieSpecs {
+		for i := 0; i < 100; i++ {
+			if ie := g.IE(uf); !ValidateIE(uf, ie) {
+				t.Fatalf("Expected a valid IE of %s, got %s", uf, ie)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package br_documents
+
+import "regexp"
+
+// plateRe matches the legacy plates (ABC1234) and the Mercosul ones (ABC1D23).
+var plateRe = regexp.MustCompile(`^[A-Z]{3}[0-9][A-Z0-9][0-9]{2}$`)
+
+// Plate generates a random vehicle plate: a Mercosul plate (three letters, a digit, a letter and two
+// digits, e.g. ABC1D23) or, for older vehicles, a legacy one (three letters and four digits, e.g. ABC1234).
+func Plate() string {
+	return global.Plate()
+}
+
+// Plate generates a random vehicle plate, as the package function Plate.
+func (g *Generator) Plate() string {
+	plate := make([]byte, 7)
+	for i := 0; i < 3; i++ {
+		plate[i] = byte('A' + g.intn(26))
+	}
+	for i := 3; i < 7; i++ {
+		plate[i] = byte('0' + g.intn(10))
+	}
+	if g.intn(3) > 0 {
+		plate[4] = byte('A' + g.intn(10)) // the Mercosul letter stands for the legacy digit (0 = A to 9 = J)
+	}
+	return string(plate)
+}
+
+// ValidatePlate reports whether the plate is a legacy or Mercosul Brazilian vehicle plate.
+func ValidatePlate(plate string) bool {
+	return plateRe.MatchString(plate)
+}
@@ -0,0 +1,48 @@
+package br_documents
+
+import "testing"
+
+func TestValidatePlate(t *testing.T) {
+	tests := []struct {
+		name  string
+		plate string
+		valid bool
+	}{
+		{"Legacy", "ABC1234", true},
+		{"Mercosul", "BRA2E19", true},
+		{"Lowercase", "abc1234", false},
+		{"Masked", "ABC-1234", false},
+		{"Short", "ABC123", false},
+		{"Long", "ABC12345", false},
+		{"DigitInLetters", "AB01234", false},
+		{"LetterInDigits", "ABC1D2E", false},
+		{"Empty", "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if ValidatePlate(tc.plate) != tc.valid {
+				t.Errorf("Expected ValidatePlate(%s) to be %v", tc.plate, tc.valid)
+			}
+		})
+	}
+}
+
+func TestPlate_Generated(t *testing.T) {
+	g := New(1)
+	mercosul := 0
+	for i := 0; i < 300; i++ {
+		plate := g.Plate()
+		if !ValidatePlate(plate) {
+			t.Fatalf("Expected a valid plate, got %s", plate)
+		}
+		if plate[4] >= 'A' {
+			if plate[4] > 'J' {
+				t.Fatalf("Expected the Mercosul letter between A and J, got %s", plate)
+			}
+			mercosul++
+		}
+	}
+	if mercosul == 0 || mercosul == 300 {
+		t.Errorf("Expected both legacy and Mercosul plates, got %d Mercosul plates of 300", mercosul)
+	}
+}
@@ -0,0 +1,25 @@
+package br_documents
+
+// ufCodes maps each state abbreviation to its IBGE code, used in access keys (cUF).
+var ufCodes = map[string]string{
+	"RO": "11", "AC": "12", "AM": "13", "RR": "14", "PA": "15", "AP": "16", "TO": "17",
+	"MA": "21", "PI": "22", "CE": "23", "RN": "24", "PB": "25", "PE": "26", "AL": "27",
+	"SE": "28", "BA": "29", "MG": "31", "ES": "32", "RJ": "33", "SP": "35",
+	"PR": "41", "SC": "42", "RS": "43", "MS": "50", "MT": "51", "GO": "52", "DF": "53",
+}
+
+// UFCode returns the IBGE code of the state abbreviation (e.g., "SP" -> "35").
+func UFCode(uf string) (string, bool) {
+	code, ok := ufCodes[uf]
+	return code, ok
+}
+
+// UFByCode returns the state abbreviation of the IBGE code (e.g., "35" -> "SP").
+func UFByCode(code string) (string, bool) {
+	for uf, c := range ufCodes {
+		if c == code {
+			return uf, true
+		}
+	}
+	return "", false
+}
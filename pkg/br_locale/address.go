@@ -0,0 +1,61 @@
+package br_locale
+
+import "fmt"
+
+// streetTypes are the kinds of public places that start the street names. Most of them are streets.
+var streetTypes = []string{"Rua", "Rua", "Rua", "Avenida", "Avenida", "Travessa", "Alameda", "Praça", "Rodovia"}
+
+// streetNames are the usual honorees and dates of Brazilian street names.
+var streetNames = []string{
+	"Sete de Setembro", "XV de Novembro", "Quinze de Novembro", "Tiradentes", "Getúlio Vargas",
+	"Dom Pedro II", "Marechal Deodoro", "Barão do Rio Branco", "Santos Dumont", "Rui Barbosa",
+	"Presidente Vargas", "Juscelino Kubitschek", "Princesa Isabel", "Duque de Caxias", "José Bonifácio",
+	"Castro Alves", "Machado de Assis", "Senador Feijó", "Floriano Peixoto", "Benjamin Constant",
+	"das Flores", "das Palmeiras", "dos Andradas", "da Liberdade", "da Independência", "Brasil",
+	"São João", "Santa Catarina", "Paraná", "Amazonas", "Bahia", "Minas Gerais",
+}
+
+// neighborhoods are common Brazilian neighborhood names.
+var neighborhoods = []string{
+	"Centro", "Centro", "Jardim América", "Jardim Europa", "Vila Nova", "Vila Mariana", "Boa Vista",
+	"Santa Cruz", "São José", "Bela Vista", "Jardim Paulista", "Industrial", "Distrito Industrial",
+	"Cidade Nova", "Parque das Nações", "Santo Antônio", "Liberdade", "Alto da Glória", "Vila Rica",
+	"Nossa Senhora Aparecida", "Planalto", "Jardim das Flores", "Bom Retiro", "Campo Grande",
+}
+
+// complements are the formats of address complements, filled with a number.
+var complements = []string{"Sala %d", "Apto %d", "Loja %d", "Bloco %d", "Galpão %d", "Casa %d", "Fundos", "Térreo"}
+
+// Street returns the name of a public place, e.g. "Avenida Getúlio Vargas".
+func Street() string {
+	return global.Street()
+}
+
+// Neighborhood returns the name of a neighborhood (bairro).
+func Neighborhood() string {
+	return global.Neighborhood()
+}
+
+// Complement returns the complement of an address, e.g. "Sala 204".
+func Complement() string {
+	return global.Complement()
+}
+
+// Street returns the name of a public place, e.g. "Avenida Getúlio Vargas".
+func (g *Generator) Street() string {
+	return g.pick(streetTypes) + " " + g.pick(streetNames)
+}
+
+// Neighborhood returns the name of a neighborhood (bairro).
+func (g *Generator) Neighborhood() string {
+	return g.pick(neighborhoods)
+}
+
+// Complement returns the complement of an address, e.g. "Sala 204".
+func (g *Generator) Complement() string {
+	format := g.pick(complements)
+	if format == "Fundos" || format == "Térreo" {
+		return format
+	}
+	return fmt.Sprintf(format, g.intn(300)+1)
+}
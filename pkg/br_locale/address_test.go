@@ -0,0 +1,15 @@
+package br_locale
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestStreet(t *testing.T) {
+	streetRe := regexp.MustCompile(`^(Rua|Avenida|Travessa|Alameda|Praça|Rodovia) `)
+	for i := 0; i < 500; i++ {
+		if street := Street(); !streetRe.MatchString(street) {
+			t.Errorf("Expected a street type, got %s", street)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package br_locale
+
+import (
+	"fmt"
+	"strings"
+)
+
+// emailProviders are the Brazilian email providers of the people.
+var emailProviders = []string{"uol.com.br", "bol.com.br", "terra.com.br", "ig.com.br", "yahoo.com.br", "globo.com.br"}
+
+// companyMailboxes are the mailboxes of the companies that receive invoices.
+var companyMailboxes = []string{"contato", "financeiro", "fiscal", "nfe", "compras", "vendas"}
+
+// unaccented maps the accented letters of Portuguese to the plain ones.
+var unaccented = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ü", "u", "ç", "c",
+)
+
+// Email derives an email address of the .com.br domain from the name, as Generator.Email.
+func Email(name string) string {
+	return global.Email(name)
+}
+
+// Email derives an email address of the .com.br domain from the name: a mailbox of the company
+// domain for company names (e.g. fiscal@oliveiracosta.com.br) and a personal address at a Brazilian
+// provider for people (e.g. maria.souza27@uol.com.br).
+func (g *Generator) Email(name string) string {
+	words := strings.Fields(unaccented.Replace(strings.ToLower(name)))
+	var plain []string
+	for _, word := range words {
+		word = strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return -1
+		}, word)
+		if word != "" {
+			plain = append(plain, word)
+		}
+	}
+	if len(plain) == 0 {
+		plain = []string{strings.ToLower(unaccented.Replace(g.LastName()))}
+	}
+
+	if TradeName(name) != name {
+		// A company: its domain is made of the first words of its name.
+		domain := plain[0]
+		if len(plain) > 1 {
+			domain += plain[1]
+		}
+		return fmt.Sprintf("%s@%s.com.br", g.pick(companyMailboxes), truncate(domain, 30))
+	}
+	user := plain[0]
+	if len(plain) > 1 {
+		user += "." + plain[len(plain)-1]
+	}
+	if g.intn(2) == 0 {
+		user += fmt.Sprintf("%d", g.intn(99)+1)
+	}
+	return fmt.Sprintf("%s@%s", truncate(user, 40), g.pick(emailProviders))
+}
+
+// truncate cuts the ASCII text to at most size characters.
+func truncate(text string, size int) string {
+	if len(text) > size {
+		return text[:size]
+	}
+	return text
+}
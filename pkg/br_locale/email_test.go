@@ -0,0 +1,21 @@
+package br_locale
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestEmail(t *testing.T) {
+	emailRe := regexp.MustCompile(`^[a-z0-9.]+@[a-z0-9]+(\.[a-z]+)*\.com\.br$`)
+	for i := 0; i < 500; i++ {
+		for _, name := range []string{CompanyName(), PersonName()} {
+			if address := Email(name); !emailRe.MatchString(address) || len(address) > 60 {
+				t.Errorf("Expected a .com.br email derived from %s, got %s", name, address)
+			}
+		}
+	}
+	if email := Email("João Conceição"); !strings.HasPrefix(email, "joao.conceicao") {
+		t.Errorf("Expected an unaccented email, got %s", email)
+	}
+}
@@ -0,0 +1,56 @@
+package br_locale
+
+// Municipality is a municipality of the IBGE table: its code (cMun), name (xMun) and state.
+type Municipality struct {
+	Code string
+	Name string
+	UF   string
+}
+
+// municipalities lists a few real municipalities of each state.
+var municipalities = []Municipality{
+	{"1200401", "Rio Branco", "AC"}, {"1200203", "Cruzeiro do Sul", "AC"},
+	{"2704302", "Maceió", "AL"}, {"2700300", "Arapiraca", "AL"},
+	{"1302603", "Manaus", "AM"}, {"1303403", "Parintins", "AM"},
+	{"1600303", "Macapá", "AP"}, {"1600600", "Santana", "AP"},
+	{"2927408", "Salvador", "BA"}, {"2910800", "Feira de Santana", "BA"}, {"2933307", "Vitória da Conquista", "BA"}, {"2914802", "Itabuna", "BA"},
+	{"2304400", "Fortaleza", "CE"}, {"2303709", "Caucaia", "CE"}, {"2307304", "Juazeiro do Norte", "CE"},
+	{"5300108", "Brasília", "DF"},
+	{"3205309", "Vitória", "ES"}, {"3205200", "Vila Velha", "ES"}, {"3205002", "Serra", "ES"},
+	{"5208707", "Goiânia", "GO"}, {"5201108", "Anápolis", "GO"}, {"5201405", "Aparecida de Goiânia", "GO"},
+	{"2111300", "São Luís", "MA"}, {"2105302", "Imperatriz", "MA"},
+	{"3106200", "Belo Horizonte", "MG"}, {"3170206", "Uberlândia", "MG"}, {"3118601", "Contagem", "MG"}, {"3136702", "Juiz de Fora", "MG"},
+	{"5002704", "Campo Grande", "MS"}, {"5003702", "Dourados", "MS"},
+	{"5103403", "Cuiabá", "MT"}, {"5108402", "Várzea Grande", "MT"}, {"5107602", "Rondonópolis", "MT"},
+	{"1501402", "Belém", "PA"}, {"1500800", "Ananindeua", "PA"}, {"1506807", "Santarém", "PA"},
+	{"2507507", "João Pessoa", "PB"}, {"2504009", "Campina Grande", "PB"},
+	{"2611606", "Recife", "PE"}, {"2609600", "Olinda", "PE"}, {"2604106", "Caruaru", "PE"}, {"2611101", "Petrolina", "PE"},
+	{"2211001", "Teresina", "PI"}, {"2207702", "Parnaíba", "PI"},
+	{"4106902", "Curitiba", "PR"}, {"4113700", "Londrina", "PR"}, {"4115200", "Maringá", "PR"},
+	{"3304557", "Rio de Janeiro", "RJ"}, {"3303302", "Niterói", "RJ"},
+	{"2408102", "Natal", "RN"}, {"2408003", "Mossoró", "RN"},
+	{"1100205", "Porto Velho", "RO"}, {"1100122", "Ji-Paraná", "RO"},
+	{"1400100", "Boa Vista", "RR"}, {"1400472", "Rorainópolis", "RR"},
+	{"4314902", "Porto Alegre", "RS"}, {"4305108", "Caxias do Sul", "RS"}, {"4314407", "Pelotas", "RS"},
+	{"4205407", "Florianópolis", "SC"}, {"4209102", "Joinville", "SC"}, {"4202404", "Blumenau", "SC"},
+	{"2800308", "Aracaju", "SE"}, {"2804805", "Nossa Senhora do Socorro", "SE"}, {"2803500", "Lagarto", "SE"},
+	{"3550308", "São Paulo", "SP"}, {"3509502", "Campinas", "SP"}, {"3548500", "Santos", "SP"}, {"3518800", "Guarulhos", "SP"}, {"3543402", "Ribeirão Preto", "SP"}, {"3552205", "Sorocaba", "SP"},
+	{"1721000", "Palmas", "TO"}, {"1702109", "Araguaína", "TO"},
+}
+
+// Municipalities returns the municipalities of the state, or of every state when uf is empty.
+func Municipalities(uf string) []Municipality {
+	var found []Municipality
+	for _, m := range municipalities {
+		if uf == "" || m.UF == uf {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+// Municipality returns a random municipality of the state, or of any state when uf is empty.
+func (g *Generator) Municipality(uf string) Municipality {
+	candidates := Municipalities(uf)
+	return candidates[g.intn(len(candidates))]
+}
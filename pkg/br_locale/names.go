@@ -0,0 +1,147 @@
+package br_locale
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// init seeds the random number generator to ensure varied outputs.
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
+
+// firstNames are common Brazilian first names.
+var firstNames = []string{
+	"Ana", "Maria", "Francisca", "Antônia", "Adriana", "Juliana", "Márcia", "Fernanda", "Patrícia", "Aline",
+	"Camila", "Beatriz", "Larissa", "Letícia", "Gabriela", "Mariana", "Luana", "Vitória", "Sandra", "Cláudia",
+	"José", "João", "Antônio", "Francisco", "Carlos", "Paulo", "Pedro", "Lucas", "Luiz", "Marcos",
+	"Luís", "Gabriel", "Rafael", "Daniel", "Marcelo", "Bruno", "Eduardo", "Felipe", "Rodrigo", "Gustavo",
+	"Thiago", "Mateus", "Leonardo", "Vinícius", "Sebastião", "Raimundo", "Fábio", "Diego", "André", "Otávio",
+}
+
+// middleNames are Brazilian names usually given as a second first name.
+var middleNames = []string{
+	"Aparecida", "Cristina", "Eduarda", "Helena", "Clara", "Luiza", "Fernanda", "Carolina",
+	"Henrique", "Augusto", "Eduardo", "Roberto", "Miguel", "Vitor", "Paulo", "Felipe",
+}
+
+// lastNames are common Brazilian family names.
+var lastNames = []string{
+	"Silva", "Santos", "Oliveira", "Souza", "Rodrigues", "Ferreira", "Alves", "Pereira", "Lima", "Gomes",
+	"Costa", "Ribeiro", "Martins", "Carvalho", "Almeida", "Lopes", "Soares", "Fernandes", "Vieira", "Barbosa",
+	"Rocha", "Dias", "Nascimento", "Andrade", "Moreira", "Nunes", "Marques", "Machado", "Mendes", "Freitas",
+	"Cardoso", "Ramos", "Gonçalves", "Santana", "Teixeira", "Araújo", "Monteiro", "Moura", "Cavalcanti", "Batista",
+}
+
+// activities are the lines of business of the company names.
+var activities = []string{
+	"Comércio de Alimentos", "Distribuidora de Bebidas", "Materiais de Construção", "Supermercados",
+	"Autopeças", "Comércio de Eletrônicos", "Indústria Têxtil", "Móveis e Decorações", "Farmácia",
+	"Confecções", "Comércio Atacadista", "Papelaria", "Informática", "Ferragens", "Indústria de Plásticos",
+	"Agropecuária", "Cosméticos", "Calçados", "Transportes", "Padaria e Confeitaria",
+}
+
+// legalSuffixes are the legal forms that end the company names. LTDA is the most common one.
+var legalSuffixes = []string{"LTDA", "LTDA", "LTDA", "ME", "S.A.", "EIRELI"}
+
+// Generator draws pt-BR data from its own source of randomness, so that the same seed gives the same
+// data. A Generator is not safe for concurrent use; the package functions, which draw from the global
+// source, are.
+type Generator struct {
+	rand *rand.Rand // nil for the package functions
+}
+
+// New returns a Generator seeded with the seed.
+func New(seed int64) *Generator {
+	return &Generator{rand: rand.New(rand.NewSource(seed))}
+}
+
+// global is the Generator of the package functions.
+var global = &Generator{}
+
+// FirstName returns a random Brazilian first name.
+func FirstName() string {
+	return global.FirstName()
+}
+
+// LastName returns a random Brazilian family name.
+func LastName() string {
+	return global.LastName()
+}
+
+// PersonName returns the full name of a person, as Generator.PersonName.
+func PersonName() string {
+	return global.PersonName()
+}
+
+// CompanyName returns the corporate name of a company, as Generator.CompanyName.
+func CompanyName() string {
+	return global.CompanyName()
+}
+
+// FirstName returns a random Brazilian first name.
+func (g *Generator) FirstName() string {
+	return g.pick(firstNames)
+}
+
+// LastName returns a random Brazilian family name.
+func (g *Generator) LastName() string {
+	return g.pick(lastNames)
+}
+
+// PersonName returns the full name of a person, with one or two first names and one or two family
+// names, e.g. "Maria Aparecida Souza Santos".
+func (g *Generator) PersonName() string {
+	parts := []string{g.FirstName()}
+	if g.intn(3) == 0 {
+		parts = append(parts, g.pick(middleNames))
+	}
+	if g.intn(2) == 0 {
+		parts = append(parts, g.LastName())
+	}
+	last := g.LastName()
+	for last == parts[len(parts)-1] {
+		last = g.LastName()
+	}
+	return strings.Join(append(parts, last), " ")
+}
+
+// CompanyName returns the corporate name of a company: one or two family names, its line of business
+// and its legal form, e.g. "Oliveira & Costa Materiais de Construção LTDA". Names are at most 60
+// characters long, the size of xNome.
+func (g *Generator) CompanyName() string {
+	owners := g.LastName()
+	if g.intn(3) == 0 {
+		partner := g.LastName()
+		for partner == owners {
+			partner = g.LastName()
+		}
+		owners += " & " + partner
+	}
+	return owners + " " + g.pick(activities) + " " + g.pick(legalSuffixes)
+}
+
+// TradeName returns the trade name of the company (xFant), which is its corporate name without the
+// legal form.
+func TradeName(company string) string {
+	for _, suffix := range legalSuffixes {
+		if trimmed, ok := strings.CutSuffix(company, " "+suffix); ok {
+			return trimmed
+		}
+	}
+	return company
+}
+
+// intn returns a random number from 0 to n-1.
+func (g *Generator) intn(n int) int {
+	if g.rand == nil {
+		return rand.Intn(n)
+	}
+	return g.rand.Intn(n)
+}
+
+// pick returns a random element of the list.
+func (g *Generator) pick(list []string) string {
+	return list[g.intn(len(list))]
+}
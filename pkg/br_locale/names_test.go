@@ -0,0 +1,31 @@
+package br_locale
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	for i := 0; i < 500; i++ {
+		company := CompanyName()
+		if len([]rune(company)) > 60 || TradeName(company) == company {
+			t.Errorf("Expected a corporate name of up to 60 characters with its legal form, got %s", company)
+		}
+		person := PersonName()
+		if len(strings.Fields(person)) < 2 || TradeName(person) != person {
+			t.Errorf("Expected the full name of a person, got %s", person)
+		}
+	}
+}
+
+func TestNew_Seed(t *testing.T) {
+	first, second := New(7), New(7)
+	for i := 0; i < 20; i++ {
+		if a, b := first.CompanyName(), second.CompanyName(); a != b {
+			t.Fatalf("Expected the same names for the same seed, got %s and %s", a, b)
+		}
+		if a, b := first.Phone("SP"), second.Phone("SP"); a != b {
+			t.Fatalf("Expected the same phones for the same seed, got %s and %s", a, b)
+		}
+	}
+}
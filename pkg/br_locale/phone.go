@@ -0,0 +1,74 @@
+package br_locale
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+)
+
+// phoneRe matches the phones with the area code: landlines (2 to 5 and seven digits) and mobiles
+// (9 and eight digits).
+var phoneRe = regexp.MustCompile(`^([1-9][1-9])([2-5][0-9]{7}|9[0-9]{8})$`)
+
+// ddds are the area codes (DDD) of each state.
+var ddds = map[string][]string{
+	"AC": {"68"},
+	"AL": {"82"},
+	"AM": {"92", "97"},
+	"AP": {"96"},
+	"BA": {"71", "73", "74", "75", "77"},
+	"CE": {"85", "88"},
+	"DF": {"61"},
+	"ES": {"27", "28"},
+	"GO": {"62", "64"},
+	"MA": {"98", "99"},
+	"MG": {"31", "32", "33", "34", "35", "37", "38"},
+	"MS": {"67"},
+	"MT": {"65", "66"},
+	"PA": {"91", "93", "94"},
+	"PB": {"83"},
+	"PE": {"81", "87"},
+	"PI": {"86", "89"},
+	"PR": {"41", "42", "43", "44", "45", "46"},
+	"RJ": {"21", "22", "24"},
+	"RN": {"84"},
+	"RO": {"69"},
+	"RR": {"95"},
+	"RS": {"51", "53", "54", "55"},
+	"SC": {"47", "48", "49"},
+	"SE": {"79"},
+	"SP": {"11", "12", "13", "14", "15", "16", "17", "18", "19"},
+	"TO": {"63"},
+}
+
+// DDDs returns the area codes of the state, or nil for an unknown state.
+func DDDs(uf string) []string {
+	return ddds[uf]
+}
+
+// Phone generates a phone number of the state, as Generator.Phone.
+func Phone(uf string) string {
+	return global.Phone(uf)
+}
+
+// Phone generates a phone number of the state with its area code and no mask: a mobile number
+// (e.g. 11987654321) or, a third of the time, a landline (e.g. 1132654321). A state that is not
+// Brazilian, such as EX, gets the area code of any state.
+func (g *Generator) Phone(uf string) string {
+	codes, ok := ddds[uf]
+	if !ok {
+		codes = ddds[g.pick(slices.Sorted(maps.Keys(ddds)))]
+	}
+	ddd := g.pick(codes)
+	if g.intn(3) == 0 {
+		return fmt.Sprintf("%s%d%07d", ddd, g.intn(4)+2, g.intn(10000000))
+	}
+	return fmt.Sprintf("%s9%08d", ddd, g.intn(100000000))
+}
+
+// ValidatePhone reports whether the phone is a landline or mobile number with an area code of the state.
+func ValidatePhone(uf, phone string) bool {
+	match := phoneRe.FindStringSubmatch(phone)
+	return match != nil && slices.Contains(ddds[uf], match[1])
+}
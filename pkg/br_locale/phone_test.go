@@ -0,0 +1,19 @@
+package br_locale
+
+import "testing"
+
+func TestPhone(t *testing.T) {
+	for uf := range ddds {
+		for i := 0; i < 50; i++ {
+			if phone := Phone(uf); !ValidatePhone(uf, phone) {
+				t.Errorf("Expected a phone with a DDD of %s, got %s", uf, phone)
+			}
+		}
+	}
+	if ValidatePhone("SP", "2198765432") {
+		t.Errorf("Expected the DDD 21 to be invalid for SP")
+	}
+	if phone := Phone("EX"); !phoneRe.MatchString(phone) {
+		t.Errorf("Expected a phone of some state for a party abroad, got %s", phone)
+	}
+}
@@ -0,0 +1,76 @@
+package br_locale
+
+// Product is a product of the catalog: its NCM, the CEST when the NCM is listed for ICMS-ST
+// (Convênio ICMS 142/2018), the description, the commercial unit and a typical unit price.
+type Product struct {
+	NCM         string
+	CEST        string
+	Description string
+	Unit        string
+	Price       int64 // in cents
+	Fractional  bool  // sold by weight or measure, in bulk and without a GTIN
+}
+
+// products lists real NCM codes with Brazilian product descriptions.
+var products = []Product{
+	// Food and groceries
+	{NCM: "10063021", Description: "Arroz branco tipo 1 pacote 5kg", Unit: "UN", Price: 2890},
+	{NCM: "07133319", Description: "Feijao carioca tipo 1 pacote 1kg", Unit: "UN", Price: 849},
+	{NCM: "17019900", Description: "Acucar cristal pacote 5kg", Unit: "UN", Price: 2199},
+	{NCM: "09012100", Description: "Cafe torrado e moido 500g", Unit: "UN", Price: 1890},
+	{NCM: "15079011", Description: "Oleo de soja refinado 900ml", Unit: "UN", Price: 799},
+	{NCM: "04012010", Description: "Leite UHT integral 1L", Unit: "LT", Price: 549},
+	{NCM: "19053100", Description: "Biscoito recheado chocolate 140g", Unit: "UN", Price: 329},
+	{NCM: "08039000", Description: "Banana prata", Unit: "KG", Price: 699, Fractional: true},
+	{NCM: "07020000", Description: "Tomate italiano", Unit: "KG", Price: 899, Fractional: true},
+	{NCM: "02013000", Description: "Carne bovina alcatra", Unit: "KG", Price: 4990, Fractional: true},
+	{NCM: "04061010", Description: "Queijo mussarela fatiado", Unit: "KG", Price: 4490, Fractional: true},
+	{NCM: "21050010", CEST: "2300100", Description: "Sorvete de creme pote 2L", Unit: "UN", Price: 2490},
+
+	// Beverages
+	{NCM: "22011000", CEST: "0300100", Description: "Agua mineral sem gas 500ml", Unit: "UN", Price: 250},
+	{NCM: "22021000", CEST: "0301100", Description: "Refrigerante de cola garrafa 2L", Unit: "UN", Price: 999},
+	{NCM: "22030000", CEST: "0302100", Description: "Cerveja pilsen lata 350ml", Unit: "UN", Price: 429},
+	{NCM: "22030000", CEST: "0302100", Description: "Cerveja pilsen caixa com 12 latas", Unit: "CX", Price: 4990},
+	{NCM: "22042100", Description: "Vinho tinto seco 750ml", Unit: "UN", Price: 4590},
+
+	// Household and personal care
+	{NCM: "34022000", Description: "Detergente liquido neutro 500ml", Unit: "UN", Price: 279},
+	{NCM: "34022000", Description: "Sabao em po 1,6kg", Unit: "UN", Price: 2190},
+	{NCM: "48181000", Description: "Papel higienico folha dupla com 12 rolos", Unit: "UN", Price: 2290},
+	{NCM: "33051000", Description: "Shampoo 350ml", Unit: "UN", Price: 1890},
+	{NCM: "23091000", CEST: "2200100", Description: "Racao para caes adultos 15kg", Unit: "UN", Price: 15990},
+
+	// Apparel and stationery
+	{NCM: "61091000", Description: "Camiseta de algodao manga curta", Unit: "UN", Price: 4990},
+	{NCM: "62034200", Description: "Calca jeans masculina", Unit: "UN", Price: 13990},
+	{NCM: "64041100", Description: "Tenis esportivo", Unit: "UN", Price: 29990},
+	{NCM: "48202000", Description: "Caderno espiral 10 materias", Unit: "UN", Price: 2990},
+
+	// Building materials and hardware
+	{NCM: "25232910", CEST: "0500100", Description: "Cimento Portland CP II saco 50kg", Unit: "UN", Price: 3890},
+	{NCM: "32091010", CEST: "2400100", Description: "Tinta acrilica fosca branca 18L", Unit: "UN", Price: 34990},
+	{NCM: "69072100", Description: "Porcelanato polido 60x60", Unit: "M2", Price: 6990, Fractional: true},
+	{NCM: "73181500", Description: "Parafuso sextavado zincado caixa com 100", Unit: "CX", Price: 3490},
+	{NCM: "85444900", Description: "Cabo flexivel 2,5mm", Unit: "M", Price: 329, Fractional: true},
+
+	// Vehicles parts
+	{NCM: "40111000", CEST: "1600100", Description: "Pneu 175/70 R13", Unit: "UN", Price: 34990},
+	{NCM: "27101932", CEST: "0600600", Description: "Oleo lubrificante motor 5W30 1L", Unit: "LT", Price: 4590},
+
+	// Electronics and appliances
+	{NCM: "84713012", Description: "Notebook 15,6 pol 8GB 256GB SSD", Unit: "UN", Price: 329900},
+	{NCM: "85171300", Description: "Smartphone 128GB", Unit: "UN", Price: 189900},
+	{NCM: "85163200", Description: "Secador de cabelo 2000W", Unit: "UN", Price: 15990},
+	{NCM: "85395000", Description: "Lampada LED bulbo 9W", Unit: "UN", Price: 1290},
+}
+
+// Products returns the products of the catalog.
+func Products() []Product {
+	return append([]Product(nil), products...)
+}
+
+// Product returns a random product of the catalog.
+func (g *Generator) Product() Product {
+	return products[g.intn(len(products))]
+}
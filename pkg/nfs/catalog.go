@@ -0,0 +1,89 @@
+package nfs
+
+import (
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+	"github.com/mayckol/brfiscalfaker/pkg/br_locale"
+)
+
+// catalogProduct is a product of the catalog: its NCM, the CEST when the NCM is listed for
+// ICMS-ST (Convênio ICMS 142/2018), the description, the commercial unit and a typical unit price.
+type catalogProduct struct {
+	ncm         string
+	cest        string
+	description string
+	unit        string
+	price       cents
+	fractional  bool // sold by weight or measure, in bulk and without a GTIN
+}
+
+// catalog lists the products of the pt-BR catalog, with real NCM codes and Brazilian descriptions.
+var catalog = catalogProducts(br_locale.Products())
+
+// catalogProducts converts products of the pt-BR catalog.
+func catalogProducts(products []br_locale.Product) []catalogProduct {
+	converted := make([]catalogProduct, len(products))
+	for i, product := range products {
+		converted[i] = newCatalogProduct(product)
+	}
+	return converted
+}
+
+// newCatalogProduct converts a product of the pt-BR catalog.
+func newCatalogProduct(product br_locale.Product) catalogProduct {
+	return catalogProduct{
+		ncm:         product.NCM,
+		cest:        product.CEST,
+		description: product.Description,
+		unit:        product.Unit,
+		price:       cents(product.Price),
+		fractional:  product.Fractional,
+	}
+}
+
+// randomProduct picks a product of the catalog. Items subject to ICMS-ST get a product listed for ST.
+func randomProduct(st bool) catalogProduct {
+	candidates := catalog
+	if st {
+		candidates = nil
+		for _, product := range catalog {
+			if product.cest != "" {
+				candidates = append(candidates, product)
+			}
+		}
+	}
+	return candidates[gofakeit.Number(0, len(candidates)-1)]
+}
+
+// productGTIN returns the GTIN of the product, or "" for bulk products, which have none.
+func productGTIN(product catalogProduct) string {
+	if product.fractional {
+		return ""
+	}
+	return br_documents.GTIN()
+}
+
+// productValue returns the value of a product placeholder of the item: the description, NCM,
+// CEST, GTIN and unit of its catalog product, or of its service. ok is false for the other
+// placeholders. Goods without a GTIN are SEM GTIN, except in the CF-e, where cEAN is left out.
+func (i *mockItem) productValue(key string, tt TemplateType) (value string, ok bool) {
+	if i.service != nil {
+		return i.serviceProductValue(key)
+	}
+	switch key {
+	case "xProd", "detProdXProd":
+		return i.product.description, true
+	case "NCM", "detProdNCM":
+		return i.product.ncm, true
+	case "CEST", "detProdCEST":
+		return i.product.cest, true
+	case "cEAN", "detProdCEAN":
+		if i.gtin == "" && tt != CFe {
+			return "SEM GTIN", true
+		}
+		return i.gtin, true
+	case "uCom", "detProdUCom":
+		return i.product.unit, true
+	}
+	return "", false
+}
@@ -0,0 +1,60 @@
+package nfs
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+func TestCatalog(t *testing.T) {
+	ncm := regexp.MustCompile(`^[0-9]{8}$`)
+	cest := regexp.MustCompile(`^([0-9]{7})?$`)
+	for _, product := range catalog {
+		if !ncm.MatchString(product.ncm) || !cest.MatchString(product.cest) {
+			t.Errorf("Invalid NCM or CEST for %q: %s %s", product.description, product.ncm, product.cest)
+		}
+		if product.unit == "" || product.price <= 0 {
+			t.Errorf("Expected a unit and a price for %q", product.description)
+		}
+	}
+}
+
+func TestGeneratedProducts(t *testing.T) {
+	cEAN := regexp.MustCompile(`<cEAN>([^<]*)</cEAN>`)
+	for _, tt := range []TemplateType{CFe, NFCe, NFe, NFeDevolucao} {
+		t.Run(tt.String(), func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				generator, err := NewTemplateGenerator(tt)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				xmlBytes, err := generator.Generate()
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				for _, match := range cEAN.FindAllStringSubmatch(string(xmlBytes), -1) {
+					if match[1] != "SEM GTIN" && !br_documents.ValidateGTIN(match[1]) {
+						t.Errorf("Invalid GTIN: %s", match[1])
+					}
+				}
+				if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+					t.Fatalf("Expected no schema violations, got %v", errs)
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratedProducts_ST(t *testing.T) {
+	doc, _ := newMockDocument(NFe, &generationConfig{icms: []ICMSSituation{CST10, CST00, CST60}})
+	for _, item := range doc.items {
+		spec := icmsSpecs[item.icms]
+		if (spec.st || spec.stRetained) && item.product.cest == "" {
+			t.Errorf("Expected a CEST for the %v item %d, got %q", item.icms, item.number, item.product.ncm)
+		}
+		if item.product.fractional == (item.gtin != "") {
+			t.Errorf("Expected a GTIN for packaged goods only, got %q for %q", item.gtin, item.product.description)
+		}
+	}
+}
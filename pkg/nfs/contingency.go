@@ -0,0 +1,175 @@
+package nfs
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// Contingency is an emission mode used when the authorizing SEFAZ is unavailable, informed in
+// tpEmis, in the access key and with the moment (dhCont) and reason (xJust) of the contingency.
+type Contingency int
+
+const (
+	// ContingencyNone is the normal emission (tpEmis 1).
+	ContingencyNone Contingency = iota
+	// ContingencyFSDA prints the DANFE on security forms (FS-DA, tpEmis 5) to authorize it later.
+	ContingencyFSDA
+	// ContingencySVCAN is authorized by the SEFAZ Virtual de Contingência do Ambiente Nacional (tpEmis 6).
+	ContingencySVCAN
+	// ContingencySVCRS is authorized by the SEFAZ Virtual de Contingência do Rio Grande do Sul (tpEmis 7).
+	ContingencySVCRS
+	// ContingencyEPEC registers the prior emission event (EPEC, tpEmis 4) to authorize it later.
+	ContingencyEPEC
+	// ContingencyOfflineNFCe is the NFC-e issued offline (tpEmis 9), which has no authorization
+	// protocol yet and carries the offline QR Code.
+	ContingencyOfflineNFCe
+)
+
+// contingencySpec describes a Contingency: its name, tpEmis, the model of the documents it applies
+// to and the states served by it, any state when empty.
+type contingencySpec struct {
+	name   string
+	tpEmis string
+	model  string
+	states []string
+}
+
+// contingencySpecs holds the spec of each Contingency.
+var contingencySpecs = map[Contingency]contingencySpec{
+	ContingencyFSDA:  {name: "ContingencyFSDA", tpEmis: "5", model: "55"},
+	ContingencySVCAN: {name: "ContingencySVCAN", tpEmis: "6", model: "55", states: []string{"AC", "AL", "AP", "DF", "ES", "MG", "PB", "RJ", "RN", "RO", "RR", "RS", "SC", "SE", "SP", "TO"}},
+	ContingencySVCRS: {name: "ContingencySVCRS", tpEmis: "7", model: "55", states: []string{"AM", "BA", "CE", "GO", "MA", "MS", "MT", "PA", "PE", "PI", "PR"}},
+	ContingencyEPEC:  {name: "ContingencyEPEC", tpEmis: "4", model: "55"},
+	ContingencyOfflineNFCe: {
+		name: "ContingencyOfflineNFCe", tpEmis: "9", model: "65",
+	},
+}
+
+// contingencyReasons are the reasons (xJust) of entering the contingency.
+var contingencyReasons = []string{
+	"SEFAZ autorizadora indisponivel para recepcao de documentos",
+	"Falha de comunicacao com a SEFAZ autorizadora",
+	"Problemas tecnicos de conexao com a internet no estabelecimento",
+	"Instabilidade no servico de autorizacao da SEFAZ",
+	"Tempo de resposta da SEFAZ excedido na autorizacao do documento",
+	"Queda de energia no provedor de acesso a internet",
+}
+
+// String returns the name of the Contingency.
+func (c Contingency) String() string {
+	if c == ContingencyNone {
+		return "ContingencyNone"
+	}
+	if spec, ok := contingencySpecs[c]; ok {
+		return spec.name
+	}
+	return "Unknown"
+}
+
+// ParseContingency converts a string (e.g. "ContingencySVCAN" or "SVCAN") to a Contingency.
+func ParseContingency(s string) (Contingency, error) {
+	name := "Contingency" + strings.TrimPrefix(s, "Contingency")
+	if name == "ContingencyNone" {
+		return ContingencyNone, nil
+	}
+	for contingency, spec := range contingencySpecs {
+		if strings.EqualFold(spec.name, name) {
+			return contingency, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid Contingency: %s", s)
+}
+
+// checkContingency fails when the contingency cannot be used for a document of the TemplateType:
+// NF-e documents use FS-DA, SVC or EPEC, NFC-e documents are issued offline, and the SVC serves
+// the emitter state of the referenced invoice or of the sequence.
+func checkContingency(tt TemplateType, cfg *generationConfig) error {
+	if cfg.contingency == ContingencyNone {
+		return nil
+	}
+	spec, ok := contingencySpecs[cfg.contingency]
+	if !ok {
+		return fmt.Errorf("unknown contingency: %d", cfg.contingency)
+	}
+	model := "55"
+	switch tt {
+	case CFe:
+		model = "59"
+	case NFCe:
+		model = "65"
+	}
+	if spec.model != model {
+		return fmt.Errorf("%v is not supported for %v documents", cfg.contingency, tt)
+	}
+	if spec.states != nil {
+		uf := ""
+		switch {
+		case cfg.reference != nil:
+			uf = cfg.reference.emitterUF(tt)
+		case cfg.sequence != nil:
+			uf = cfg.sequence.emitterUF("")
+		}
+		if uf != "" && !slices.Contains(spec.states, uf) {
+			return fmt.Errorf("%v does not serve the emitter state %s", cfg.contingency, uf)
+		}
+	}
+	return nil
+}
+
+// contingencyUF returns the state of the emitter served by the contingency.
+func contingencyUF(contingency Contingency, uf string) string {
+	states := contingencySpecs[contingency].states
+	if len(states) == 0 || slices.Contains(states, uf) {
+		return uf
+	}
+	return gofakeit.RandomString(states)
+}
+
+// applyContingency sets tpEmis, which the access key carries, and the moment and reason of the
+// contingency, which started some minutes before the emission.
+func (doc *mockDocument) applyContingency(contingency Contingency) {
+	spec, ok := contingencySpecs[contingency]
+	if !ok {
+		return
+	}
+	doc.contingency = contingency
+	doc.tpEmis = spec.tpEmis
+	doc.dhCont = doc.dhEmi.Add(-time.Duration(gofakeit.Number(0, 180)) * time.Minute)
+	doc.xJust = gofakeit.RandomString(contingencyReasons)
+}
+
+// offline reports whether the document is an NFC-e issued offline, without its authorization protocol.
+func (doc *mockDocument) offline() bool {
+	return doc.contingency == ContingencyOfflineNFCe
+}
+
+// offlineQRCode generates the QR Code of an NFC-e issued offline (version 2), which carries the day
+// of the emission, the total and the digest of the signature in hexadecimal besides the access key.
+func offlineQRCode(accessKey, tpAmb string, doc *mockDocument, digestValue string) string {
+	digest, err := base64.StdEncoding.DecodeString(digestValue)
+	if err != nil {
+		digest = []byte(digestValue)
+	}
+	return fmt.Sprintf("https://www.fazenda.rj.gov.br/nfce/qrcode?p=%s|2|%s|%02d|%s|%s|1|%s", accessKey, tpAmb,
+		doc.dhEmi.Day(), doc.vNF, hex.EncodeToString(digest), gofakeit.Numerify("########################################"))
+}
+
+// unwrapProc leaves the document out of the nfeProc group, as a bare NFe without the protocol.
+func unwrapProc(document string) string {
+	lines := strings.Split(document, "\n")
+	var unwrapped []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "<nfeProc") || trimmed == "</nfeProc>" {
+			continue
+		}
+		unwrapped = append(unwrapped, strings.TrimPrefix(line, "  "))
+	}
+	return strings.Join(unwrapped, "\n")
+}
@@ -0,0 +1,132 @@
+package nfs
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseContingency(t *testing.T) {
+	for contingency := ContingencyNone; contingency <= ContingencyOfflineNFCe; contingency++ {
+		parsed, err := ParseContingency(contingency.String())
+		if err != nil || parsed != contingency {
+			t.Errorf("Expected %v, got %v (%v)", contingency, parsed, err)
+		}
+	}
+	if parsed, err := ParseContingency("svcan"); err != nil || parsed != ContingencySVCAN {
+		t.Errorf("Expected ContingencySVCAN, got %v (%v)", parsed, err)
+	}
+	if _, err := ParseContingency("DPEC"); err == nil {
+		t.Errorf("Expected an error for an unknown contingency")
+	}
+}
+
+func TestWithContingency(t *testing.T) {
+	idRe := regexp.MustCompile(`Id="NFe([0-9]{44})"`)
+	dhEmiRe := regexp.MustCompile(`<dhEmi>([^<]+)</dhEmi>`)
+	dhContRe := regexp.MustCompile(`<dhCont>([^<]+)</dhCont>`)
+	xJustRe := regexp.MustCompile(`<xJust>([^<]+)</xJust>`)
+	ufRe := regexp.MustCompile(`<enderEmit>(?s:.*?)<UF>([A-Z]{2})</UF>`)
+	for contingency := ContingencyFSDA; contingency <= ContingencyOfflineNFCe; contingency++ {
+		spec := contingencySpecs[contingency]
+		templateTypes := []TemplateType{NFe, NFeDevolucao, NFeComplementar}
+		if contingency == ContingencyOfflineNFCe {
+			templateTypes = []TemplateType{NFCe}
+		}
+		for _, tt := range templateTypes {
+			t.Run(contingency.String()+"/"+tt.String(), func(t *testing.T) {
+				generator, err := NewTemplateGenerator(tt)
+				if err != nil {
+					t.Fatalf("Failed to create generator: %v", err)
+				}
+				for i := 0; i < 10; i++ {
+					xmlBytes, err := generator.Generate(WithContingency(contingency))
+					if err != nil {
+						t.Fatalf("Expected no error, got %v", err)
+					}
+					document := string(xmlBytes)
+					if !strings.Contains(document, "<tpEmis>"+spec.tpEmis+"</tpEmis>") {
+						t.Errorf("Expected tpEmis %s in the document", spec.tpEmis)
+					}
+					if key := idRe.FindStringSubmatch(document); key == nil || key[1][34:35] != spec.tpEmis {
+						t.Errorf("Expected tpEmis %s in the access key, got %v", spec.tpEmis, key)
+					}
+					dhEmi, _ := time.Parse(dateTimeLayout, dhEmiRe.FindStringSubmatch(document)[1])
+					dhCont := dhContRe.FindStringSubmatch(document)
+					if dhCont == nil {
+						t.Fatalf("Expected dhCont in the document")
+					}
+					if start, _ := time.Parse(dateTimeLayout, dhCont[1]); start.After(dhEmi) {
+						t.Errorf("Expected the contingency to start before the emission, got %s", dhCont[1])
+					}
+					if xJust := xJustRe.FindStringSubmatch(document); xJust == nil || len(xJust[1]) < 15 || len(xJust[1]) > 256 {
+						t.Errorf("Expected a justification of 15 to 256 characters, got %v", xJust)
+					}
+					if spec.states != nil && !slices.Contains(spec.states, ufRe.FindStringSubmatch(document)[1]) {
+						t.Errorf("Expected an emitter in a state served by %v", contingency)
+					}
+					if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+						t.Fatalf("Expected no schema violations, got %v", errs)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestWithContingency_OfflineNFCe(t *testing.T) {
+	qrCodeRe := regexp.MustCompile(`qrcode\?p=([0-9]{44})\|2\|[12]\|([0-9]{2})\|([0-9]+\.[0-9]{2})\|([0-9a-f]{40})\|1\|[0-9A-Za-z]{40}`)
+	for i := 0; i < 20; i++ {
+		xmlBytes, err := NewNFCeGenerator().Generate(WithContingency(ContingencyOfflineNFCe))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		document := string(xmlBytes)
+		if strings.Contains(document, "<protNFe") || strings.Contains(document, "<nfeProc") {
+			t.Fatalf("Expected a bare NFe without the authorization protocol\n%s", document)
+		}
+		match := qrCodeRe.FindStringSubmatch(document)
+		if match == nil {
+			t.Fatalf("Expected the offline QR Code\n%s", document)
+		}
+		if !strings.Contains(document, "Id=\"NFe"+match[1]+"\"") || !strings.Contains(document, "<vNF>"+match[3]+"</vNF>") {
+			t.Errorf("Expected the access key and the total of the document in the QR Code, got %s", match[0])
+		}
+		if !regexp.MustCompile(`<dhEmi>[0-9]{4}-[0-9]{2}-` + match[2] + `T`).MatchString(document) {
+			t.Errorf("Expected the day of the emission in the QR Code, got %s", match[2])
+		}
+	}
+}
+
+func TestWithContingency_Errors(t *testing.T) {
+	referenced, err := NewNFeGenerator().Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate the referenced invoice: %v", err)
+	}
+	uf := regexp.MustCompile(`<enderEmit>(?s:.*?)<UF>([A-Z]{2})</UF>`).FindSubmatch(referenced)[1]
+	unserved := ContingencySVCRS
+	if slices.Contains(contingencySpecs[ContingencySVCRS].states, string(uf)) {
+		unserved = ContingencySVCAN
+	}
+
+	tests := []struct {
+		name      string
+		generator TemplateGenerator
+		options   []Option
+	}{
+		{"CFe", NewCFeGenerator(), []Option{WithContingency(ContingencyOfflineNFCe)}},
+		{"NFC-e in SVC", NewNFCeGenerator(), []Option{WithContingency(ContingencySVCAN)}},
+		{"offline NF-e", NewNFeGenerator(), []Option{WithContingency(ContingencyOfflineNFCe)}},
+		{"SVC of another state", NewNFeComplementarGenerator(), []Option{WithContingency(unserved), WithReferencedInvoice(referenced)}},
+		{"unknown contingency", NewNFeGenerator(), []Option{WithContingency(Contingency(99))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.generator.Generate(tt.options...); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
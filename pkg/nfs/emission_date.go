@@ -0,0 +1,57 @@
+package nfs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// ufOffsets are the UTC offsets, in hours, of the states whose official time is not the one of
+// Brasília (-03:00). Brazil has no daylight saving time since 2019.
+var ufOffsets = map[string]int{
+	"AC": -5,
+	"AM": -4, "MS": -4, "MT": -4, "RO": -4, "RR": -4,
+}
+
+// ufLocation returns the time zone of the state, with the fixed offset of its official time.
+func ufLocation(uf string) *time.Location {
+	offset, ok := ufOffsets[uf]
+	if !ok {
+		return brasilia
+	}
+	return time.FixedZone(fmt.Sprintf("UTC%d", offset), offset*60*60)
+}
+
+// checkEmissionDate fails when the emission range is empty, or ends before the emission of the
+// referenced invoice, which comes first.
+func checkEmissionDate(cfg *generationConfig) error {
+	if !cfg.emissionDate {
+		return nil
+	}
+	if cfg.emissionFrom.IsZero() || cfg.emissionTo.IsZero() {
+		return fmt.Errorf("invalid emission date: the date is required")
+	}
+	if cfg.emissionTo.Before(cfg.emissionFrom) {
+		return fmt.Errorf("invalid emission date range: %s is after %s",
+			cfg.emissionFrom.Format(dateTimeLayout), cfg.emissionTo.Format(dateTimeLayout))
+	}
+	if cfg.reference != nil && !cfg.emissionTo.After(cfg.reference.dhEmi) {
+		return fmt.Errorf("invalid emission date: the referenced invoice was emitted at %s",
+			cfg.reference.dhEmi.Format(dateTimeLayout))
+	}
+	return nil
+}
+
+// emissionDate picks the emission moment within the range of the config, after the emission of
+// the referenced invoice when there is one.
+func emissionDate(cfg *generationConfig) time.Time {
+	from, to := cfg.emissionFrom.Truncate(time.Second), cfg.emissionTo.Truncate(time.Second)
+	if cfg.reference != nil && !from.After(cfg.reference.dhEmi) {
+		from = cfg.reference.dhEmi.Add(time.Second)
+	}
+	if !to.After(from) {
+		return from
+	}
+	return from.Add(time.Duration(gofakeit.Number(0, int(to.Sub(from)/time.Second))) * time.Second)
+}
@@ -0,0 +1,122 @@
+package nfs
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestUFLocation(t *testing.T) {
+	tests := map[string]string{"SP": "-03:00", "AM": "-04:00", "MT": "-04:00", "AC": "-05:00", "PE": "-03:00"}
+	moment := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	for uf, offset := range tests {
+		if got := moment.In(ufLocation(uf)).Format("-07:00"); got != offset {
+			t.Errorf("Expected the offset %s for %s, got %s", offset, uf, got)
+		}
+	}
+}
+
+func TestWithEmissionDate(t *testing.T) {
+	idRe := regexp.MustCompile(`Id="(?:NFe|CFe)([0-9]{44})"`)
+	ufRe := regexp.MustCompile(`<enderEmit>(?s:.*?)<UF>([A-Z]{2})</UF>`)
+	dateRe := regexp.MustCompile(`<(dhEmi|dhSaiEnt|dhRecbto)>([^<]+)</`)
+	moment := time.Date(2025, 2, 1, 0, 30, 0, 0, brasilia) // still January in the states west of Brasília
+	for _, tt := range []TemplateType{NFe, NFCe, NFeDevolucao, NFeComplementar, NFeAjuste} {
+		t.Run(tt.String(), func(t *testing.T) {
+			generator, err := NewTemplateGenerator(tt)
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			for i := 0; i < 10; i++ {
+				xmlBytes, err := generator.Generate(WithEmissionDate(moment))
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				document := string(xmlBytes)
+				dates := map[string]time.Time{}
+				for _, match := range dateRe.FindAllStringSubmatch(document, -1) {
+					date, err := time.Parse(dateTimeLayout, match[2])
+					if err != nil {
+						t.Fatalf("Expected a date with its offset in %s, got %s", match[1], match[2])
+					}
+					dates[match[1]] = date
+				}
+				dhEmi := dates["dhEmi"]
+				if !dhEmi.Equal(moment) {
+					t.Errorf("Expected the emission at %v, got %v", moment, dhEmi)
+				}
+				uf := ufRe.FindStringSubmatch(document)[1]
+				if offset := moment.In(ufLocation(uf)).Format("-07:00"); dhEmi.Format("-07:00") != offset {
+					t.Errorf("Expected the offset of %s, got %s", uf, dhEmi.Format("-07:00"))
+				}
+				if key := idRe.FindStringSubmatch(document)[1]; key[2:6] != dhEmi.Format("0601") {
+					t.Errorf("Expected the AAMM of dhEmi in the access key, got %s", key[2:6])
+				}
+				if dhSaiEnt, ok := dates["dhSaiEnt"]; ok && dhSaiEnt.Before(dhEmi) {
+					t.Errorf("Expected dhSaiEnt after dhEmi, got %v", dhSaiEnt)
+				}
+				if dhRecbto, ok := dates["dhRecbto"]; ok && (!dhRecbto.After(dhEmi) || dhRecbto.Sub(dhEmi) > 90*time.Second) {
+					t.Errorf("Expected dhRecbto shortly after dhEmi, got %v", dhRecbto)
+				}
+				if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+					t.Fatalf("Expected no schema violations, got %v", errs)
+				}
+			}
+		})
+	}
+}
+
+func TestWithEmissionDateRange(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, brasilia)
+	to := from.AddDate(0, 1, 0).Add(-time.Second)
+	for i := 0; i < 50; i++ {
+		doc, _ := newMockDocument(NFe, &generationConfig{emissionDate: true, emissionFrom: from, emissionTo: to})
+		if doc.dhEmi.Before(from) || doc.dhEmi.After(to) {
+			t.Errorf("Expected the emission in March, got %v", doc.dhEmi)
+		}
+		if doc.dhEmi.Location().String() != ufLocation(doc.emit.city.uf).String() {
+			t.Errorf("Expected the time zone of %s, got %v", doc.emit.city.uf, doc.dhEmi.Location())
+		}
+	}
+
+	referenced, err := NewNFCeGenerator().Generate(WithEmissionDate(from.AddDate(0, 0, 10)))
+	if err != nil {
+		t.Fatalf("Failed to generate the referenced invoice: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		xmlBytes, err := NewNFeDevolucaoGenerator().Generate(WithReferencedInvoice(referenced), WithEmissionDateRange(from, to))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		dhEmi, _ := time.Parse(dateTimeLayout, regexp.MustCompile(`<dhEmi>([^<]+)</dhEmi>`).FindStringSubmatch(string(xmlBytes))[1])
+		if !dhEmi.After(from.AddDate(0, 0, 10)) || dhEmi.After(to) {
+			t.Errorf("Expected the return after the sale and within the range, got %v", dhEmi)
+		}
+	}
+}
+
+func TestWithEmissionDate_Errors(t *testing.T) {
+	moment := time.Date(2025, 3, 10, 12, 0, 0, 0, brasilia)
+	referenced, err := NewNFeGenerator().Generate(WithEmissionDate(moment))
+	if err != nil {
+		t.Fatalf("Failed to generate the referenced invoice: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		generator TemplateGenerator
+		options   []Option
+	}{
+		{"zero date", NewNFeGenerator(), []Option{WithEmissionDate(time.Time{})}},
+		{"inverted range", NewNFeGenerator(), []Option{WithEmissionDateRange(moment, moment.Add(-time.Hour))}},
+		{"before the referenced invoice", NewNFeDevolucaoGenerator(), []Option{WithReferencedInvoice(referenced), WithEmissionDate(moment.Add(-time.Hour))}},
+		{"at the referenced invoice", NewNFeComplementarGenerator(), []Option{WithReferencedInvoice(referenced), WithEmissionDate(moment)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.generator.Generate(tt.options...); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package nfs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+	"github.com/mayckol/brfiscalfaker/pkg/br_locale"
+)
+
+// EmitterProfile is the tax regime of the emitter, informed in CRT, which sets the ICMS groups of
+// the items: CSOSN in the Simples Nacional and for MEIs, CST otherwise.
+type EmitterProfile int
+
+const (
+	// EmitterDefault keeps the emitter of the template: a company of the Simples Nacional or of the
+	// Regime Normal, following the chosen situations.
+	EmitterDefault EmitterProfile = iota
+	// EmitterSimplesNacional is a company of the Simples Nacional (CRT 1).
+	EmitterSimplesNacional
+	// EmitterSimplesSublimite is a company of the Simples Nacional whose revenue exceeds the sublimit
+	// of the state (CRT 2), which pays the ICMS as the Regime Normal.
+	EmitterSimplesSublimite
+	// EmitterRegimeNormal is a company of the Regime Normal, Lucro Real or Presumido (CRT 3).
+	EmitterRegimeNormal
+	// EmitterMEI is a microempreendedor individual (CRT 4).
+	EmitterMEI
+	// EmitterProdutorRural is a rural producer who emits NF-e as a person, identified by the CPF and
+	// the IE of the producer (CRT 3).
+	EmitterProdutorRural
+)
+
+// emitterSpec describes an EmitterProfile: its name, CRT, whether its items take CSOSN groups and
+// whether the emitter is a person.
+type emitterSpec struct {
+	name    string
+	CRT     string
+	simples bool
+	person  bool
+}
+
+// emitterSpecs holds the spec of each EmitterProfile.
+var emitterSpecs = map[EmitterProfile]emitterSpec{
+	EmitterSimplesNacional:  {name: "EmitterSimplesNacional", CRT: "1", simples: true},
+	EmitterSimplesSublimite: {name: "EmitterSimplesSublimite", CRT: "2"},
+	EmitterRegimeNormal:     {name: "EmitterRegimeNormal", CRT: "3"},
+	EmitterMEI:              {name: "EmitterMEI", CRT: "4", simples: true},
+	EmitterProdutorRural:    {name: "EmitterProdutorRural", CRT: "3", person: true},
+}
+
+// String returns the name of the EmitterProfile.
+func (p EmitterProfile) String() string {
+	if p == EmitterDefault {
+		return "EmitterDefault"
+	}
+	if spec, ok := emitterSpecs[p]; ok {
+		return spec.name
+	}
+	return "Unknown"
+}
+
+// ParseEmitterProfile converts a string (e.g. "EmitterMEI" or "MEI") to an EmitterProfile.
+func ParseEmitterProfile(s string) (EmitterProfile, error) {
+	name := "Emitter" + strings.TrimPrefix(s, "Emitter")
+	if name == "EmitterDefault" {
+		return EmitterDefault, nil
+	}
+	for profile, spec := range emitterSpecs {
+		if spec.name == name {
+			return profile, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid EmitterProfile: %s", s)
+}
+
+// checkEmitterProfile fails when the emitter of the profile cannot issue a document of the
+// TemplateType with the chosen situations. Rural producers issue NF-e documents only and the
+// federal taxes of the Simples Nacional and MEIs are not charged at the regular PIS rates.
+func checkEmitterProfile(tt TemplateType, cfg *generationConfig) error {
+	if cfg.emitter == EmitterDefault {
+		return nil
+	}
+	spec, ok := emitterSpecs[cfg.emitter]
+	if !ok {
+		return fmt.Errorf("unknown emitter profile: %d", cfg.emitter)
+	}
+	if tt == CFe || (spec.person && tt == NFCe) {
+		return fmt.Errorf("%v is not supported for %v documents", cfg.emitter, tt)
+	}
+	if cfg.referencedInvoice != nil {
+		return fmt.Errorf("%v is not supported with a referenced invoice, whose emitter is kept", cfg.emitter)
+	}
+	if spec.person && (cfg.CNPJ != "" || cfg.operation == OperationTransferencia || cfg.fault == FaultInvalidCNPJ) {
+		return fmt.Errorf("%v is identified by a CPF and has no CNPJ", cfg.emitter)
+	}
+	for _, situation := range cfg.icms {
+		if situationSpec, ok := icmsSpecs[situation]; ok && situationSpec.simples != spec.simples {
+			return fmt.Errorf("%v is not supported for %v, whose CRT is %s", situation, cfg.emitter, spec.CRT)
+		}
+	}
+	if spec.CRT != "3" && regularPIS(cfg.pis) {
+		return fmt.Errorf("regular PIS situations are not supported for %v, whose CRT is %s", cfg.emitter, spec.CRT)
+	}
+	return nil
+}
+
+// applyEmitterProfile identifies the emitter of the profile: the rural producer is a person with
+// the IE of the producer, whose CPF takes the place of the CNPJ in the access key.
+func (doc *mockDocument) applyEmitterProfile(profile EmitterProfile) {
+	if emitterSpecs[profile].person {
+		doc.emit.CNPJ = ""
+		doc.emit.CPF = br_documents.CPF()
+		doc.emitName = br_locale.PersonName()
+	}
+}
+
+// simples reports whether the items of the document take the CSOSN groups of the Simples Nacional.
+func (doc *mockDocument) simples() bool {
+	return doc.CRT == "1" || doc.CRT == "4"
+}
@@ -0,0 +1,98 @@
+package nfs
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+func TestParseEmitterProfile(t *testing.T) {
+	for profile := EmitterDefault; profile <= EmitterProdutorRural; profile++ {
+		parsed, err := ParseEmitterProfile(profile.String())
+		if err != nil || parsed != profile {
+			t.Errorf("Expected %v, got %v (%v)", profile, parsed, err)
+		}
+	}
+	if parsed, err := ParseEmitterProfile("MEI"); err != nil || parsed != EmitterMEI {
+		t.Errorf("Expected EmitterMEI, got %v (%v)", parsed, err)
+	}
+	if _, err := ParseEmitterProfile("LucroReal"); err == nil {
+		t.Errorf("Expected an error for an unknown profile")
+	}
+}
+
+func TestWithEmitterProfile(t *testing.T) {
+	icmsRe := regexp.MustCompile(`<ICMS(SN)?[0-9]+>\s*<orig>[0-9]</orig>\s*<(CST|CSOSN)>`)
+	for profile := EmitterSimplesNacional; profile <= EmitterProdutorRural; profile++ {
+		spec := emitterSpecs[profile]
+		for _, tt := range []TemplateType{NFe, NFCe, NFeDevolucao} {
+			if spec.person && tt == NFCe {
+				continue
+			}
+			t.Run(profile.String()+"/"+tt.String(), func(t *testing.T) {
+				generator, err := NewTemplateGenerator(tt)
+				if err != nil {
+					t.Fatalf("Failed to create generator: %v", err)
+				}
+				for i := 0; i < 10; i++ {
+					xmlBytes, err := generator.Generate(WithEmitterProfile(profile), WithICMS(ICMSRandom, ICMSRandom))
+					if err != nil {
+						t.Fatalf("Expected no error, got %v", err)
+					}
+					document := string(xmlBytes)
+					if !strings.Contains(document, "<CRT>"+spec.CRT+"</CRT>") {
+						t.Errorf("Expected CRT %s in the document", spec.CRT)
+					}
+					for _, match := range icmsRe.FindAllStringSubmatch(document, -1) {
+						if (match[2] == "CSOSN") != spec.simples {
+							t.Errorf("Expected the ICMS groups of CRT %s, got %s", spec.CRT, match[2])
+						}
+					}
+					if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+						t.Fatalf("Expected no schema violations, got %v", errs)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestWithEmitterProfile_ProdutorRural(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		doc, _ := newMockDocument(NFe, &generationConfig{emitter: EmitterProdutorRural})
+		if doc.emit.CNPJ != "" || !br_documents.ValidateCPF(doc.emit.CPF) || !br_documents.ValidateIE(doc.emit.city.uf, doc.emit.IE) {
+			t.Fatalf("Expected a person with a CPF and the IE of the producer, got %+v", doc.emit)
+		}
+		if segment := doc.accessKey[6:20]; segment != "000"+doc.emit.CPF {
+			t.Errorf("Expected the CPF left-padded in the access key, got %s for %s", segment, doc.emit.CPF)
+		}
+		if !br_documents.ValidateAccessKey(doc.accessKey) {
+			t.Errorf("Expected a valid access key, got %s", doc.accessKey)
+		}
+	}
+}
+
+func TestWithEmitterProfile_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator TemplateGenerator
+		options   []Option
+	}{
+		{"CFe", NewCFeGenerator(), []Option{WithEmitterProfile(EmitterMEI)}},
+		{"NFC-e of a rural producer", NewNFCeGenerator(), []Option{WithEmitterProfile(EmitterProdutorRural)}},
+		{"CSOSN in the Regime Normal", NewNFeGenerator(), []Option{WithEmitterProfile(EmitterRegimeNormal), WithICMS(CSOSN102)}},
+		{"CST for a MEI", NewNFeGenerator(), []Option{WithEmitterProfile(EmitterMEI), WithICMS(CST00)}},
+		{"regular PIS in the Simples", NewNFeGenerator(), []Option{WithEmitterProfile(EmitterSimplesSublimite), WithPIS(PISAliq)}},
+		{"transfer of a person", NewNFeGenerator(), []Option{WithEmitterProfile(EmitterProdutorRural), WithOperation(OperationTransferencia)}},
+		{"unknown profile", NewNFeGenerator(), []Option{WithEmitterProfile(EmitterProfile(99))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.generator.Generate(tt.options...); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
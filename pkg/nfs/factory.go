@@ -21,6 +21,10 @@ func NewTemplateGenerator(templateType TemplateType) (TemplateGenerator, error)
 		return NewNFCeGenerator(), nil
 	case NFeDevolucao:
 		return NewNFeDevolucaoGenerator(), nil
+	case NFeComplementar:
+		return NewNFeComplementarGenerator(), nil
+	case NFeAjuste:
+		return NewNFeAjusteGenerator(), nil
 	default:
 		return nil, fmt.Errorf("unsupported template type: %v", templateType)
 	}
@@ -0,0 +1,267 @@
+package nfs
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+	"github.com/mayckol/brfiscalfaker/utils"
+)
+
+// Fault is a deliberate corruption applied to an otherwise valid NF-e or NFC-e, for negative tests.
+type Fault int
+
+const (
+	// FaultWrongAccessKeyDV makes the access key check digit invalid (cStat 253).
+	FaultWrongAccessKeyDV Fault = iota + 1
+	// FaultAccessKeyMismatch builds the access key from a cNF other than the one in ide (cStat 502).
+	FaultAccessKeyMismatch
+	// FaultTotalMismatch makes vNF differ from the sum of the values that compose it (cStat 610).
+	FaultTotalMismatch
+	// FaultItemValueMismatch makes vUnCom differ from vProd divided by qCom (cStat 629).
+	FaultItemValueMismatch
+	// FaultInvalidCNPJ gives the emitter a CNPJ with wrong check digits (cStat 207).
+	FaultInvalidCNPJ
+	// FaultInvalidIE gives the emitter an IE with wrong check digits (cStat 209).
+	FaultInvalidIE
+	// FaultMissingRequiredTag removes the required natOp tag (cStat 225).
+	FaultMissingRequiredTag
+	// FaultBadDateFormat writes dhEmi without the UTC offset (cStat 225).
+	FaultBadDateFormat
+	// FaultWrongModel swaps the model in ide (55 and 65), which no longer matches the access key (cStat 502).
+	FaultWrongModel
+	// FaultEmissionTooOld dates the emission more than 30 days before its reception (cStat 228).
+	FaultEmissionTooOld
+	// FaultHomologationRecipient issues the document in homologation with a real recipient name (cStat 598).
+	FaultHomologationRecipient
+	// FaultCFOPMismatch uses an entry CFOP on an exit, or an exit CFOP on an entry (cStat 518 or 519).
+	FaultCFOPMismatch
+)
+
+// FaultReport describes the element a Fault corrupts and the rejection it triggers.
+type FaultReport struct {
+	Fault   Fault
+	CStat   string // SEFAZ rejection code
+	Message string // SEFAZ rejection message (xMotivo)
+	Element string // name of the corrupted element
+}
+
+// faultSpec describes how a Fault is applied. model corrupts the values before the template
+// is filled, output corrupts the generated XML and fails when the element is not there.
+type faultSpec struct {
+	name    string
+	cStat   string
+	message string
+	element string
+	model   func(doc *mockDocument)
+	output  func(document string, doc *mockDocument) (string, bool)
+}
+
+// faultSpecs holds the spec of each Fault.
+var faultSpecs = map[Fault]faultSpec{
+	FaultWrongAccessKeyDV: {
+		name:    "FaultWrongAccessKeyDV",
+		cStat:   "253",
+		message: "Rejeição: Digito Verificador da chave de acesso composta inválida",
+		element: "cDV",
+		model: func(doc *mockDocument) {
+			dv := (int(doc.accessKey[43]-'0') + gofakeit.Number(1, 9)) % 10
+			doc.accessKey = doc.accessKey[:43] + strconv.Itoa(dv)
+		},
+	},
+	FaultAccessKeyMismatch: {
+		name:    "FaultAccessKeyMismatch",
+		cStat:   "502",
+		message: "Rejeição: Erro na Chave de Acesso - Campo Id não corresponde à concatenação dos campos correspondentes",
+		element: "Id",
+		model: func(doc *mockDocument) {
+			cNF := doc.cNF
+			for doc.cNF == cNF {
+				doc.cNF = fmt.Sprintf("%08d", gofakeit.Number(10000000, 99999999))
+			}
+			doc.buildAccessKey()
+			doc.cNF = cNF
+		},
+	},
+	FaultTotalMismatch: {
+		name:    "FaultTotalMismatch",
+		cStat:   "610",
+		message: "Rejeição: Total da NF difere do somatório dos Valores compõe o valor Total da NF.",
+		element: "vNF",
+		model: func(doc *mockDocument) {
+			doc.vNF += cents(gofakeit.Number(1, 100))
+			doc.splitPayments() // the payments still cover the informed total
+		},
+	},
+	FaultItemValueMismatch: {
+		name:    "FaultItemValueMismatch",
+		cStat:   "629",
+		message: "Rejeição: Valor do Produto difere do produto Valor Unitário de Comercialização e Quantidade Comercial",
+		element: "vUnCom",
+		output: func(document string, doc *mockDocument) (string, bool) {
+			corrupted := utils.ReplaceElement(document, "vUnCom", (doc.items[0].unitValue * 2).String())
+			return corrupted, corrupted != document
+		},
+	},
+	FaultInvalidCNPJ: {
+		name:    "FaultInvalidCNPJ",
+		cStat:   "207",
+		message: "Rejeição: CNPJ do emitente inválido",
+		element: "CNPJ",
+		model: func(doc *mockDocument) {
+			last := (int(doc.emit.CNPJ[13]-'0') + gofakeit.Number(1, 9)) % 10
+			doc.emit.CNPJ = doc.emit.CNPJ[:13] + strconv.Itoa(last)
+			doc.buildAccessKey()
+		},
+	},
+	FaultInvalidIE: {
+		name:    "FaultInvalidIE",
+		cStat:   "209",
+		message: "Rejeição: IE do emitente inválida",
+		element: "IE",
+		model: func(doc *mockDocument) {
+			ie := doc.emit.IE
+			for br_documents.ValidateIE(doc.emit.city.uf, doc.emit.IE) {
+				last := (int(ie[len(ie)-1]-'0') + gofakeit.Number(1, 9)) % 10
+				doc.emit.IE = ie[:len(ie)-1] + strconv.Itoa(last)
+			}
+		},
+	},
+	FaultMissingRequiredTag: {
+		name:    "FaultMissingRequiredTag",
+		cStat:   "225",
+		message: "Rejeição: Falha no Schema XML da NFe",
+		element: "natOp",
+		output: func(document string, doc *mockDocument) (string, bool) {
+			tagRe := regexp.MustCompile(`\s*<natOp>[^<]*</natOp>`)
+			return tagRe.ReplaceAllString(document, ""), tagRe.MatchString(document)
+		},
+	},
+	FaultBadDateFormat: {
+		name:    "FaultBadDateFormat",
+		cStat:   "225",
+		message: "Rejeição: Falha no Schema XML da NFe",
+		element: "dhEmi",
+		output: func(document string, doc *mockDocument) (string, bool) {
+			corrupted := utils.ReplaceElement(document, "dhEmi", doc.dhEmi.Format("2006-01-02 15:04:05"))
+			return corrupted, corrupted != document
+		},
+	},
+	FaultWrongModel: {
+		name:    "FaultWrongModel",
+		cStat:   "502",
+		message: "Rejeição: Erro na Chave de Acesso - Campo Id não corresponde à concatenação dos campos correspondentes",
+		element: "mod",
+		output: func(document string, doc *mockDocument) (string, bool) {
+			value := "65"
+			if doc.model == "65" {
+				value = "55"
+			}
+			corrupted := utils.ReplaceElement(document, "mod", value)
+			return corrupted, corrupted != document
+		},
+	},
+	FaultEmissionTooOld: {
+		name:    "FaultEmissionTooOld",
+		cStat:   "228",
+		message: "Rejeição: Data de Emissão muito atrasada",
+		element: "dhEmi",
+		model: func(doc *mockDocument) {
+			delay := time.Duration(gofakeit.Number(31, 90)) * 24 * time.Hour
+			reference := doc.dhRecbto
+			if doc.templateType == NFe {
+				reference = time.Now().In(doc.dhEmi.Location()).Truncate(time.Second) // not yet received
+			}
+			doc.dhEmi = reference.Add(-delay)
+			doc.dhSaiEnt = doc.dhEmi
+			doc.buildAccessKey()
+		},
+	},
+	FaultHomologationRecipient: {
+		name:    "FaultHomologationRecipient",
+		cStat:   "598",
+		message: "Rejeição: NF-e emitida em ambiente de homologação com Razão Social do destinatário diferente de NF-E EMITIDA EM AMBIENTE DE HOMOLOGACAO - SEM VALOR FISCAL",
+		element: "xNome",
+		model: func(doc *mockDocument) {
+			doc.tpAmb = "2"
+			doc.destName = xNome()
+		},
+	},
+	FaultCFOPMismatch: {
+		name:    "FaultCFOPMismatch",
+		cStat:   "518",
+		message: "Rejeição: CFOP de entrada para NF-e de saída",
+		element: "CFOP",
+		model: func(doc *mockDocument) {
+			// 5/6 (exits) become 1/2 (entries) and the other way round, in every item.
+			for i := range doc.items {
+				item := &doc.items[i]
+				first := int(item.CFOP[0] - '0')
+				if first >= 5 {
+					first -= 4
+				} else {
+					first += 4
+				}
+				item.CFOP = strconv.Itoa(first) + item.CFOP[1:]
+			}
+		},
+	},
+}
+
+// String returns the name of the Fault.
+func (f Fault) String() string {
+	if spec, ok := faultSpecs[f]; ok {
+		return spec.name
+	}
+	return "Unknown"
+}
+
+// ParseFault converts a string (e.g. "FaultTotalMismatch") to a Fault.
+func ParseFault(s string) (Fault, error) {
+	for fault, spec := range faultSpecs {
+		if spec.name == s {
+			return fault, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid Fault: %s", s)
+}
+
+// CStat returns the SEFAZ rejection code the Fault triggers on an exit document.
+func (f Fault) CStat() string {
+	return faultSpecs[f].cStat
+}
+
+// Report returns the element the Fault corrupted in the generated document and the rejection the
+// document triggers. FaultCFOPMismatch triggers cStat 518 on an exit and 519 on an entry (tpNF 0).
+func (f Fault) Report(document []byte) FaultReport {
+	spec := faultSpecs[f]
+	report := FaultReport{Fault: f, CStat: spec.cStat, Message: spec.message, Element: spec.element}
+	if f == FaultCFOPMismatch && bytes.Contains(document, []byte("<tpNF>0</tpNF>")) {
+		report.CStat, report.Message = "519", "Rejeição: CFOP de saída para NF-e de entrada"
+	}
+	return report
+}
+
+// applyModel corrupts the document values, before the template is filled.
+func (f Fault) applyModel(doc *mockDocument) {
+	if spec := faultSpecs[f]; spec.model != nil {
+		spec.model(doc)
+	}
+}
+
+// applyOutput corrupts the generated document. It fails when the element to corrupt is not in the template.
+func (f Fault) applyOutput(document string, doc *mockDocument) (string, error) {
+	spec := faultSpecs[f]
+	if spec.output == nil {
+		return document, nil
+	}
+	corrupted, ok := spec.output(document, doc)
+	if !ok {
+		return "", fmt.Errorf("fault %v: template has no <%s> element", f, spec.element)
+	}
+	return corrupted, nil
+}
@@ -0,0 +1,152 @@
+package nfs
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+func TestWithFault_Faults(t *testing.T) {
+	const documentsPerFault = 20
+
+	keyRe := regexp.MustCompile(`Id="NFe([0-9]{44})"`)
+	emitRe := regexp.MustCompile(`(?s)<emit>\s*<CNPJ>([0-9]+)</CNPJ>.*?<UF>([A-Z]{2})</UF>.*?<IE>([0-9]+)</IE>`)
+	element := func(document, name string) string {
+		match := regexp.MustCompile("<" + name + ">([^<]*)</" + name + ">").FindStringSubmatch(document)
+		if match == nil {
+			return ""
+		}
+		return match[1]
+	}
+	amount := func(document, name string) float64 {
+		value, _ := strconv.ParseFloat(element(document, name), 64)
+		return value
+	}
+
+	tests := []struct {
+		fault     Fault
+		corrupted func(document string) bool
+	}{
+		{FaultWrongAccessKeyDV, func(document string) bool {
+			return !br_documents.ValidateAccessKey(keyRe.FindStringSubmatch(document)[1])
+		}},
+		{FaultAccessKeyMismatch, func(document string) bool {
+			key := keyRe.FindStringSubmatch(document)[1]
+			return br_documents.ValidateAccessKey(key) && key[35:43] != element(document, "cNF")
+		}},
+		{FaultTotalMismatch, func(document string) bool {
+			totals := document[strings.Index(document, "<ICMSTot>"):]
+			vNF := amount(totals, "vProd") - amount(totals, "vDesc") - amount(totals, "vICMSDeson") + amount(totals, "vST") +
+				amount(totals, "vFCPST") + amount(totals, "vFrete") + amount(totals, "vSeg") + amount(totals, "vOutro") +
+				amount(totals, "vII") + amount(totals, "vIPI") + amount(totals, "vIPIDevol")
+			if services := strings.Index(document, "<ISSQNtot>"); services >= 0 {
+				vNF += amount(document[services:], "vServ")
+			}
+			return math.Abs(vNF-amount(totals, "vNF")) >= 0.005
+		}},
+		{FaultItemValueMismatch, func(document string) bool {
+			return math.Abs(amount(document, "qCom")*amount(document, "vUnCom")-amount(document, "vProd")) > 0.01
+		}},
+		{FaultInvalidCNPJ, func(document string) bool {
+			return !br_documents.ValidateCNPJ(emitRe.FindStringSubmatch(document)[1])
+		}},
+		{FaultInvalidIE, func(document string) bool {
+			emit := emitRe.FindStringSubmatch(document)
+			return !br_documents.ValidateIE(emit[2], emit[3])
+		}},
+		{FaultMissingRequiredTag, func(document string) bool {
+			return !strings.Contains(document, "<natOp>")
+		}},
+		{FaultBadDateFormat, func(document string) bool {
+			_, err := time.Parse(dateTimeLayout, element(document, "dhEmi"))
+			return err != nil
+		}},
+		{FaultWrongModel, func(document string) bool {
+			return element(document, "mod") != keyRe.FindStringSubmatch(document)[1][20:22]
+		}},
+		{FaultEmissionTooOld, func(document string) bool {
+			dhEmi, _ := time.Parse(dateTimeLayout, element(document, "dhEmi"))
+			reference := time.Now()
+			if dhRecbto, err := time.Parse(dateTimeLayout, element(document, "dhRecbto")); err == nil {
+				reference = dhRecbto
+			}
+			return reference.Sub(dhEmi) > 30*24*time.Hour
+		}},
+		{FaultHomologationRecipient, func(document string) bool {
+			dest := document[strings.Index(document, "<dest>"):]
+			return element(document, "tpAmb") == "2" && element(dest, "xNome") != homologationRecipientName
+		}},
+		{FaultCFOPMismatch, func(document string) bool {
+			exit := element(document, "CFOP")[0] >= '5'
+			return exit != (element(document, "tpNF") == "1")
+		}},
+	}
+
+	for _, tc := range tests {
+		for _, tt := range []TemplateType{NFe, NFCe, NFeDevolucao} {
+			t.Run(tc.fault.String()+"/"+tt.String(), func(t *testing.T) {
+				generator, err := NewTemplateGenerator(tt)
+				if err != nil {
+					t.Fatalf("Failed to create generator: %v", err)
+				}
+				for i := 0; i < documentsPerFault; i++ {
+					xmlBytes, err := generator.Generate(WithFault(tc.fault))
+					if err != nil {
+						t.Fatalf("Expected no error, got %v", err)
+					}
+					if !tc.corrupted(string(xmlBytes)) {
+						t.Fatalf("Expected <%s> to be corrupted\n%s", tc.fault.Report(xmlBytes).Element, xmlBytes)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestFault_Report(t *testing.T) {
+	report := FaultTotalMismatch.Report(nil)
+	if report.Fault != FaultTotalMismatch || report.CStat != "610" || report.Element != "vNF" || report.Message == "" {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if report.CStat != FaultTotalMismatch.CStat() {
+		t.Errorf("Expected the cStat %s, got %s", FaultTotalMismatch.CStat(), report.CStat)
+	}
+	if report := FaultCFOPMismatch.Report([]byte("<tpNF>0</tpNF>")); report.CStat != "519" {
+		t.Errorf("Expected the cStat 519 on an entry, got %s", report.CStat)
+	}
+
+	for fault := FaultWrongAccessKeyDV; fault <= FaultCFOPMismatch; fault++ {
+		if report := fault.Report(nil); report.CStat == "" || report.Element == "" {
+			t.Errorf("Expected the rejection of %v, got %+v", fault, report)
+		}
+		if parsed, err := ParseFault(fault.String()); err != nil || parsed != fault {
+			t.Errorf("Expected to parse %v, got %v (%v)", fault, parsed, err)
+		}
+	}
+}
+
+func TestWithFault_SchemaFaults(t *testing.T) {
+	for _, fault := range []Fault{FaultMissingRequiredTag, FaultBadDateFormat} {
+		t.Run(fault.String(), func(t *testing.T) {
+			xmlBytes, err := NewNFCeGenerator().Generate(WithFault(fault))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if errs := Validate(NFCe, xmlBytes); len(errs) == 0 {
+				t.Errorf("Expected schema violations for %v", fault)
+			}
+		})
+	}
+}
+
+func TestWithFault_UnsupportedTemplate(t *testing.T) {
+	if _, err := NewCFeGenerator().Generate(WithFault(FaultInvalidCNPJ)); err == nil {
+		t.Errorf("Expected an error for a CFe document")
+	}
+}
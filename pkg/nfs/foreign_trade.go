@@ -0,0 +1,198 @@
+package nfs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+// customsPlace is a port or airport where goods are cleared by the customs.
+type customsPlace struct {
+	uf   string
+	name string
+}
+
+// customsPlaces lists the main places of clearance of the foreign trade.
+var customsPlaces = []customsPlace{
+	{"SP", "Porto de Santos"},
+	{"PR", "Porto de Paranagua"},
+	{"RS", "Porto de Rio Grande"},
+	{"SC", "Porto de Itajai"},
+	{"RJ", "Porto do Rio de Janeiro"},
+	{"ES", "Porto de Vitoria"},
+	{"AM", "Porto de Manaus"},
+	{"SP", "Aeroporto Internacional de Guarulhos"},
+	{"SP", "Aeroporto Internacional de Viracopos"},
+}
+
+// iiRates are usual rates of the Imposto de Importação (TEC), in hundredths of percent.
+var iiRates = []int{0, 200, 1000, 1200, 1400, 1600, 1800, 2000}
+
+// importDeclaration is the import declaration (DI) of the goods bought abroad, shared by the items,
+// each of them an addition (adi) of it.
+type importDeclaration struct {
+	nDI          string
+	dDI          time.Time
+	place        customsPlace
+	dDesemb      time.Time
+	tpViaTransp  string
+	tpIntermedio string
+	CNPJ         string // the buyer, when imported on its behalf (tpIntermedio 2 or 3)
+	UFTerceiro   string
+	cExportador  string
+}
+
+// exportDeclaration is the place where the exported goods leave the country (exporta).
+type exportDeclaration struct {
+	place        customsPlace
+	xLocDespacho string
+}
+
+// applyForeignTrade sets the import declaration of an import or the place of shipment of an export.
+// The goods are cleared some days before the document is issued.
+func (doc *mockDocument) applyForeignTrade() {
+	switch doc.operation {
+	case OperationImportacao:
+		registered := doc.dhEmi.AddDate(0, 0, -gofakeit.Number(3, 30))
+		di := &importDeclaration{
+			nDI:          registered.Format("06") + gofakeit.Numerify("########"),
+			dDI:          registered,
+			place:        customsPlaces[gofakeit.Number(0, len(customsPlaces)-1)],
+			dDesemb:      registered.AddDate(0, 0, gofakeit.Number(1, 3)),
+			tpViaTransp:  gofakeit.RandomString([]string{"1", "1", "1", "4", "7"}), // maritime, air or road
+			tpIntermedio: gofakeit.RandomString([]string{"1", "1", "2", "3"}),
+			cExportador:  doc.dest.idEstrangeiro,
+		}
+		if di.tpIntermedio != "1" {
+			// On behalf of (2) or by order of (3) another company, which buys the goods.
+			di.CNPJ = br_documents.CNPJ()
+			di.UFTerceiro = UF()
+		}
+		doc.importDecl = di
+	case OperationExportacao:
+		doc.export = &exportDeclaration{
+			place:        customsPlaces[gofakeit.Number(0, len(customsPlaces)-1)],
+			xLocDespacho: "Recinto alfandegado de " + doc.emit.city.name,
+		}
+	}
+}
+
+// calculateForeignTrade sets the addition of the DI and the II of an imported item, or the export
+// details of an exported item. The II is charged on the customs value, informed as the item value;
+// the AFRMM is due on sea freight only.
+func (i *mockItem) calculateForeignTrade(doc *mockDocument) {
+	if doc.importDecl != nil {
+		i.nAdicao, i.nSeqAdic = (i.number-1)/10+1, (i.number-1)%10+1
+		i.cFabricante = gofakeit.Numerify("FAB######")
+		if doc.importDecl.tpViaTransp == "1" {
+			i.vAFRMM = i.vProd.applyRate(gofakeit.Number(50, 250))
+		}
+		i.vBCII = i.net()
+		i.pII = iiRates[gofakeit.Number(0, len(iiRates)-1)]
+		i.vDespAdu = i.vBCII.applyRate(gofakeit.Number(50, 300))
+		i.vII = i.vBCII.applyRate(i.pII)
+		return
+	}
+	if doc.export != nil {
+		if gofakeit.Bool() {
+			i.nDraw = gofakeit.Numerify("20#########") // drawback concession act
+		}
+		if doc.CFOP[1:] == "501" {
+			// Goods received with the specific purpose of export: the remittance of the producer.
+			ufCode, _ := br_documents.UFCode(UF())
+			i.nRE = gofakeit.Numerify("############")
+			i.chNFeExport = br_documents.AccessKey(br_documents.AccessKeyConfig{
+				CNPJ:         br_documents.CNPJ(),
+				UF:           ufCode,
+				Date:         doc.dhEmi.AddDate(0, 0, -gofakeit.Number(5, 60)),
+				Model:        "55",
+				Series:       gofakeit.Number(1, 999),
+				Number:       gofakeit.Number(1, 999999999),
+				EmissionType: "1",
+			})
+		}
+	}
+}
+
+// diGroupXML renders the import declaration of the item with its addition, which is empty outside of imports.
+func (doc *mockDocument) diGroupXML() string {
+	di, item := doc.importDecl, doc.item
+	if di == nil {
+		return ""
+	}
+	x := &xmlBuilder{}
+	x.open("DI")
+	x.element("nDI", di.nDI)
+	x.element("dDI", di.dDI.Format("2006-01-02"))
+	x.element("xLocDesemb", di.place.name)
+	x.element("UFDesemb", di.place.uf)
+	x.element("dDesemb", di.dDesemb.Format("2006-01-02"))
+	x.element("tpViaTransp", di.tpViaTransp)
+	if di.tpViaTransp == "1" {
+		x.element("vAFRMM", item.vAFRMM.String())
+	}
+	x.element("tpIntermedio", di.tpIntermedio)
+	if di.CNPJ != "" {
+		x.element("CNPJ", di.CNPJ)
+		x.element("UFTerceiro", di.UFTerceiro)
+	}
+	x.element("cExportador", di.cExportador)
+	x.open("adi")
+	x.element("nAdicao", fmt.Sprint(item.nAdicao))
+	x.element("nSeqAdic", fmt.Sprint(item.nSeqAdic))
+	x.element("cFabricante", item.cFabricante)
+	x.close("adi")
+	x.close("DI")
+	return x.String()
+}
+
+// detExportGroupXML renders the export details of the item: the drawback act and the indirect export
+// when the goods were received for export. It is empty outside of exports and when there are none.
+func (doc *mockDocument) detExportGroupXML() string {
+	item := doc.item
+	if doc.export == nil || (item.nDraw == "" && item.nRE == "") {
+		return ""
+	}
+	x := &xmlBuilder{}
+	x.open("detExport")
+	if item.nDraw != "" {
+		x.element("nDraw", item.nDraw)
+	}
+	if item.nRE != "" {
+		x.open("exportInd")
+		x.element("nRE", item.nRE)
+		x.element("chNFe", item.chNFeExport)
+		x.element("qExport", formatQuantity(item.quantity))
+		x.close("exportInd")
+	}
+	x.close("detExport")
+	return x.String()
+}
+
+// iiGroupXML renders the import tax group of the item, which is empty outside of imports.
+func (doc *mockDocument) iiGroupXML() string {
+	if doc.importDecl == nil {
+		return ""
+	}
+	i := doc.item
+	return xmlGroup("II", []xmlElement{
+		{"vBC", i.vBCII.String()},
+		{"vDespAdu", i.vDespAdu.String()},
+		{"vII", i.vII.String()},
+		{"vIOF", "0.00"},
+	})
+}
+
+// exportaGroupXML renders the place of shipment of an export, which is empty for the other operations.
+func (doc *mockDocument) exportaGroupXML() string {
+	if doc.export == nil {
+		return ""
+	}
+	return xmlGroup("exporta", []xmlElement{
+		{"UFSaidaPais", doc.export.place.uf},
+		{"xLocExporta", doc.export.place.name},
+		{"xLocDespacho", doc.export.xLocDespacho},
+	})
+}
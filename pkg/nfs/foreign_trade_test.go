@@ -0,0 +1,64 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestForeignTrade_Import(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		doc, _ := newMockDocument(NFe, &generationConfig{operation: OperationImportacao})
+		di := doc.importDecl
+		if di == nil {
+			t.Fatalf("Expected the DI of the import")
+		}
+		if !di.dDI.Before(doc.dhEmi) || di.dDesemb.Before(di.dDI) {
+			t.Errorf("Expected the DI of %v cleared on %v, before the emission on %v", di.dDI, di.dDesemb, doc.dhEmi)
+		}
+		if di.cExportador != doc.dest.idEstrangeiro || (di.tpIntermedio == "1") != (di.CNPJ == "") {
+			t.Errorf("Unexpected DI parties: %+v", di)
+		}
+
+		var vII cents
+		for _, item := range doc.items {
+			if item.vBCII != item.net() || item.vII != item.vBCII.applyRate(item.pII) {
+				t.Errorf("Expected the II on the customs value %v, got %v", item.net(), item.vII)
+			}
+			if (di.tpViaTransp == "1") != (item.vAFRMM > 0) {
+				t.Errorf("Expected the AFRMM on sea freight only, got %v by %s", item.vAFRMM, di.tpViaTransp)
+			}
+			vII += item.vII
+		}
+		if doc.total.vII != vII || doc.vNF != doc.total.net()+doc.total.vII+doc.total.vIPI+doc.total.vICMSST+doc.total.vFCPST-doc.total.vICMSDeson {
+			t.Errorf("Expected vII %v in vNF %v", vII, doc.vNF)
+		}
+	}
+}
+
+func TestForeignTrade_Export(t *testing.T) {
+	indirect := false
+	for i := 0; i < 30; i++ {
+		xmlBytes, err := NewNFeGenerator().Generate(WithOperation(OperationExportacao), WithICMS(ICMSRandom, ICMSRandom))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		document := string(xmlBytes)
+		if !strings.Contains(document, "<UFSaidaPais>") || !strings.Contains(document, "<xLocExporta>") {
+			t.Errorf("Expected the place of shipment")
+		}
+		if strings.Contains(document, "<CFOP>7501</CFOP>") {
+			indirect = true
+			if strings.Count(document, "<exportInd>") != 2 {
+				t.Errorf("Expected the indirect export of each item")
+			}
+		} else if strings.Contains(document, "<exportInd>") {
+			t.Errorf("Expected no indirect export outside of CFOP 7501")
+		}
+		if errs := Validate(NFe, xmlBytes); len(errs) > 0 {
+			t.Fatalf("Expected no schema violations, got %v", errs)
+		}
+	}
+	if !indirect {
+		t.Errorf("Expected some indirect exports")
+	}
+}
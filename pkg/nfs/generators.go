@@ -65,3 +65,37 @@ func NewNFeDevolucaoGenerator() *NFeDevolucaoGenerator {
 func (g *NFeDevolucaoGenerator) Generate(options ...Option) ([]byte, error) {
 	return ReplaceTemplate(g.template, options...)
 }
+
+// NFeComplementarGenerator generates a complementary NFe (finNFe 2) XML.
+type NFeComplementarGenerator struct {
+	template string
+}
+
+// NewNFeComplementarGenerator creates a new instance of NFeComplementarGenerator with the NFeComplementar XML template.
+func NewNFeComplementarGenerator() *NFeComplementarGenerator {
+	return &NFeComplementarGenerator{
+		template: NFeComplementarXMLMock,
+	}
+}
+
+// Generate replaces placeholders in the NFeComplementar template, respecting blocked placeholders.
+func (g *NFeComplementarGenerator) Generate(options ...Option) ([]byte, error) {
+	return ReplaceTemplate(g.template, options...)
+}
+
+// NFeAjusteGenerator generates an adjustment NFe (finNFe 3) XML.
+type NFeAjusteGenerator struct {
+	template string
+}
+
+// NewNFeAjusteGenerator creates a new instance of NFeAjusteGenerator with the NFeAjuste XML template.
+func NewNFeAjusteGenerator() *NFeAjusteGenerator {
+	return &NFeAjusteGenerator{
+		template: NFeAjusteXMLMock,
+	}
+}
+
+// Generate replaces placeholders in the NFeAjuste template, respecting blocked placeholders.
+func (g *NFeAjusteGenerator) Generate(options ...Option) ([]byte, error) {
+	return ReplaceTemplate(g.template, options...)
+}
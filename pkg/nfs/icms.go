@@ -0,0 +1,433 @@
+package nfs
+
+import (
+	"fmt"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// ICMSSituation is the tax situation of the ICMS of an item: a CST for the regular regime
+// (CRT 3) or a CSOSN for the Simples Nacional (CRT 1).
+type ICMSSituation int
+
+const (
+	// ICMSRandom picks a situation of the emitter regime for the item.
+	ICMSRandom ICMSSituation = iota
+	// CST00 is tributada integralmente.
+	CST00
+	// CST10 is tributada e com cobrança do ICMS por substituição tributária.
+	CST10
+	// CST20 is com redução de base de cálculo.
+	CST20
+	// CST30 is isenta ou não tributada e com cobrança do ICMS por substituição tributária.
+	CST30
+	// CST40 is isenta.
+	CST40
+	// CST41 is não tributada.
+	CST41
+	// CST50 is suspensão.
+	CST50
+	// CST51 is diferimento.
+	CST51
+	// CST60 is ICMS cobrado anteriormente por substituição tributária.
+	CST60
+	// CST70 is com redução de base de cálculo e cobrança do ICMS por substituição tributária.
+	CST70
+	// CST90 is outras.
+	CST90
+	// CSOSN101 is tributada pelo Simples Nacional com permissão de crédito.
+	CSOSN101
+	// CSOSN102 is tributada pelo Simples Nacional sem permissão de crédito.
+	CSOSN102
+	// CSOSN103 is isenção do ICMS no Simples Nacional para faixa de receita bruta.
+	CSOSN103
+	// CSOSN201 is tributada com permissão de crédito e com cobrança do ICMS por substituição tributária.
+	CSOSN201
+	// CSOSN202 is tributada sem permissão de crédito e com cobrança do ICMS por substituição tributária.
+	CSOSN202
+	// CSOSN203 is isenção para faixa de receita bruta e com cobrança do ICMS por substituição tributária.
+	CSOSN203
+	// CSOSN300 is imune.
+	CSOSN300
+	// CSOSN400 is não tributada pelo Simples Nacional.
+	CSOSN400
+	// CSOSN500 is ICMS cobrado anteriormente por substituição tributária ou por antecipação.
+	CSOSN500
+	// CSOSN900 is outros.
+	CSOSN900
+)
+
+// icmsSpec describes an ICMSSituation: its code, the group that carries it, whether the emitter
+// retains ICMS-ST (st) or the ST was retained before (stRetained), whether the ICMS is relieved,
+// whether the own operation may carry FCP and whether sales to non-contributors of other states
+// owe the destination state the rate difference (DIFAL).
+type icmsSpec struct {
+	name       string
+	code       string
+	group      string
+	simples    bool
+	st         bool
+	stRetained bool
+	deson      bool
+	fcp        bool
+	difal      bool
+}
+
+// icmsSpecs holds the spec of each ICMSSituation.
+var icmsSpecs = map[ICMSSituation]icmsSpec{
+	CST00:    {name: "CST00", code: "00", group: "ICMS00", fcp: true, difal: true},
+	CST10:    {name: "CST10", code: "10", group: "ICMS10", st: true, fcp: true},
+	CST20:    {name: "CST20", code: "20", group: "ICMS20", deson: true, fcp: true, difal: true},
+	CST30:    {name: "CST30", code: "30", group: "ICMS30", st: true},
+	CST40:    {name: "CST40", code: "40", group: "ICMS40", deson: true},
+	CST41:    {name: "CST41", code: "41", group: "ICMS40"},
+	CST50:    {name: "CST50", code: "50", group: "ICMS40"},
+	CST51:    {name: "CST51", code: "51", group: "ICMS51", fcp: true},
+	CST60:    {name: "CST60", code: "60", group: "ICMS60", stRetained: true},
+	CST70:    {name: "CST70", code: "70", group: "ICMS70", st: true, fcp: true},
+	CST90:    {name: "CST90", code: "90", group: "ICMS90", fcp: true, difal: true},
+	CSOSN101: {name: "CSOSN101", code: "101", group: "ICMSSN101", simples: true, difal: true},
+	CSOSN102: {name: "CSOSN102", code: "102", group: "ICMSSN102", simples: true, difal: true},
+	CSOSN103: {name: "CSOSN103", code: "103", group: "ICMSSN102", simples: true},
+	CSOSN201: {name: "CSOSN201", code: "201", group: "ICMSSN201", simples: true, st: true},
+	CSOSN202: {name: "CSOSN202", code: "202", group: "ICMSSN202", simples: true, st: true},
+	CSOSN203: {name: "CSOSN203", code: "203", group: "ICMSSN202", simples: true, st: true},
+	CSOSN300: {name: "CSOSN300", code: "300", group: "ICMSSN102", simples: true},
+	CSOSN400: {name: "CSOSN400", code: "400", group: "ICMSSN102", simples: true},
+	CSOSN500: {name: "CSOSN500", code: "500", group: "ICMSSN500", simples: true, stRetained: true},
+	CSOSN900: {name: "CSOSN900", code: "900", group: "ICMSSN900", simples: true, difal: true},
+}
+
+// String returns the name of the ICMSSituation.
+func (s ICMSSituation) String() string {
+	if s == ICMSRandom {
+		return "ICMSRandom"
+	}
+	if spec, ok := icmsSpecs[s]; ok {
+		return spec.name
+	}
+	return "Unknown"
+}
+
+// ParseICMSSituation converts a string (e.g. "CST10" or "CSOSN500") to an ICMSSituation.
+func ParseICMSSituation(s string) (ICMSSituation, error) {
+	if s == "ICMSRandom" {
+		return ICMSRandom, nil
+	}
+	for situation, spec := range icmsSpecs {
+		if spec.name == s {
+			return situation, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid ICMSSituation: %s", s)
+}
+
+// checkICMSSituations fails when the situations cannot be issued together in a document of the TemplateType.
+func checkICMSSituations(tt TemplateType, situations []ICMSSituation) error {
+	if len(situations) == 0 {
+		return nil
+	}
+	if tt == CFe {
+		return fmt.Errorf("ICMS situations are not supported for CFe documents")
+	}
+	if len(situations) > 990 {
+		return fmt.Errorf("too many items: %d (the maximum is 990)", len(situations))
+	}
+	regular, simples := false, false
+	for _, situation := range situations {
+		spec, ok := icmsSpecs[situation]
+		if !ok && situation != ICMSRandom {
+			return fmt.Errorf("unknown ICMS situation: %d", situation)
+		}
+		if ok && spec.simples {
+			simples = true
+		} else if ok {
+			regular = true
+		}
+	}
+	if regular && simples {
+		return fmt.Errorf("ICMS situations mix CST and CSOSN, which belong to different tax regimes")
+	}
+	return nil
+}
+
+// simplesNacional reports whether the situations are issued under the Simples Nacional (CRT 1).
+// ok is false when none of them tells the regime.
+func simplesNacional(situations []ICMSSituation) (simples bool, ok bool) {
+	for _, situation := range situations {
+		if spec, found := icmsSpecs[situation]; found {
+			return spec.simples, true
+		}
+	}
+	return false, false
+}
+
+// randomICMSSituation picks a situation of the regime. NFC-e items do not retain ICMS-ST
+// nor defer the ICMS, as the recipient is the final consumer.
+func randomICMSSituation(simples bool, tt TemplateType) ICMSSituation {
+	var candidates []ICMSSituation
+	for situation := CST00; situation <= CSOSN900; situation++ {
+		spec := icmsSpecs[situation]
+		if spec.simples != simples || (tt == NFCe && (spec.st || situation == CST51)) {
+			continue
+		}
+		candidates = append(candidates, situation)
+	}
+	return candidates[gofakeit.Number(0, len(candidates)-1)]
+}
+
+// itemCFOP returns the CFOP of an item of the operation, which changes when the goods are subject to ICMS-ST:
+// x401/x403 when the emitter retains it, x404/x405 when it was retained before, x409 on transfers and
+// x411 on returns. The other operations and foreign trade keep their CFOP.
+func itemCFOP(operationCFOP string, situation ICMSSituation) string {
+	spec := icmsSpecs[situation]
+	if !spec.st && !spec.stRetained {
+		return operationCFOP
+	}
+	prefix, suffix := operationCFOP[:1], operationCFOP[1:]
+	switch {
+	case prefix == "3" || prefix == "7":
+		return operationCFOP
+	case suffix == "202":
+		return prefix + "411"
+	case suffix == "152":
+		return prefix + "409"
+	case suffix != "101" && suffix != "102":
+		return operationCFOP
+	case spec.stRetained && prefix == "6":
+		return prefix + "404"
+	case spec.stRetained:
+		return prefix + "405"
+	case suffix == "101":
+		return prefix + "401"
+	default:
+		return prefix + "403"
+	}
+}
+
+// calculateICMS sets the ICMS values of the item for its situation in the document. The ST base adds
+// the IPI and the margin (MVA) to the item value, and the ST is the destination internal rate on that
+// base minus the ICMS of the emitter's own operation. FCP is charged by the state that gets the ICMS:
+// the emitter state on its own operations and the destination state on ST and DIFAL.
+func (i *mockItem) calculateICMS(doc *mockDocument) {
+	spec := icmsSpecs[i.icms]
+	emitUF, destUF := doc.emit.city.uf, doc.dest.city.uf
+	if destUF == exterior.uf {
+		destUF = emitUF // imports pay the internal rate of the state of the importer
+	}
+	rate := icmsRate(emitUF, destUF, i.orig)
+	net := i.net()
+
+	switch i.icms {
+	case CST00, CST10, CST70, CST90, CSOSN900:
+		i.pICMS, i.vBC = rate, net
+	case CST20:
+		i.pICMS, i.pRedBC = rate, []int{3333, 4167, 5000, 6111}[gofakeit.Number(0, 3)]
+		i.vBC = net - net.applyRate(i.pRedBC)
+	case CST51:
+		i.pICMS, i.vBC, i.pDif = rate, net, []int{3333, 4167, 5000}[gofakeit.Number(0, 2)]
+	case CSOSN101, CSOSN201:
+		i.pCredSN = gofakeit.Number(125, 395)
+		i.vCredICMSSN = net.applyRate(i.pCredSN)
+	}
+	if i.icms == CST70 {
+		i.pRedBC = []int{3333, 4167, 5000}[gofakeit.Number(0, 2)]
+		i.vBC = net - net.applyRate(i.pRedBC)
+	}
+	i.vICMS = i.vBC.applyRate(i.pICMS)
+
+	if i.icms == CST51 {
+		i.vICMSOp = i.vICMS
+		i.vICMSDif = i.vICMSOp.applyRate(i.pDif)
+		i.vICMS = i.vICMSOp - i.vICMSDif
+	}
+	if spec.fcp && doc.idDest == "1" && fcpRates[emitUF] > 0 && gofakeit.Number(1, 3) == 1 {
+		i.pFCP, i.vBCFCP = fcpRates[emitUF], i.vBC
+		i.vFCP = i.vBCFCP.applyRate(i.pFCP)
+	}
+	if spec.deson {
+		// The relieved ICMS is deducted from the total of the document.
+		i.vICMSDeson = net.applyRate(rate) - i.vICMS
+		i.motDesICMS = "9" // 9 = outros
+	}
+
+	if spec.st {
+		i.pMVAST = gofakeit.Number(30, 70) * 100
+		i.pICMSST = icmsRate(destUF, destUF, "0")
+		i.vBCST = (net + i.vIPI).applyRate(10000 + i.pMVAST)
+		own := i.vICMS
+		if own == 0 {
+			own = net.applyRate(rate) // exempt or Simples Nacional: the ICMS the operation would have
+		}
+		i.vICMSST = i.vBCST.applyRate(i.pICMSST) - own
+		if i.vICMSST < 0 {
+			i.vICMSST = 0
+		}
+		if fcpRates[destUF] > 0 && gofakeit.Bool() {
+			i.pFCPST, i.vBCFCPST = fcpRates[destUF], i.vBCST
+			i.vFCPST = i.vBCFCPST.applyRate(i.pFCPST)
+		}
+	}
+	if spec.stRetained {
+		// Retained by the substitute taxpayer when the goods entered the state.
+		i.pST = icmsRate(emitUF, emitUF, "0")
+		i.vBCSTRet = net.applyRate(10000 + gofakeit.Number(30, 70)*100)
+		i.vICMSSubstituto = net.applyRate(gofakeit.Number(7000, 9000)).applyRate(i.pST)
+		i.vICMSSTRet = i.vBCSTRet.applyRate(i.pST) - i.vICMSSubstituto
+	}
+
+	if spec.difal && doc.model == "55" && doc.tpNF == "1" && doc.idDest == "2" && doc.indFinal == "1" && doc.indIEDest == "9" {
+		i.calculateDIFAL(emitUF, destUF)
+	}
+}
+
+// calculateDIFAL sets the ICMS owed to the destination state on a sale to a non-contributor of
+// another state (EC 87/2015, LC 190/2022): the destination internal rate on the DIFAL base minus
+// the interstate ICMS, all of it to the destination state since 2019, plus its FCP.
+func (i *mockItem) calculateDIFAL(emitUF, destUF string) {
+	operation := i.net() + i.vIPI
+	i.pICMSInter = icmsRate(emitUF, destUF, i.orig)
+	i.pICMSUFDest = internalICMSRates[destUF]
+	i.pFCPUFDest = fcpRates[destUF]
+	interstateICMS := operation.applyRate(i.pICMSInter)
+
+	i.vBCUFDest = operation
+	if difalDoubleBase[destUF] {
+		// The interstate ICMS is taken out and the destination ICMS and FCP are put in.
+		divisor := cents(10000 - i.pICMSUFDest - i.pFCPUFDest)
+		i.vBCUFDest = ((operation-interstateICMS)*10000 + divisor/2) / divisor
+	}
+	i.vFCPUFDest = i.vBCUFDest.applyRate(i.pFCPUFDest)
+	i.vICMSUFDest = i.vBCUFDest.applyRate(i.pICMSUFDest) - interstateICMS
+	if i.vICMSUFDest < 0 {
+		i.vICMSUFDest = 0
+	}
+}
+
+// icmsGroupXML renders the ICMS element of the item with the group of its situation, the elements
+// in the layout order. It is empty for services, which are taxed by the ISSQN.
+func (i *mockItem) icmsGroupXML() string {
+	if i.service != nil {
+		return ""
+	}
+	spec := icmsSpecs[i.icms]
+	codeName := "CST"
+	if spec.simples {
+		codeName = "CSOSN"
+	}
+	elements := []xmlElement{{"orig", i.orig}, {codeName, spec.code}}
+	ownICMS := []xmlElement{
+		{"modBC", "3"}, // 3 = valor da operação
+		{"vBC", i.vBC.String()},
+		{"pICMS", formatRate(i.pICMS, 2)},
+		{"vICMS", i.vICMS.String()},
+	}
+	st := []xmlElement{
+		{"modBCST", "4"}, // 4 = margem de valor agregado
+		{"pMVAST", formatRate(i.pMVAST, 2)},
+		{"vBCST", i.vBCST.String()},
+		{"pICMSST", formatRate(i.pICMSST, 2)},
+		{"vICMSST", i.vICMSST.String()},
+	}
+	deson := []xmlElement{
+		{"vICMSDeson", i.vICMSDeson.String()},
+		{"motDesICMS", i.motDesICMS},
+		{"indDeduzDeson", "1"}, // 1 = deduz o ICMS desonerado do valor do item
+	}
+
+	switch i.icms {
+	case CST00, CST10, CST90, CSOSN900:
+		elements = append(elements, ownICMS...)
+	case CST20, CST70:
+		elements = append(elements, ownICMS[0], xmlElement{"pRedBC", formatRate(i.pRedBC, 2)})
+		elements = append(elements, ownICMS[1:]...)
+	case CST51:
+		elements = append(elements, ownICMS[:3]...)
+		elements = append(elements,
+			xmlElement{"vICMSOp", i.vICMSOp.String()},
+			xmlElement{"pDif", formatRate(i.pDif, 2)},
+			xmlElement{"vICMSDif", i.vICMSDif.String()},
+			xmlElement{"vICMS", i.vICMS.String()},
+		)
+	case CST60, CSOSN500:
+		elements = append(elements,
+			xmlElement{"vBCSTRet", i.vBCSTRet.String()},
+			xmlElement{"pST", formatRate(i.pST, 2)},
+			xmlElement{"vICMSSubstituto", i.vICMSSubstituto.String()},
+			xmlElement{"vICMSSTRet", i.vICMSSTRet.String()},
+		)
+	}
+	if i.pFCP > 0 {
+		if i.icms != CST00 {
+			elements = append(elements, xmlElement{"vBCFCP", i.vBCFCP.String()})
+		}
+		elements = append(elements,
+			xmlElement{"pFCP", formatRate(i.pFCP, 2)},
+			xmlElement{"vFCP", i.vFCP.String()},
+		)
+	}
+	if spec.st {
+		elements = append(elements, st...)
+	}
+	if i.pFCPST > 0 {
+		elements = append(elements,
+			xmlElement{"vBCFCPST", i.vBCFCPST.String()},
+			xmlElement{"pFCPST", formatRate(i.pFCPST, 2)},
+			xmlElement{"vFCPST", i.vFCPST.String()},
+		)
+	}
+	if i.pCredSN > 0 {
+		elements = append(elements,
+			xmlElement{"pCredSN", formatRate(i.pCredSN, 2)},
+			xmlElement{"vCredICMSSN", i.vCredICMSSN.String()},
+		)
+	}
+	if spec.deson {
+		elements = append(elements, deson...)
+	}
+
+	x := &xmlBuilder{}
+	x.open("ICMS")
+	x.open(spec.group)
+	x.elements(elements)
+	x.close(spec.group)
+	x.close("ICMS")
+	return x.String()
+}
+
+// cfeICMSGroupXML renders the ICMS element of a CF-e item, which is empty for services.
+func (i *mockItem) cfeICMSGroupXML() string {
+	if i.service != nil {
+		return ""
+	}
+	x := &xmlBuilder{}
+	x.open("ICMS")
+	x.open("ICMS00")
+	x.elements([]xmlElement{
+		{"Orig", i.orig},
+		{"CST", "00"},
+		{"pICMS", formatRate(i.pICMS, 2)},
+		{"vICMS", i.vICMS.String()},
+	})
+	x.close("ICMS00")
+	x.close("ICMS")
+	return x.String()
+}
+
+// icmsUFDestXML renders the ICMSUFDest group of the item, or nothing when the item owes no DIFAL.
+func (i *mockItem) icmsUFDestXML() string {
+	if i.pICMSInter == 0 {
+		return ""
+	}
+	return xmlGroup("ICMSUFDest", []xmlElement{
+		{"vBCUFDest", i.vBCUFDest.String()},
+		{"vBCFCPUFDest", i.vBCUFDest.String()},
+		{"pFCPUFDest", formatRate(i.pFCPUFDest, 2)},
+		{"pICMSUFDest", formatRate(i.pICMSUFDest, 2)},
+		{"pICMSInter", formatRate(i.pICMSInter, 2)},
+		{"pICMSInterPart", "100.00"}, // all of the DIFAL goes to the destination state since 2019
+		{"vFCPUFDest", i.vFCPUFDest.String()},
+		{"vICMSUFDest", i.vICMSUFDest.String()},
+		{"vICMSUFRemet", "0.00"},
+	})
+}
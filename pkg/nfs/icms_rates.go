@@ -0,0 +1,46 @@
+package nfs
+
+// internalICMSRates holds the general internal ICMS rate of each state, in hundredths of percent.
+var internalICMSRates = map[string]int{
+	"AC": 1900, "AL": 1900, "AM": 2000, "AP": 1800, "BA": 2050, "CE": 2000, "DF": 2000,
+	"ES": 1700, "GO": 1900, "MA": 2300, "MG": 1800, "MS": 1700, "MT": 1700, "PA": 1900,
+	"PB": 2000, "PE": 2050, "PI": 2250, "PR": 1950, "RJ": 2000, "RN": 2000, "RO": 1950,
+	"RR": 2000, "RS": 1700, "SC": 1700, "SE": 2000, "SP": 1800, "TO": 2000,
+}
+
+// southSoutheast lists the states of the South and Southeast regions, except Espírito Santo.
+var southSoutheast = map[string]bool{"MG": true, "PR": true, "RJ": true, "RS": true, "SC": true, "SP": true}
+
+// icmsRate returns the ICMS rate of an operation from origin to destination, in hundredths of percent:
+// the internal rate inside a state, 4% for imported goods (orig 1, 2, 3 or 8) and 7% or 12% otherwise
+// (Resolução do Senado 22/1989 and 13/2012).
+func icmsRate(origin, destination, orig string) int {
+	if origin == destination {
+		return internalICMSRates[origin]
+	}
+	switch orig {
+	case "1", "2", "3", "8":
+		return 400
+	}
+	if southSoutheast[origin] && !southSoutheast[destination] {
+		return 700
+	}
+	return 1200
+}
+
+// fcpRates holds the rate of the Fundo de Combate à Pobreza (ADCT, art. 82) charged by each state
+// on top of the internal rate, in hundredths of percent. The states missing from the map charge it
+// on specific goods only.
+var fcpRates = map[string]int{
+	"AL": 100, "AM": 200, "BA": 200, "CE": 200, "DF": 200, "ES": 200, "GO": 200, "MA": 200,
+	"MG": 200, "MS": 200, "MT": 200, "PB": 200, "PE": 200, "PI": 100, "PR": 200, "RJ": 200,
+	"RN": 200, "RO": 200, "SE": 100, "TO": 200,
+}
+
+// difalDoubleBase lists the states whose DIFAL base is the operation value without the interstate
+// ICMS, grossed up by the internal rate (base dupla, LC 190/2022); the others tax the operation
+// value itself (base única).
+var difalDoubleBase = map[string]bool{
+	"AL": true, "BA": true, "GO": true, "MA": true, "MG": true, "PA": true, "PB": true, "PE": true,
+	"PI": true, "PR": true, "RS": true, "SC": true, "SE": true, "SP": true, "TO": true,
+}
@@ -0,0 +1,100 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithICMS_Items(t *testing.T) {
+	xmlBytes, err := NewNFeGenerator().Generate(WithICMS(CST00, CST10, CST60))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	document := string(xmlBytes)
+
+	for i, group := range []string{"<ICMS00>", "<ICMS10>", "<ICMS60>"} {
+		if !strings.Contains(document, group) {
+			t.Errorf("Expected item %d to have %s", i+1, group)
+		}
+	}
+	if count := strings.Count(document, "<det nItem="); count != 3 {
+		t.Errorf("Expected 3 items, got %d", count)
+	}
+	if !strings.Contains(document, `<det nItem="3">`) {
+		t.Errorf("Expected the items to be numbered")
+	}
+	if errs := Validate(NFe, xmlBytes); len(errs) > 0 {
+		t.Errorf("Expected no schema violations, got %v", errs)
+	}
+}
+
+func TestWithICMS_Regime(t *testing.T) {
+	tests := []struct {
+		situation ICMSSituation
+		crt       string
+	}{
+		{CSOSN500, "<CRT>1</CRT>"},
+		{CST90, "<CRT>3</CRT>"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.situation.String(), func(t *testing.T) {
+			xmlBytes, err := NewNFCeGenerator().Generate(WithICMS(tc.situation))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !strings.Contains(string(xmlBytes), tc.crt) {
+				t.Errorf("Expected %s for %v", tc.crt, tc.situation)
+			}
+		})
+	}
+}
+
+func TestWithICMS_Errors(t *testing.T) {
+	if _, err := NewNFeGenerator().Generate(WithICMS(CST00, CSOSN102)); err == nil {
+		t.Errorf("Expected an error when mixing CST and CSOSN")
+	}
+	if _, err := NewCFeGenerator().Generate(WithICMS(CST00)); err == nil {
+		t.Errorf("Expected an error for a CFe document")
+	}
+}
+
+func TestParseICMSSituation(t *testing.T) {
+	for _, name := range []string{"ICMSRandom", "CST51", "CSOSN203"} {
+		situation, err := ParseICMSSituation(name)
+		if err != nil || situation.String() != name {
+			t.Errorf("Expected %s, got %v (%v)", name, situation, err)
+		}
+	}
+	if _, err := ParseICMSSituation("CST99"); err == nil {
+		t.Errorf("Expected an error for an unknown situation")
+	}
+}
+
+func TestCalculateDIFAL(t *testing.T) {
+	tests := []struct {
+		name           string
+		emitUF, destUF string
+		vProd          cents
+		vBCUFDest      cents
+		vICMSUFDest    cents
+		vFCPUFDest     cents
+	}{
+		// (4252.00 - 12%) / (1 - 17%) = 4508.14; 17% of it less the 510.24 of interstate ICMS
+		{"double base", "MA", "RS", 425200, 450814, 25614, 0},
+		// 20% less 12% of 1000.00, plus 2% of FCP
+		{"single base", "SP", "RJ", 100000, 100000, 8000, 2000},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			item := mockItem{vProd: tc.vProd, orig: "0"}
+			item.calculateDIFAL(tc.emitUF, tc.destUF)
+
+			if item.vBCUFDest != tc.vBCUFDest || item.vICMSUFDest != tc.vICMSUFDest || item.vFCPUFDest != tc.vFCPUFDest {
+				t.Errorf("Expected base %v, DIFAL %v and FCP %v, got %v, %v and %v",
+					tc.vBCUFDest, tc.vICMSUFDest, tc.vFCPUFDest, item.vBCUFDest, item.vICMSUFDest, item.vFCPUFDest)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package nfs
+
+import (
+	"fmt"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+// marketplace is an intermediary platform of sales (marketplace or delivery app).
+type marketplace struct {
+	name string
+	CNPJ string
+}
+
+// marketplaces are the main marketplaces of Brazil.
+var marketplaces = []marketplace{
+	{"Mercado Livre", "03007331000141"},
+	{"Amazon", "15436940000103"},
+	{"Magazine Luiza", "47960950000121"},
+	{"Shopee", "35635824000112"},
+	{"Americanas", "00776574000660"},
+	{"Casas Bahia", "33041260065290"},
+}
+
+// deliveryApps are the delivery apps of the NFC-e sales delivered at home.
+var deliveryApps = []marketplace{
+	{"iFood", "14380200000121"},
+}
+
+// mockIntermediary is the intermediary of a sale (infIntermed): the CNPJ of the platform and the
+// identifier of the seller registered on it.
+type mockIntermediary struct {
+	CNPJ         string
+	idCadIntTran string
+}
+
+// checkMarketplace fails when the marketplace cannot intermediate a document of the TemplateType
+// with the operation: sales of NF-e and NFC-e within the country only.
+func checkMarketplace(tt TemplateType, cfg *generationConfig) error {
+	if !cfg.marketplace {
+		return nil
+	}
+	if tt != NFe && tt != NFCe {
+		return fmt.Errorf("marketplaces are not supported for %v documents", tt)
+	}
+	if spec, ok := operationSpecs[cfg.operation]; ok && (!spec.sale || spec.location == abroad) {
+		return fmt.Errorf("marketplaces are not supported for %v", cfg.operation)
+	}
+	if cfg.marketplaceCNPJ != "" && !br_documents.ValidateCNPJ(cfg.marketplaceCNPJ) {
+		return fmt.Errorf("invalid marketplace CNPJ: %s", cfg.marketplaceCNPJ)
+	}
+	if id := cfg.marketplaceID; id != "" && (len(id) < 2 || len(id) > 60) {
+		return fmt.Errorf("invalid marketplace seller identifier: %q (2 to 60 characters)", id)
+	}
+	return nil
+}
+
+// applyIntermediary sets the presence of the buyer and the intermediary of the sale. Some NF-e sales
+// are made on the internet (indPres 2), by the own store of the emitter or on a marketplace; with
+// WithMarketplace they always are, and NFC-e sales are delivered at home (indPres 4) by a delivery
+// app. The indicator of intermediary (indIntermed) is informed on sales without the presence of the
+// buyer only.
+func (doc *mockDocument) applyIntermediary(cfg *generationConfig) {
+	sale := doc.templateType == NFe && doc.finNFe == "1" && doc.idDest != "3"
+	if spec, ok := operationSpecs[doc.operation]; ok {
+		sale = sale && spec.sale
+	}
+	switch {
+	case cfg.marketplace && doc.templateType == NFCe:
+		doc.indPres = "4"
+		doc.intermediary = &mockIntermediary{CNPJ: deliveryApps[gofakeit.Number(0, len(deliveryApps)-1)].CNPJ}
+	case cfg.marketplace:
+		doc.indPres = "2"
+		doc.intermediary = &mockIntermediary{CNPJ: marketplaces[gofakeit.Number(0, len(marketplaces)-1)].CNPJ}
+	case sale && gofakeit.Number(1, 4) == 1:
+		doc.indPres = "2"
+		if gofakeit.Bool() {
+			doc.intermediary = &mockIntermediary{CNPJ: marketplaces[gofakeit.Number(0, len(marketplaces)-1)].CNPJ}
+		}
+	}
+	if i := doc.intermediary; i != nil {
+		i.idCadIntTran = gofakeit.RandomString([]string{"LOJA", "SELLER", "VENDEDOR"}) + gofakeit.Numerify("########")
+		if cfg.marketplaceCNPJ != "" {
+			i.CNPJ = cfg.marketplaceCNPJ
+		}
+		if cfg.marketplaceID != "" {
+			i.idCadIntTran = cfg.marketplaceID
+		}
+	}
+
+	switch {
+	case doc.intermediary != nil:
+		doc.indIntermed = "1" // operação em site ou plataforma de terceiros
+	case doc.indPres == "2" || doc.indPres == "3" || doc.indPres == "4" || doc.indPres == "9":
+		doc.indIntermed = "0" // operação sem intermediador, e.g. the own site of the emitter
+	}
+}
+
+// infIntermedGroupXML renders the intermediary of the sale, which is empty for sales without one.
+func (doc *mockDocument) infIntermedGroupXML() string {
+	if doc.intermediary == nil {
+		return ""
+	}
+	return xmlGroup("infIntermed", []xmlElement{
+		{"CNPJ", doc.intermediary.CNPJ},
+		{"idCadIntTran", doc.intermediary.idCadIntTran},
+	})
+}
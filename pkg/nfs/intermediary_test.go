@@ -0,0 +1,103 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+func TestMarketplaces(t *testing.T) {
+	for _, m := range append(marketplaces, deliveryApps...) {
+		if !br_documents.ValidateCNPJ(m.CNPJ) {
+			t.Errorf("Expected a valid CNPJ of %s, got %s", m.name, m.CNPJ)
+		}
+	}
+}
+
+func TestApplyIntermediary(t *testing.T) {
+	online := false
+	for i := 0; i < 200; i++ {
+		doc, _ := newMockDocument(NFe, &generationConfig{})
+		switch doc.indPres {
+		case "1":
+			if doc.indIntermed != "" || doc.intermediary != nil {
+				t.Errorf("Expected no intermediary on presential sales, got %s", doc.indIntermed)
+			}
+		case "2":
+			online = true
+			if (doc.indIntermed == "1") != (doc.intermediary != nil) || doc.indIntermed == "" {
+				t.Errorf("Expected the intermediary with indIntermed 1, got %s and %+v", doc.indIntermed, doc.intermediary)
+			}
+		default:
+			t.Errorf("Unexpected indPres %s", doc.indPres)
+		}
+	}
+	if !online {
+		t.Errorf("Expected some sales on the internet")
+	}
+
+	doc, _ := newMockDocument(NFeDevolucao, &generationConfig{})
+	if doc.indPres != "9" || doc.indIntermed != "0" {
+		t.Errorf("Expected indIntermed 0 on returns, got %s by indPres %s", doc.indIntermed, doc.indPres)
+	}
+}
+
+func TestWithMarketplace(t *testing.T) {
+	tests := []struct {
+		tt      TemplateType
+		cnpj    string
+		id      string
+		indPres string
+	}{
+		{NFe, "", "", "2"},
+		{NFe, "03007331000141", "LOJA123", "2"},
+		{NFCe, "", "", "4"},
+		{NFCe, "14380200000121", "RESTAURANTE42", "4"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.tt.String()+"/"+tc.cnpj, func(t *testing.T) {
+			generator, err := NewTemplateGenerator(tc.tt)
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			for i := 0; i < 10; i++ {
+				xmlBytes, err := generator.Generate(WithMarketplace(tc.cnpj, tc.id))
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				document := string(xmlBytes)
+				for _, element := range []string{"<indPres>" + tc.indPres + "</indPres>", "<indIntermed>1</indIntermed>", "<infIntermed>", "<CNPJ>" + tc.cnpj, "<idCadIntTran>" + tc.id} {
+					if !strings.Contains(document, element) {
+						t.Errorf("Expected %s in the document", element)
+					}
+				}
+				if errs := Validate(tc.tt, xmlBytes); len(errs) > 0 {
+					t.Fatalf("Expected no schema violations, got %v", errs)
+				}
+			}
+		})
+	}
+}
+
+func TestWithMarketplace_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator TemplateGenerator
+		options   []Option
+	}{
+		{"CFe", NewCFeGenerator(), []Option{WithMarketplace("", "")}},
+		{"return", NewNFeDevolucaoGenerator(), []Option{WithMarketplace("", "")}},
+		{"transfer", NewNFeGenerator(), []Option{WithMarketplace("", ""), WithOperation(OperationTransferencia)}},
+		{"export", NewNFeGenerator(), []Option{WithMarketplace("", ""), WithOperation(OperationExportacao)}},
+		{"invalid CNPJ", NewNFeGenerator(), []Option{WithMarketplace("11111111111111", "")}},
+		{"invalid seller", NewNFeGenerator(), []Option{WithMarketplace("", "X")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.generator.Generate(tt.options...); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package nfs
+
+import (
+	"fmt"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// IPISituation is the group of the IPI of an item.
+type IPISituation int
+
+const (
+	// IPIRandom picks IPITrib or IPINT.
+	IPIRandom IPISituation = iota
+	// IPITrib is tributado: CST 50 on exits and 00 on entries, at the TIPI rate of the product.
+	IPITrib
+	// IPINT is não tributado: alíquota zero, isento, não tributado, imune or suspenso (CST 51 to 55
+	// on exits and 01 to 05 on entries), with the matching legal framework (cEnq).
+	IPINT
+)
+
+// ipiSituationNames holds the name of each IPISituation.
+var ipiSituationNames = map[IPISituation]string{
+	IPITrib: "IPITrib",
+	IPINT:   "IPINT",
+}
+
+// ipiNTCodes are the last CST digit of the IPINT situations and their legal framework (cEnq):
+// 001 to 099 imunidade, 101 to 199 suspensão, 301 to 399 isenção and 999 the others.
+var ipiNTCodes = []struct{ cst, cEnq string }{
+	{cst: "1", cEnq: "999"}, // alíquota zero
+	{cst: "2", cEnq: "301"}, // isenta
+	{cst: "3", cEnq: "999"}, // não tributada
+	{cst: "4", cEnq: "001"}, // imune
+	{cst: "5", cEnq: "101"}, // suspensão
+}
+
+// ipiRates are the TIPI rates of the generated products, in hundredths of percent.
+var ipiRates = []int{500, 1000, 1500}
+
+// String returns the name of the IPISituation.
+func (s IPISituation) String() string {
+	if s == IPIRandom {
+		return "IPIRandom"
+	}
+	if name, ok := ipiSituationNames[s]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// ParseIPISituation converts a string (e.g. "IPITrib") to an IPISituation.
+func ParseIPISituation(s string) (IPISituation, error) {
+	if s == "IPIRandom" {
+		return IPIRandom, nil
+	}
+	for situation, name := range ipiSituationNames {
+		if name == s {
+			return situation, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid IPISituation: %s", s)
+}
+
+// checkIPISituations fails when the situations cannot be issued in a document of the TemplateType.
+// The NFC-e and the CF-e do not carry IPI, as they are issued by retailers.
+func checkIPISituations(tt TemplateType, situations []IPISituation) error {
+	if len(situations) == 0 {
+		return nil
+	}
+	if tt == CFe || tt == NFCe {
+		return fmt.Errorf("IPI situations are not supported for %v documents", tt)
+	}
+	if len(situations) > 990 {
+		return fmt.Errorf("too many items: %d (the maximum is 990)", len(situations))
+	}
+	for _, situation := range situations {
+		if _, ok := ipiSituationNames[situation]; !ok && situation != IPIRandom {
+			return fmt.Errorf("unknown IPI situation: %d", situation)
+		}
+	}
+	return nil
+}
+
+// calculateIPI sets the CST, legal framework and values of the IPI of the item for its situation.
+func (i *mockItem) calculateIPI(doc *mockDocument, situation IPISituation) {
+	if situation == IPIRandom {
+		situation = IPISituation(gofakeit.Number(int(IPITrib), int(IPINT)))
+	}
+	prefix := "5"
+	if doc.tpNF == "0" {
+		prefix = "0"
+	}
+	if situation == IPITrib {
+		i.ipiCST, i.cEnq = prefix+"0", "999"
+		i.pIPI = ipiRates[gofakeit.Number(0, len(ipiRates)-1)]
+		i.vIPI = i.net().applyRate(i.pIPI)
+		return
+	}
+	code := ipiNTCodes[gofakeit.Number(0, len(ipiNTCodes)-1)]
+	i.ipiCST, i.cEnq = prefix+code.cst, code.cEnq
+}
+
+// ipiGroupXML renders the IPI group of the item, which is empty when the item has no IPI.
+func (i *mockItem) ipiGroupXML() string {
+	if i.ipiCST == "" {
+		return ""
+	}
+	x := &xmlBuilder{}
+	x.open("IPI")
+	x.element("cEnq", i.cEnq)
+	if i.pIPI == 0 {
+		x.open("IPINT")
+		x.element("CST", i.ipiCST)
+		x.close("IPINT")
+	} else {
+		x.open("IPITrib")
+		x.element("CST", i.ipiCST)
+		x.element("vBC", i.net().String())
+		x.element("pIPI", formatRate(i.pIPI, 2))
+		x.element("vIPI", i.vIPI.String())
+		x.close("IPITrib")
+	}
+	x.close("IPI")
+	return x.String()
+}
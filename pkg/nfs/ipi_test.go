@@ -0,0 +1,48 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithIPI_Items(t *testing.T) {
+	xmlBytes, err := NewNFeGenerator().Generate(WithIPI(IPITrib, IPINT))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	document := string(xmlBytes)
+
+	if count := strings.Count(document, "<det nItem="); count != 2 {
+		t.Fatalf("Expected 2 items, got %d", count)
+	}
+	for _, group := range []string{"<IPITrib>", "<CST>50</CST>", "<IPINT>", "<cEnq>"} {
+		if !strings.Contains(document, group) {
+			t.Errorf("Expected %s", group)
+		}
+	}
+	if errs := Validate(NFe, xmlBytes); len(errs) > 0 {
+		t.Errorf("Expected no schema violations, got %v", errs)
+	}
+}
+
+func TestWithIPI_Entries(t *testing.T) {
+	xmlBytes, err := NewNFeDevolucaoGenerator().Generate(WithIPI(IPINT))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(string(xmlBytes), "<IPINT>\n              <CST>0") {
+		t.Errorf("Expected an entry IPINT CST (01 to 05)")
+	}
+	if errs := Validate(NFeDevolucao, xmlBytes); len(errs) > 0 {
+		t.Errorf("Expected no schema violations, got %v", errs)
+	}
+}
+
+func TestWithIPI_Errors(t *testing.T) {
+	if _, err := NewNFCeGenerator().Generate(WithIPI(IPITrib)); err == nil {
+		t.Errorf("Expected an error for an NFCe document")
+	}
+	if _, err := NewNFeGenerator().Generate(WithIPI(IPISituation(7))); err == nil {
+		t.Errorf("Expected an error for an unknown situation")
+	}
+}
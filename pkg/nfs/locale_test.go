@@ -0,0 +1,54 @@
+package nfs
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_locale"
+)
+
+func TestGenerate_Locale(t *testing.T) {
+	foneRe := regexp.MustCompile(`<UF>([A-Z]{2})</UF>\s*<CEP>[0-9]+</CEP>\s*<cPais>1058</cPais>\s*<xPais>BRASIL</xPais>\s*<fone>([0-9]+)</fone>`)
+	for _, tt := range []TemplateType{NFe, NFCe} {
+		generator, err := NewTemplateGenerator(tt)
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		for i := 0; i < 20; i++ {
+			xmlBytes, err := generator.Generate()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			document := string(xmlBytes)
+			phones := foneRe.FindAllStringSubmatch(document, -1)
+			if len(phones) == 0 {
+				t.Fatalf("Expected the phones of the parties\n%s", document)
+			}
+			for _, match := range phones {
+				if !br_locale.ValidatePhone(match[1], match[2]) {
+					t.Errorf("Expected a phone of %s, got %s", match[1], match[2])
+				}
+			}
+			if tt == NFCe && !strings.Contains(document, ".com.br</email>") {
+				t.Errorf("Expected a .com.br email of the recipient\n%s", document)
+			}
+			if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+				t.Fatalf("Expected no schema violations, got %v", errs)
+			}
+		}
+	}
+}
+
+func TestNewMockDocument_RecipientName(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		doc, _ := newMockDocument(NFCe, &generationConfig{})
+		if doc.tpAmb == "1" && br_locale.TradeName(doc.destName) != doc.destName {
+			t.Errorf("Expected the name of a person for a CPF recipient, got %s", doc.destName)
+		}
+		doc, _ = newMockDocument(NFe, &generationConfig{})
+		if br_locale.TradeName(doc.emitName) == doc.emitName {
+			t.Errorf("Expected a corporate name for the emitter, got %s", doc.emitName)
+		}
+	}
+}
@@ -0,0 +1,685 @@
+package nfs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+	"github.com/mayckol/brfiscalfaker/pkg/universe"
+)
+
+// homologationRecipientName is the recipient name required by SEFAZ in the homologation environment (tpAmb 2).
+const homologationRecipientName = "NF-E EMITIDA EM AMBIENTE DE HOMOLOGACAO - SEM VALOR FISCAL"
+
+// cents is a monetary value in hundredths of real.
+type cents int64
+
+// String formats the value with two decimals, as in the TDec_1302 fields.
+func (c cents) String() string {
+	sign := ""
+	if c < 0 {
+		sign, c = "-", -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/100, c%100)
+}
+
+// applyRate returns the value times a rate given in hundredths of percent, rounded half up.
+func (c cents) applyRate(rate int) cents {
+	return (c*cents(rate) + 5000) / 10000
+}
+
+// formatRate formats a rate given in hundredths of percent with the number of decimals.
+func formatRate(rate int, decimals int) string {
+	value := strconv.Itoa(rate/100) + "." + fmt.Sprintf("%02d", rate%100)
+	return value + strings.Repeat("0", decimals-2)
+}
+
+// mockParty holds the identification and location of one of the parties of the document.
+type mockParty struct {
+	CNPJ          string
+	CPF           string
+	IE            string
+	idEstrangeiro string // the document of a party abroad
+	city          municipality
+	cPais         string // the country of a party abroad
+	xPais         string
+	address       *universe.Address // the fixed address of a party of a universe
+}
+
+// mockItem holds the values of a document item. The document totals are held as an item too,
+// with the sum of the values of every item.
+type mockItem struct {
+	number    int
+	code      string // cProd, when it comes from a referenced invoice or a company of a universe
+	CFOP      string
+	quantity  int64 // qCom in ten-thousandths
+	unitValue cents
+	vProd     cents
+	vDesc     cents
+	orig      string
+	vTotTrib  cents
+	product   catalogProduct
+	gtin      string // empty for bulk products
+	pDevol    int    // the returned percentage of the referenced item, in hundredths of percent
+	sector    *sectorItem
+
+	// PIS and COFINS, by situation
+	pis             PISSituation
+	pisCST          string
+	pPIS            int
+	vPIS            cents
+	pCOFINS         int
+	vCOFINS         cents
+	vAliqProdPIS    int // rates per unit in ten-thousandths of real
+	vAliqProdCOFINS int
+	pPISST          int
+	vPISST          cents
+	pCOFINSST       int
+	vCOFINSST       cents
+
+	// IPI, when the item has it
+	ipiCST string
+	cEnq   string
+	pIPI   int
+	vIPI   cents
+
+	// ISSQN, for service items; the totals hold the services apart from the goods
+	service     *serviceListItem
+	cMunFG      string
+	pISS        int
+	vISSQN      cents
+	vServ       cents
+	vPISServ    cents
+	vCOFINSServ cents
+
+	// ICMS, by situation
+	icms            ICMSSituation
+	vBC             cents
+	pICMS           int
+	vICMS           cents
+	pRedBC          int
+	vICMSOp         cents
+	pDif            int
+	vICMSDif        cents
+	vICMSDeson      cents
+	motDesICMS      string
+	pMVAST          int
+	vBCST           cents
+	pICMSST         int
+	vICMSST         cents
+	vBCSTRet        cents
+	pST             int
+	vICMSSubstituto cents
+	vICMSSTRet      cents
+	pCredSN         int
+	vCredICMSSN     cents
+
+	// FCP and DIFAL (ICMSUFDest)
+	pFCP        int
+	vBCFCP      cents
+	vFCP        cents
+	pFCPST      int
+	vBCFCPST    cents
+	vFCPST      cents
+	vBCUFDest   cents
+	pFCPUFDest  int
+	pICMSUFDest int
+	pICMSInter  int
+	vFCPUFDest  cents
+	vICMSUFDest cents
+
+	// Foreign trade: the addition of the DI and the II of imports, the export details of exports
+	nAdicao     int
+	nSeqAdic    int
+	cFabricante string
+	vAFRMM      cents
+	vBCII       cents
+	pII         int
+	vDespAdu    cents
+	vII         cents
+	nDraw       string
+	nRE         string
+	chNFeExport string
+
+	// IBS, CBS and Imposto Seletivo, when the tax reform layout is chosen
+	reform *reformTaxes
+}
+
+// net returns the item value after the discount, the base of the taxes.
+func (i mockItem) net() cents {
+	return i.vProd - i.vDesc
+}
+
+// pisBase returns the PIS/COFINS base, which is zero when the item has no rate (e.g. Simples Nacional).
+func (i mockItem) pisBase() cents {
+	if i.pPIS == 0 {
+		return 0
+	}
+	return i.net()
+}
+
+// formatQuantity formats a quantity given in ten-thousandths with four decimals.
+func formatQuantity(quantity int64) string {
+	return fmt.Sprintf("%d.%04d", quantity/10000, quantity%10000)
+}
+
+// mockDocument holds the values shared by several placeholders, so that the generated document
+// is internally consistent: the access key matches the ide fields, the parties are located in
+// real municipalities with valid IEs, CFOPs agree with the operation and the totals add up.
+type mockDocument struct {
+	templateType TemplateType
+	model        string
+	tpAmb        string
+	tpEmis       string
+	serie        int
+	nNF          int
+	cNF          string
+	dhEmi        time.Time
+	dhSaiEnt     time.Time
+	dhRecbto     time.Time
+	contingency  Contingency
+	dhCont       time.Time // the start of the contingency
+	xJust        string
+	operation    Operation
+	natOp        string
+	finNFe       string
+	tpNF         string
+	idDest       string
+	indFinal     string
+	indPres      string
+	indIEDest    string
+	indIntermed  string // empty on sales with the presence of the buyer
+	intermediary *mockIntermediary
+	tpImp        string
+	CFOP         string
+	CRT          string
+	pPIS         int // PIS and COFINS rates of the emitter regime
+	pCOFINS      int
+	taxReform    TaxReformLayout
+	emit         mockParty
+	emitName     string
+	company      *universe.Company // the emitter, when it is a company of a universe
+	dest         mockParty
+	destName     string
+	destEmail    string
+	recipient    RecipientKind
+	transport    *mockTransport
+	pickup       municipality
+	delivery     municipality
+	items        []mockItem
+	item         *mockItem // the item being filled, or total outside of the items
+	total        mockItem
+	vNF          cents
+	payments     []mockPayment
+	vChange      cents // the change of the cash payments
+	accessKey    string
+	refNFe       string
+	importDecl   *importDeclaration // the DI of imports
+	export       *exportDeclaration // the place of shipment of exports
+}
+
+// inferTemplateType finds out which kind of document the template describes.
+func inferTemplateType(template string) TemplateType {
+	switch {
+	case strings.Contains(template, "<CFe"):
+		return CFe
+	case strings.Contains(template, "<mod>65</mod>"):
+		return NFCe
+	case strings.Contains(template, "<finNFe>2</finNFe>"):
+		return NFeComplementar
+	case strings.Contains(template, "<finNFe>3</finNFe>"):
+		return NFeAjuste
+	case strings.Contains(template, "<finNFe>4</finNFe>"):
+		return NFeDevolucao
+	default:
+		return NFe
+	}
+}
+
+// newMockDocument builds a consistent set of values for a document of the TemplateType. It fails
+// when the sequence of the config has no number left.
+func newMockDocument(tt TemplateType, cfg *generationConfig) (*mockDocument, error) {
+	doc := &mockDocument{
+		templateType: tt,
+		model:        "55",
+		tpAmb:        tpAmb(),
+		tpEmis:       "1",
+		serie:        gofakeit.Number(1, 999),
+		nNF:          gofakeit.Number(1, 999999999),
+		cNF:          fmt.Sprintf("%08d", gofakeit.Number(10000000, 99999999)),
+		finNFe:       "1",
+		tpNF:         "1",
+		indFinal:     indFinal(),
+		indPres:      "1",
+		tpImp:        gofakeit.RandomString([]string{"1", "2"}),
+		CRT:          "3",
+		taxReform:    cfg.taxReform,
+	}
+
+	emitUF := contingencyUF(cfg.contingency, UF())
+	if tt == CFe {
+		emitUF = "SP" // CF-e SAT is issued in São Paulo only
+	}
+	if cfg.sequence != nil {
+		emitUF = cfg.sequence.emitterUF(emitUF)
+	}
+	doc.emit = mockParty{CNPJ: br_documents.CNPJ(), city: randomMunicipality(emitUF)}
+	doc.emit.IE = br_documents.IE(emitUF)
+	if cfg.CNPJ != "" {
+		doc.emit.CNPJ = cfg.CNPJ
+	}
+	doc.emitName = xNome()
+	doc.applyEmitterProfile(cfg.emitter)
+	if cfg.universe != nil {
+		doc.applyUniverseEmitter(cfg)
+	}
+	if cfg.sequence != nil {
+		cfg.sequence.keepEmitter(doc)
+	}
+	emitUF = doc.emit.city.uf
+
+	destUF := emitUF
+	if tt != NFCe && tt != CFe && gofakeit.Number(1, 10) > 7 {
+		for destUF == emitUF {
+			destUF = UF()
+		}
+	}
+	doc.dest = mockParty{CNPJ: br_documents.CNPJ(), CPF: br_documents.CPF(), city: randomMunicipality(destUF)}
+	doc.dest.IE = br_documents.IE(destUF)
+	if cfg.CPF != "" {
+		doc.dest.CPF = cfg.CPF
+	}
+
+	switch tt {
+	case NFe:
+		doc.indIEDest = "1"
+		if doc.indFinal == "1" && gofakeit.Bool() {
+			doc.indIEDest = "9" // 9 = não contribuinte, e.g. a company buying for its own use
+		}
+		doc.CFOP = gofakeit.RandomString([]string{"101", "102"})
+		doc.natOp = "Venda de mercadoria"
+		if doc.CFOP == "101" {
+			doc.natOp = "Venda de producao do estabelecimento"
+		}
+	case NFCe:
+		doc.model = "65"
+		doc.CRT = "1"
+		doc.indFinal = "1"
+		doc.indIEDest = "9"
+		doc.tpImp = "4"
+		doc.CFOP = "102"
+		doc.natOp = "Venda ao consumidor"
+	case NFeDevolucao:
+		doc.CRT = "1"
+		doc.tpNF = "0"
+		doc.indFinal = "1"
+		doc.indIEDest = "9"
+		doc.indPres = "9"
+		doc.finNFe = "4"
+		doc.CFOP = "202"
+		doc.natOp = "Devolucao de venda"
+	case NFeComplementar:
+		doc.indIEDest = "1"
+		doc.finNFe = "2"
+		doc.CFOP = gofakeit.RandomString([]string{"101", "102"})
+		doc.natOp = "Complemento de preco"
+	case NFeAjuste:
+		doc.indFinal = "0"
+		doc.indIEDest = "1"
+		doc.indPres = "0"
+		doc.finNFe = "3"
+		doc.CFOP = "949"
+		doc.natOp = "Ajuste de ICMS"
+	case CFe:
+		doc.model = "59"
+		doc.serie = gofakeit.Number(900000001, 999999999) // nserieSAT
+		doc.nNF = gofakeit.Number(1, 999999)              // nCFe
+		doc.cNF = fmt.Sprintf("%06d", gofakeit.Number(0, 999999))
+		doc.CFOP = "102"
+	}
+	recipient := cfg.recipient
+	if recipient == RecipientDefault {
+		recipient = defaultRecipient(tt)
+	}
+	doc.applyRecipient(recipient)
+	operation := cfg.operation
+	if recipient == RecipientForeign && tt == NFe && operation == OperationDefault {
+		operation = OperationExportacao // the recipient is abroad
+	}
+	if operation != OperationDefault {
+		doc.applyOperation(operation)
+	}
+	if cfg.universe != nil {
+		doc.applyUniverse(cfg.universe)
+	}
+	if cfg.reference != nil {
+		doc.applyReference(cfg.reference)
+		emitUF = doc.emit.city.uf
+	}
+
+	destUF = doc.dest.city.uf
+	switch destUF {
+	case emitUF:
+		doc.idDest = "1"
+	case exterior.uf:
+		doc.idDest = "3"
+		destUF = emitUF // the goods leave or arrive through the emitter state
+	default:
+		doc.idDest = "2"
+	}
+	doc.CFOP = cfopPrefix(doc.tpNF, doc.idDest) + doc.CFOP
+	doc.applyIntermediary(cfg)
+
+	doc.applyTransport()
+	doc.pickup = randomMunicipality(emitUF)
+	doc.delivery = randomMunicipality(destUF)
+
+	simples, ok := simplesNacional(cfg.icms)
+	if !ok {
+		simples = doc.CRT == "1" && !regularPIS(cfg.pis)
+	}
+	if tt != CFe {
+		doc.CRT = "3"
+		if simples {
+			doc.CRT = "1"
+		}
+	}
+	if spec, ok := emitterSpecs[cfg.emitter]; ok {
+		doc.CRT = spec.CRT
+	}
+	if doc.company != nil {
+		doc.CRT = doc.company.CRT
+	}
+
+	if doc.CRT == "3" {
+		doc.pPIS, doc.pCOFINS = 165, 760 // Lucro Real, non-cumulative
+		if gofakeit.Number(1, 3) == 1 {
+			doc.pPIS, doc.pCOFINS = 65, 300 // Lucro Presumido, cumulative
+		}
+	}
+
+	doc.dhEmi = emissionTime()
+	switch {
+	case cfg.emissionDate:
+		doc.dhEmi = emissionDate(cfg)
+	case tt == NFe || doc.complementary() || cfg.contingency == ContingencyOfflineNFCe:
+		// Not yet authorized: emitted moments before being sent to SEFAZ.
+		doc.dhEmi = time.Now().In(brasilia).Add(-time.Duration(gofakeit.Number(60, 7200)) * time.Second).Truncate(time.Second)
+	}
+	if !cfg.emissionDate && cfg.reference != nil && !doc.dhEmi.After(cfg.reference.dhEmi) {
+		// The goods are returned some days after the sale, and never in the future.
+		doc.dhEmi = cfg.reference.dhEmi.Add(time.Duration(gofakeit.Number(1, 30*24)) * time.Hour).In(brasilia)
+		if now := time.Now().In(brasilia).Truncate(time.Second); doc.dhEmi.After(now) {
+			doc.dhEmi = now
+		}
+	}
+	if cfg.sequence != nil {
+		if err := cfg.sequence.number(doc, cfg); err != nil {
+			return nil, err
+		}
+	}
+	doc.dhEmi = doc.dhEmi.In(ufLocation(doc.emit.city.uf)) // the official time of the emitter state
+	if doc.operation == OperationImportacao || doc.operation == OperationExportacao {
+		doc.applyForeignTrade()
+	}
+	doc.dhSaiEnt = doc.dhEmi.Add(time.Duration(gofakeit.Number(0, 2880)) * time.Minute)
+	doc.dhRecbto = doc.dhEmi.Add(time.Duration(gofakeit.Number(1, 90)) * time.Second)
+	doc.applyContingency(cfg.contingency)
+
+	count := max(len(cfg.icms), len(cfg.pis), len(cfg.ipi), serviceItemCount(cfg.services), len(sectorList(cfg.sectors)), 1)
+	if cfg.reference != nil {
+		count = len(cfg.reference.items)
+	}
+	doc.items = make([]mockItem, count)
+	for i := range doc.items {
+		doc.items[i] = newMockItem(doc, cfg, i+1)
+		doc.total.add(doc.items[i])
+	}
+	doc.item = &doc.total
+	doc.vNF = doc.total.net() + doc.total.vServ + doc.total.vII + doc.total.vIPI + doc.total.vICMSST + doc.total.vFCPST - doc.total.vICMSDeson
+	doc.applyPayments(cfg.payments)
+
+	doc.buildAccessKey()
+	if (tt == NFeDevolucao || doc.complementary()) && doc.refNFe == "" {
+		// The returned, complemented or adjusted goods were sold by the emitter itself, some days before.
+		ufCode, _ := br_documents.UFCode(emitUF)
+		doc.refNFe = br_documents.AccessKey(br_documents.AccessKeyConfig{
+			CNPJ:         doc.emit.CNPJ,
+			CPF:          doc.emit.CPF,
+			UF:           ufCode,
+			Date:         doc.dhEmi.AddDate(0, 0, -gofakeit.Number(1, 90)),
+			Model:        "55",
+			Series:       doc.serie,
+			Number:       gofakeit.Number(1, 999999999),
+			EmissionType: "1",
+		})
+	}
+	if doc.operation == OperationDevolucaoCompra {
+		// The returned goods were bought from the recipient, some days before.
+		ufCode, _ := br_documents.UFCode(doc.dest.city.uf)
+		doc.refNFe = br_documents.AccessKey(br_documents.AccessKeyConfig{
+			CNPJ:         doc.dest.CNPJ,
+			UF:           ufCode,
+			Date:         doc.dhEmi.AddDate(0, 0, -gofakeit.Number(1, 90)),
+			Model:        "55",
+			Series:       gofakeit.Number(1, 999),
+			Number:       gofakeit.Number(1, 999999999),
+			EmissionType: "1",
+		})
+	}
+	return doc, nil
+}
+
+// complementary reports whether the document complements or adjusts the values of another NF-e
+// (finNFe 2 or 3), with items that have no quantity.
+func (doc *mockDocument) complementary() bool {
+	return doc.templateType == NFeComplementar || doc.templateType == NFeAjuste
+}
+
+// buildAccessKey sets the access key from the ide fields and the emitter.
+func (doc *mockDocument) buildAccessKey() {
+	ufCode, _ := br_documents.UFCode(doc.emit.city.uf)
+	if doc.templateType == CFe {
+		doc.accessKey = cfeAccessKey(ufCode, doc)
+		return
+	}
+	doc.accessKey = br_documents.AccessKey(br_documents.AccessKeyConfig{
+		CNPJ:         doc.emit.CNPJ,
+		CPF:          doc.emit.CPF,
+		UF:           ufCode,
+		Date:         doc.dhEmi,
+		Model:        doc.model,
+		Series:       doc.serie,
+		Number:       doc.nNF,
+		EmissionType: doc.tpEmis,
+		NumericCode:  doc.cNF,
+	})
+}
+
+// cfopPrefix returns the first CFOP digit of the operation: 1/2/3 for entries and 5/6/7 for exits,
+// within the state, interstate or abroad.
+func cfopPrefix(tpNF, idDest string) string {
+	prefix := 5
+	if tpNF == "0" {
+		prefix = 1
+	}
+	switch idDest {
+	case "2":
+		prefix++
+	case "3":
+		prefix += 2
+	}
+	return strconv.Itoa(prefix)
+}
+
+// defaultICMSSituation returns the ICMS situation of the items of the document when none is chosen.
+func defaultICMSSituation(doc *mockDocument) ICMSSituation {
+	if situation, ok := operationICMSSituation(doc.operation, doc.simples()); ok {
+		return situation
+	}
+	switch {
+	case doc.templateType == NFeAjuste && doc.simples():
+		return CSOSN900
+	case doc.templateType == NFeAjuste:
+		return CST90
+	case doc.simples():
+		return CSOSN102
+	}
+	return CST00
+}
+
+// newMockItem generates the values of the item number and its taxes for the document, with the
+// situations chosen for it in the configuration.
+func newMockItem(doc *mockDocument, cfg *generationConfig, number int) mockItem {
+	index := number - 1
+	situation := defaultICMSSituation(doc)
+	if index < len(cfg.icms) {
+		situation = cfg.icms[index]
+	}
+	if situation == ICMSRandom {
+		situation = randomICMSSituation(doc.simples(), doc.templateType)
+	}
+
+	item := mockItem{
+		number:    number,
+		CFOP:      doc.CFOP,
+		icms:      situation,
+		quantity:  int64(gofakeit.Number(1, 50)) * 10000,
+		unitValue: cents(gofakeit.Number(100, 200000)),
+		orig:      gofakeit.RandomString([]string{"0", "0", "0", "1", "2"}),
+	}
+	if doc.operation == OperationImportacao {
+		item.orig = "1" // 1 = estrangeira, importação direta
+	}
+	service := serviceItem(cfg.services, number)
+	if service {
+		item.quantity = int64(gofakeit.Number(1, 10)) * 10000 // units or hours of service
+	} else if cfg.reference != nil && doc.templateType == NFeDevolucao {
+		item.returnItem(cfg.reference.items[index], cfg.returnPercent)
+	} else if cfg.reference != nil {
+		item.referenceItem(cfg.reference.items[index])
+	} else if sector := itemSector(cfg.sectors, index); sector != 0 {
+		item.sectorProduct(doc, sector)
+	} else {
+		spec := icmsSpecs[situation]
+		if doc.company != nil {
+			item.companyProduct(doc.company, spec.st || spec.stRetained)
+		} else {
+			item.product = randomProduct(spec.st || spec.stRetained)
+			item.gtin = productGTIN(item.product)
+		}
+		item.unitValue = item.product.price.applyRate(gofakeit.Number(7000, 13000))
+		if item.product.fractional {
+			item.quantity = int64(gofakeit.Number(1000, 500000)) // weighed or measured goods
+		}
+	}
+	item.vProd = cents((item.quantity*int64(item.unitValue) + 5000) / 10000)
+	if item.vProd == 0 {
+		item.vProd = 1
+		item.unitValue = cents((10000*100 + item.quantity - 1) / item.quantity)
+		item.vProd = cents((item.quantity*int64(item.unitValue) + 5000) / 10000)
+	}
+	if doc.complementary() {
+		// Only the difference of value is informed, a share of the value of the original item. The base
+		// of an adjustment is at least 1.00, whose ICMS is a cent or more even at the 4% interstate rate
+		// with the largest reduction of the base.
+		minimum := cents(1)
+		if doc.templateType == NFeAjuste {
+			minimum = 100
+		}
+		item.vProd = max(item.vProd.applyRate(gofakeit.Number(100, 2000)), minimum)
+		item.quantity, item.unitValue = 0, 0
+	} else if !service && cfg.reference == nil && gofakeit.Number(1, 3) == 1 {
+		item.vDesc = item.vProd.applyRate(gofakeit.Number(100, 1500))
+	}
+
+	if index < len(cfg.ipi) {
+		item.calculateIPI(doc, cfg.ipi[index])
+	} else if doc.templateType == NFeDevolucao {
+		item.calculateIPI(doc, IPITrib) // the IPI of the returned goods
+	}
+	if service {
+		item.calculateISSQN(doc)
+	} else {
+		if doc.templateType != CFe {
+			item.CFOP = itemCFOP(doc.CFOP, situation)
+		}
+		item.calculateICMS(doc)
+	}
+	if doc.templateType == NFeAjuste {
+		// The adjustment carries the taxes only, and no relieved ICMS to deduct from a value.
+		item.vProd, item.vICMSDeson = 0, 0
+	}
+
+	item.pis = defaultPISSituation(doc)
+	if index < len(cfg.pis) {
+		item.pis = cfg.pis[index]
+	}
+	if item.pis == PISRandom {
+		item.pis = randomPISSituation(doc)
+	}
+	item.calculatePIS(doc)
+
+	item.calculateForeignTrade(doc)
+
+	if doc.taxReform != 0 {
+		item.calculateTaxReform(doc.taxReform)
+	}
+
+	item.vTotTrib = item.net().applyRate(gofakeit.Number(1000, 3500))
+	return item
+}
+
+// add adds the values of the item to the totals.
+func (i *mockItem) add(item mockItem) {
+	if item.service != nil {
+		i.vServ += item.vProd
+		i.vISSQN += item.vISSQN
+		i.vPISServ += item.vPIS
+		i.vCOFINSServ += item.vCOFINS
+	} else {
+		i.vProd += item.vProd
+		i.vPIS += item.vPIS
+		i.vCOFINS += item.vCOFINS
+	}
+	i.vDesc += item.vDesc
+	i.vBC += item.vBC
+	i.vICMS += item.vICMS
+	i.vICMSDeson += item.vICMSDeson
+	i.vBCST += item.vBCST
+	i.vICMSST += item.vICMSST
+	i.vFCP += item.vFCP
+	i.vFCPST += item.vFCPST
+	i.vFCPUFDest += item.vFCPUFDest
+	i.vICMSUFDest += item.vICMSUFDest
+	if item.pICMSInter > 0 {
+		i.pICMSInter = item.pICMSInter // the totals carry DIFAL
+	}
+	i.vIPI += item.vIPI
+	i.vII += item.vII
+	i.vTotTrib += item.vTotTrib
+	if item.reform != nil {
+		if i.reform == nil {
+			i.reform = &reformTaxes{}
+		}
+		i.reform.add(*item.reform)
+	}
+}
+
+// cfeAccessKey builds the CF-e access key: cUF, AAMM, CNPJ, model 59, nserieSAT, nCFe, cNF and cDV.
+func cfeAccessKey(ufCode string, doc *mockDocument) string {
+	partial := fmt.Sprintf("%s%s%s59%09d%06d%s", ufCode, doc.dhEmi.Format("0601"), doc.emit.CNPJ, doc.serie, doc.nNF, doc.cNF)
+	weights := []int{2, 3, 4, 5, 6, 7, 8, 9}
+	sum := 0
+	for i := len(partial) - 1; i >= 0; i-- {
+		sum += int(partial[i]-'0') * weights[(len(partial)-1-i)%len(weights)]
+	}
+	dv := 11 - sum%11
+	if dv >= 10 {
+		dv = 0
+	}
+	return partial + strconv.Itoa(dv)
+}
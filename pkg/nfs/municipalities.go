@@ -0,0 +1,24 @@
+package nfs
+
+import (
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mayckol/brfiscalfaker/pkg/br_locale"
+)
+
+// municipality is a municipality of the IBGE table, used in cMun/xMun pairs.
+type municipality struct {
+	code string
+	name string
+	uf   string
+}
+
+// newMunicipality converts a municipality of the pt-BR data.
+func newMunicipality(m br_locale.Municipality) municipality {
+	return municipality{code: m.Code, name: m.Name, uf: m.UF}
+}
+
+// randomMunicipality returns a municipality of the state, or of any state when uf is empty.
+func randomMunicipality(uf string) municipality {
+	candidates := br_locale.Municipalities(uf)
+	return newMunicipality(candidates[gofakeit.Number(0, len(candidates)-1)])
+}
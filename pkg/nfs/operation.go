@@ -0,0 +1,262 @@
+package nfs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+// Operation is an operation profile of the NF-e, which sets the nature of the operation (natOp),
+// the CFOP, the purpose (finNFe), the direction (tpNF), the destination (idDest) and the recipient
+// together.
+type Operation int
+
+const (
+	// OperationDefault keeps the operation of the template: a sale, or a sale return for NFeDevolucao.
+	OperationDefault Operation = iota
+	// OperationVendaInterna is a sale to a contributor of the emitter state (CFOP 5101 or 5102).
+	OperationVendaInterna
+	// OperationVendaInterestadual is a sale to a contributor of another state (CFOP 6101 or 6102).
+	OperationVendaInterestadual
+	// OperationVendaConsumidorFinal is a sale to a final consumer who is not an ICMS contributor,
+	// within the state or interstate (CFOP 5102 or 6102), which may owe the DIFAL.
+	OperationVendaConsumidorFinal
+	// OperationRemessaIndustrializacao sends goods to be processed by another company (CFOP 5901 or
+	// 6901), with the ICMS suspended.
+	OperationRemessaIndustrializacao
+	// OperationTransferencia moves goods to another establishment of the same company (CFOP 5152 or 6152).
+	OperationTransferencia
+	// OperationBonificacao ships goods as a bonus, donation or gift (CFOP 5910 or 6910).
+	OperationBonificacao
+	// OperationDevolucaoCompra returns purchased goods to the supplier (CFOP 5202 or 6202, finNFe 4),
+	// referencing the purchase NF-e.
+	OperationDevolucaoCompra
+	// OperationExportacao is a sale to a recipient abroad (CFOP 7102, idDest 3), immune to the ICMS.
+	OperationExportacao
+	// OperationImportacao is the entry of goods bought from a supplier abroad (CFOP 3102, idDest 3).
+	OperationImportacao
+)
+
+// Recipient locations of the operations.
+const (
+	anywhere = iota
+	sameState
+	otherState
+	abroad
+)
+
+// operationSpec describes an Operation: its name, natOp and CFOP without the first digit, which
+// follows the direction and destination, its purpose and direction, where the recipient is and
+// whether it is a contributor, and the ICMS situations of its items when none is chosen.
+type operationSpec struct {
+	name        string
+	natOp       string
+	cfop        string
+	finNFe      string
+	tpNF        string
+	location    int
+	contributor bool
+	sale        bool
+	icms        ICMSSituation // of the regular regime, CST00 when not set
+	icmsSimples ICMSSituation // of the Simples Nacional, CSOSN102 when not set
+}
+
+// operationSpecs holds the spec of each Operation.
+var operationSpecs = map[Operation]operationSpec{
+	OperationVendaInterna: {
+		name: "OperationVendaInterna", natOp: "Venda de mercadoria", cfop: "102", finNFe: "1", tpNF: "1",
+		location: sameState, contributor: true, sale: true,
+	},
+	OperationVendaInterestadual: {
+		name: "OperationVendaInterestadual", natOp: "Venda de mercadoria", cfop: "102", finNFe: "1", tpNF: "1",
+		location: otherState, contributor: true, sale: true,
+	},
+	OperationVendaConsumidorFinal: {
+		name: "OperationVendaConsumidorFinal", natOp: "Venda a consumidor final", cfop: "102", finNFe: "1", tpNF: "1",
+		location: anywhere, sale: true,
+	},
+	OperationRemessaIndustrializacao: {
+		name: "OperationRemessaIndustrializacao", natOp: "Remessa para industrializacao por encomenda", cfop: "901",
+		finNFe: "1", tpNF: "1", location: anywhere, contributor: true, icms: CST50, icmsSimples: CSOSN400,
+	},
+	OperationTransferencia: {
+		name: "OperationTransferencia", natOp: "Transferencia de mercadoria adquirida de terceiros", cfop: "152",
+		finNFe: "1", tpNF: "1", location: anywhere, contributor: true,
+	},
+	OperationBonificacao: {
+		name: "OperationBonificacao", natOp: "Remessa em bonificacao, doacao ou brinde", cfop: "910",
+		finNFe: "1", tpNF: "1", location: anywhere, contributor: true,
+	},
+	OperationDevolucaoCompra: {
+		name: "OperationDevolucaoCompra", natOp: "Devolucao de compra para comercializacao", cfop: "202",
+		finNFe: "4", tpNF: "1", location: anywhere, contributor: true,
+	},
+	OperationExportacao: {
+		name: "OperationExportacao", natOp: "Exportacao de mercadoria", cfop: "102", finNFe: "1", tpNF: "1",
+		location: abroad, sale: true, icms: CST41, icmsSimples: CSOSN300,
+	},
+	OperationImportacao: {
+		name: "OperationImportacao", natOp: "Compra para comercializacao - importacao", cfop: "102",
+		finNFe: "1", tpNF: "0", location: abroad,
+	},
+}
+
+// foreignCountries are the BACEN codes and names of the countries of the recipients abroad.
+var foreignCountries = []struct{ code, name string }{
+	{"0639", "ARGENTINA"},
+	{"1589", "CHILE"},
+	{"1600", "CHINA"},
+	{"2496", "ESTADOS UNIDOS"},
+	{"0230", "ALEMANHA"},
+	{"5860", "PARAGUAI"},
+	{"6076", "PORTUGAL"},
+	{"8451", "URUGUAI"},
+}
+
+// exterior is the municipality of the addresses abroad.
+var exterior = municipality{code: "9999999", name: "EXTERIOR", uf: "EX"}
+
+// String returns the name of the Operation.
+func (o Operation) String() string {
+	if o == OperationDefault {
+		return "OperationDefault"
+	}
+	if spec, ok := operationSpecs[o]; ok {
+		return spec.name
+	}
+	return "Unknown"
+}
+
+// ParseOperation converts a string (e.g. "OperationVendaInterna" or "VendaInterna") to an Operation.
+func ParseOperation(s string) (Operation, error) {
+	name := "Operation" + strings.TrimPrefix(s, "Operation")
+	if name == "OperationDefault" {
+		return OperationDefault, nil
+	}
+	for operation, spec := range operationSpecs {
+		if spec.name == name {
+			return operation, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid Operation: %s", s)
+}
+
+// checkOperation fails when the operation cannot be issued in a document of the TemplateType with
+// the chosen items. Service items are sold in domestic sales only and entries take PISOutr only.
+func checkOperation(tt TemplateType, operation Operation, cfg *generationConfig) error {
+	if operation == OperationDefault {
+		return nil
+	}
+	spec, ok := operationSpecs[operation]
+	if !ok {
+		return fmt.Errorf("unknown operation: %d", operation)
+	}
+	if tt != NFe {
+		return fmt.Errorf("operations are not supported for %v documents", tt)
+	}
+	if len(cfg.services) > 0 && (!spec.sale || spec.location == abroad) {
+		return fmt.Errorf("service items are not supported for %v", operation)
+	}
+	if spec.tpNF == "0" {
+		for _, situation := range cfg.pis {
+			if situation != PISRandom && situation != PISOutr {
+				return fmt.Errorf("%v is an exit situation; %v takes PISOutr", situation, operation)
+			}
+		}
+	}
+	return nil
+}
+
+// applyOperation sets the ide fields and the recipient of the document for the operation.
+// The recipient of a transfer is another establishment of the emitter and the recipient abroad
+// is identified by a foreign document (idEstrangeiro) instead of a CNPJ.
+func (doc *mockDocument) applyOperation(operation Operation) {
+	spec := operationSpecs[operation]
+	doc.operation = operation
+	doc.natOp = spec.natOp
+	doc.finNFe = spec.finNFe
+	doc.tpNF = spec.tpNF
+	doc.CFOP = spec.cfop
+	if spec.cfop == "102" && spec.sale && spec.contributor && gofakeit.Bool() {
+		doc.natOp, doc.CFOP = "Venda de producao do estabelecimento", "101"
+	}
+	if operation == OperationExportacao && gofakeit.Number(1, 3) == 1 {
+		// A trading company exports the goods it received with that purpose (exportação indireta).
+		doc.natOp, doc.CFOP = "Exportacao de mercadoria recebida para exportacao", "501"
+	}
+
+	doc.indFinal, doc.indIEDest, doc.indPres = "0", "1", "0"
+	if spec.sale {
+		doc.indPres = "1"
+	}
+	if !spec.contributor {
+		doc.indIEDest = "9"
+	}
+	if operation == OperationVendaConsumidorFinal {
+		doc.indFinal = "1"
+	}
+
+	switch spec.location {
+	case sameState:
+		doc.dest.city = randomMunicipality(doc.emit.city.uf)
+	case otherState:
+		uf := doc.emit.city.uf
+		for uf == doc.emit.city.uf {
+			uf = UF()
+		}
+		doc.dest.city = randomMunicipality(uf)
+	case abroad:
+		country := foreignCountries[gofakeit.Number(0, len(foreignCountries)-1)]
+		doc.dest = mockParty{
+			idEstrangeiro: gofakeit.Numerify("##########"),
+			city:          exterior,
+			cPais:         country.code,
+			xPais:         country.name,
+		}
+	}
+	doc.dest.IE = ""
+	if doc.indIEDest == "1" {
+		doc.dest.IE = br_documents.IE(doc.dest.city.uf)
+	}
+	if operation == OperationTransferencia {
+		doc.dest.CNPJ = br_documents.CNPJBranch(doc.emit.CNPJ, gofakeit.Number(2, 30))
+	}
+}
+
+// operationICMSSituation returns the ICMS situation of the items of the operation when none is chosen.
+func operationICMSSituation(operation Operation, simples bool) (ICMSSituation, bool) {
+	spec := operationSpecs[operation]
+	if simples && spec.icmsSimples != 0 {
+		return spec.icmsSimples, true
+	}
+	if !simples && spec.icms != 0 {
+		return spec.icms, true
+	}
+	return 0, false
+}
+
+// nfRefGroupXML renders the NFref group of the document, which is empty unless it references another NF-e.
+func (doc *mockDocument) nfRefGroupXML() string {
+	if doc.refNFe == "" {
+		return ""
+	}
+	return xmlGroup("NFref", []xmlElement{{"refNFe", doc.refNFe}})
+}
+
+// country returns the BACEN code of the country of the party, Brazil unless it is abroad.
+func (p mockParty) country() string {
+	if p.cPais == "" {
+		return cPais()
+	}
+	return p.cPais
+}
+
+// countryName returns the name of the country of the party.
+func (p mockParty) countryName() string {
+	if p.xPais == "" {
+		return xPais()
+	}
+	return p.xPais
+}
@@ -0,0 +1,81 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+func TestWithOperation(t *testing.T) {
+	tests := []struct {
+		operation Operation
+		expected  []string
+	}{
+		{OperationVendaInterna, []string{"<tpNF>1</tpNF>", "<idDest>1</idDest>", "<finNFe>1</finNFe>", "<indIEDest>1</indIEDest>"}},
+		{OperationVendaInterestadual, []string{"<idDest>2</idDest>", "<indIEDest>1</indIEDest>"}},
+		{OperationVendaConsumidorFinal, []string{"<natOp>Venda a consumidor final</natOp>", "<indFinal>1</indFinal>", "<indIEDest>9</indIEDest>"}},
+		{OperationRemessaIndustrializacao, []string{"901</CFOP>", "<CST>50</CST>"}},
+		{OperationTransferencia, []string{"152</CFOP>"}},
+		{OperationBonificacao, []string{"910</CFOP>"}},
+		{OperationDevolucaoCompra, []string{"202</CFOP>", "<finNFe>4</finNFe>", "<refNFe>"}},
+		{OperationExportacao, []string{"<CFOP>7", "<idDest>3</idDest>", "<idEstrangeiro>", "<UF>EX</UF>", "<CST>41</CST>", "<CST>08</CST>", "<exporta>"}},
+		{OperationImportacao, []string{"<CFOP>3102</CFOP>", "<tpNF>0</tpNF>", "<idDest>3</idDest>", "<cMun>9999999</cMun>", "<DI>", "<adi>", "<II>"}},
+	}
+	for _, test := range tests {
+		t.Run(test.operation.String(), func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				xmlBytes, err := NewNFeGenerator().Generate(WithOperation(test.operation))
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				document := string(xmlBytes)
+				for _, element := range test.expected {
+					if !strings.Contains(document, element) {
+						t.Errorf("Expected %s", element)
+					}
+				}
+				if errs := Validate(NFe, xmlBytes); len(errs) > 0 {
+					t.Fatalf("Expected no schema violations, got %v", errs)
+				}
+			}
+		})
+	}
+}
+
+func TestWithOperation_Transferencia(t *testing.T) {
+	doc, _ := newMockDocument(NFe, &generationConfig{operation: OperationTransferencia})
+	if doc.dest.CNPJ[:8] != doc.emit.CNPJ[:8] || doc.dest.CNPJ == doc.emit.CNPJ || !br_documents.ValidateCNPJ(doc.dest.CNPJ) {
+		t.Errorf("Expected another establishment of %s, got %s", doc.emit.CNPJ, doc.dest.CNPJ)
+	}
+}
+
+func TestWithOperation_Errors(t *testing.T) {
+	if _, err := NewNFCeGenerator().Generate(WithOperation(OperationVendaInterna)); err == nil {
+		t.Errorf("Expected an error for an NFCe operation")
+	}
+	if _, err := NewNFeGenerator().Generate(WithOperation(OperationExportacao), WithServiceItems(1)); err == nil {
+		t.Errorf("Expected an error for exported services")
+	}
+	if _, err := NewNFeGenerator().Generate(WithOperation(OperationImportacao), WithPIS(PISAliq)); err == nil {
+		t.Errorf("Expected an error for an exit PIS situation on an entry")
+	}
+	if _, err := NewNFeGenerator().Generate(WithOperation(Operation(99))); err == nil {
+		t.Errorf("Expected an error for an unknown operation")
+	}
+}
+
+func TestParseOperation(t *testing.T) {
+	for operation := OperationDefault; operation <= OperationImportacao; operation++ {
+		parsed, err := ParseOperation(operation.String())
+		if err != nil || parsed != operation {
+			t.Errorf("Expected %v, got %v (%v)", operation, parsed, err)
+		}
+	}
+	if parsed, err := ParseOperation("Exportacao"); err != nil || parsed != OperationExportacao {
+		t.Errorf("Expected OperationExportacao, got %v (%v)", parsed, err)
+	}
+	if _, err := ParseOperation("Consignacao"); err == nil {
+		t.Errorf("Expected an error for an unknown operation")
+	}
+}
@@ -1,5 +1,11 @@
 package nfs
 
+import (
+	"time"
+
+	"github.com/mayckol/brfiscalfaker/pkg/universe"
+)
+
 // Option defines a function type for generator configuration options.
 type Option func(*generationConfig)
 
@@ -8,6 +14,29 @@ type generationConfig struct {
 	blockedPlaceholders []string
 	CPF                 string
 	CNPJ                string
+	fault               Fault
+	icms                []ICMSSituation
+	taxReform           TaxReformLayout
+	pis                 []PISSituation
+	ipi                 []IPISituation
+	services            []int
+	operation           Operation
+	referencedInvoice   []byte
+	reference           *referencedInvoice
+	returnPercent       int
+	sectors             ProductSector
+	payments            []PaymentMethod
+	marketplace         bool
+	marketplaceCNPJ     string
+	marketplaceID       string
+	recipient           RecipientKind
+	emitter             EmitterProfile
+	contingency         Contingency
+	emissionDate        bool
+	emissionFrom        time.Time
+	emissionTo          time.Time
+	sequence            *Sequence
+	universe            *universe.Universe
 }
 
 // WithBlockedPlaceholders returns an Option that blocks the specified placeholders.
@@ -30,3 +59,200 @@ func WithCNPJ(cnpj string) Option {
 		cfg.CNPJ = cnpj
 	}
 }
+
+// WithFault returns an Option that applies the Fault to the generated document, for negative tests.
+// Fault.Report describes the corrupted element of the document and the rejection it should trigger.
+// Faults apply to NF-e and NFC-e documents only.
+func WithFault(fault Fault) Option {
+	return func(cfg *generationConfig) {
+		cfg.fault = fault
+	}
+}
+
+// WithICMS returns an Option that generates one item for each ICMS situation, in order.
+// CST situations are issued by a regular regime emitter (CRT 3) and CSOSN situations by a
+// Simples Nacional emitter (CRT 1), so they cannot be mixed; ICMSRandom picks a situation of
+// the same regime. ICMS situations apply to NF-e and NFC-e documents only.
+func WithICMS(situations ...ICMSSituation) Option {
+	return func(cfg *generationConfig) {
+		cfg.icms = append(cfg.icms, situations...)
+	}
+}
+
+// WithPIS returns an Option that sets the PIS and COFINS situation of each item, in order, adding
+// items as needed. PISAliq, PISQtde and PISST belong to the regular regime (CRT 3) and entries take
+// PISOutr only; PISRandom picks a situation allowed for the document. PIS situations apply to NF-e
+// and NFC-e documents only.
+func WithPIS(situations ...PISSituation) Option {
+	return func(cfg *generationConfig) {
+		cfg.pis = append(cfg.pis, situations...)
+	}
+}
+
+// WithIPI returns an Option that sets the IPI situation of each item, in order, adding items as
+// needed. Items without a situation have no IPI group. IPI situations apply to NF-e documents only.
+func WithIPI(situations ...IPISituation) Option {
+	return func(cfg *generationConfig) {
+		cfg.ipi = append(cfg.ipi, situations...)
+	}
+}
+
+// WithServiceItems returns an Option that makes the items with the numbers (starting at 1) services
+// taxed by the ISSQN instead of the ICMS, adding items as needed; the other items are goods.
+// Service items apply to NF-e (NF-e conjugada) and CF-e documents only.
+func WithServiceItems(numbers ...int) Option {
+	return func(cfg *generationConfig) {
+		cfg.services = append(cfg.services, numbers...)
+	}
+}
+
+// WithTaxReform returns an Option that adds the IBS, CBS and Imposto Seletivo groups of the tax
+// reform layout to every item, with their totals, alongside the legacy ICMS, PIS and COFINS.
+// The tax reform groups apply to NF-e and NFC-e documents only.
+func WithTaxReform(layout TaxReformLayout) Option {
+	return func(cfg *generationConfig) {
+		cfg.taxReform = layout
+	}
+}
+
+// WithOperation returns an Option that issues the document for the operation profile, which sets
+// natOp, the CFOP of the items, finNFe, tpNF, idDest and the recipient coherently. Operations apply
+// to NF-e documents only.
+func WithOperation(operation Operation) Option {
+	return func(cfg *generationConfig) {
+		cfg.operation = operation
+	}
+}
+
+// WithReferencedInvoice returns an Option that makes the return reference the NF-e or NFC-e, which
+// is usually a document generated before. The return swaps the parties when the recipient is an ICMS
+// contributor (an exit with CFOP 5202/6202) and is issued by the original emitter otherwise (an
+// entry with CFOP 1202/2202), reusing the original items. Complements and adjustments of an NF-e
+// keep its parties and products. It applies to NFeDevolucao, NFeComplementar and NFeAjuste documents.
+func WithReferencedInvoice(xml []byte) Option {
+	return func(cfg *generationConfig) {
+		cfg.referencedInvoice = xml
+	}
+}
+
+// WithReferenced returns an Option that makes the document reference the invoice generated before
+// by GenerateInvoice, as WithReferencedInvoice with its XML.
+func WithReferenced(invoice *Invoice) Option {
+	return func(cfg *generationConfig) {
+		cfg.referencedInvoice = []byte{} // a nil invoice is a malformed reference, not a missing one
+		if invoice != nil {
+			cfg.referencedInvoice = append(cfg.referencedInvoice, invoice.XML...)
+		}
+	}
+}
+
+// WithPartialReturn returns an Option that returns the percentage (1 to 100) of the quantity of each
+// item of the referenced invoice, informed in pDevol; goods sold by the unit return whole units.
+func WithPartialReturn(percent int) Option {
+	return func(cfg *generationConfig) {
+		cfg.returnPercent = percent
+	}
+}
+
+// WithProductSector returns an Option that gives the goods items to the product sectors in turn
+// (e.g. Fuel | Medicine), with the NCM and the specific group of each sector: comb, med with the
+// batch (rastro), veicProd or arma. Sectors apply to NF-e documents, and fuels and medicines to
+// NFC-e documents too.
+func WithProductSector(sectors ProductSector) Option {
+	return func(cfg *generationConfig) {
+		cfg.sectors = sectors
+	}
+}
+
+// WithPayments returns an Option that pays the document with the means of payment, one detPag entry
+// each, splitting the total among them. Cash payments may exceed their share, with the change in
+// vTroco. Payments apply to NF-e and NFC-e documents; returns and adjustments take PaymentNone only.
+func WithPayments(methods ...PaymentMethod) Option {
+	return func(cfg *generationConfig) {
+		cfg.payments = append(cfg.payments, methods...)
+	}
+}
+
+// WithMarketplace returns an Option that makes the document a sale intermediated by the marketplace
+// with the CNPJ, where the emitter is registered as the seller id (idCadIntTran), informed in the
+// infIntermed group. NF-e sales are made on the internet and NFC-e sales are delivered at home.
+// An empty CNPJ or id picks a random marketplace or seller.
+func WithMarketplace(cnpj, id string) Option {
+	return func(cfg *generationConfig) {
+		cfg.marketplace = true
+		cfg.marketplaceCNPJ = cnpj
+		cfg.marketplaceID = id
+	}
+}
+
+// WithRecipientKind returns an Option that identifies the recipient by a CPF, a CNPJ or a foreign
+// document (idEstrangeiro), or leaves the anonymous consumer of NFC-e and CF-e documents out, with
+// indIEDest and the IE matching it. A foreign recipient of an NF-e makes the document an export.
+func WithRecipientKind(kind RecipientKind) Option {
+	return func(cfg *generationConfig) {
+		cfg.recipient = kind
+	}
+}
+
+// WithEmitterProfile returns an Option that issues the document by an emitter of the profile, with
+// its CRT and the ICMS groups matching it: CSOSN for the Simples Nacional and MEIs, CST otherwise.
+// The rural producer is identified by a CPF and issues NF-e documents only. Profiles apply to NF-e
+// and NFC-e documents.
+func WithEmitterProfile(profile EmitterProfile) Option {
+	return func(cfg *generationConfig) {
+		cfg.emitter = profile
+	}
+}
+
+// WithContingency returns an Option that issues the document in the contingency mode, with tpEmis
+// in the ide group and in the access key, and the moment (dhCont) and reason (xJust) of the
+// contingency. NF-e documents use FS-DA, SVC-AN, SVC-RS or EPEC, whose emitter is in a state served
+// by the SVC, and NFC-e documents are issued offline, with the offline QR Code and no protNFe.
+func WithContingency(contingency Contingency) Option {
+	return func(cfg *generationConfig) {
+		cfg.contingency = contingency
+	}
+}
+
+// WithEmissionDate returns an Option that emits the document at the moment, informed in dhEmi with the
+// UTC offset of the emitter state (e.g. -04:00 in AM). The exit (dhSaiEnt) and the authorization
+// (dhRecbto) come after it and the access key takes its year and month.
+func WithEmissionDate(t time.Time) Option {
+	return WithEmissionDateRange(t, t)
+}
+
+// WithEmissionDateRange returns an Option that emits the document at a random moment from one time to
+// the other, inclusive, as WithEmissionDate. Returns are emitted after the referenced invoice.
+func WithEmissionDateRange(from, to time.Time) Option {
+	return func(cfg *generationConfig) {
+		cfg.emissionDate = true
+		cfg.emissionFrom = from
+		cfg.emissionTo = to
+	}
+}
+
+// WithSequence returns an Option that numbers the document with the next number of the sequence
+// shared by a batch, issued by the emitter of its first document and after the document before it.
+// Sequences apply to NF-e and NFC-e documents without a referenced invoice.
+func WithSequence(sequence *Sequence) Option {
+	return func(cfg *generationConfig) {
+		cfg.sequence = sequence
+	}
+}
+
+// WithNumbering returns an Option that numbers the document in the series (serie) with the number
+// nNF. Use WithSequence to number a batch of documents.
+func WithNumbering(serie, nNF int) Option {
+	return WithSequence(NewSequence(serie, nNF))
+}
+
+// WithUniverse returns an Option that issues the document among the parties of the universe: the
+// emitter is one of its companies, with its address, regime (CRT) and products, and the recipient is
+// another company or one of its consumers, so that the same parties trade across a batch. The
+// emitter matches the emitter profile, situations and contingency mode chosen. Universes apply to
+// NF-e and NFC-e documents without a referenced invoice.
+func WithUniverse(u *universe.Universe) Option {
+	return func(cfg *generationConfig) {
+		cfg.universe = u
+	}
+}
@@ -0,0 +1,249 @@
+package nfs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// PaymentMethod is a means of payment of the document (tPag), each one a detPag entry.
+type PaymentMethod int
+
+const (
+	// PaymentRandom picks a means of payment allowed for the document.
+	PaymentRandom PaymentMethod = iota
+	// PaymentCash is dinheiro (01), with change (vTroco) when the customer pays more than the total.
+	PaymentCash
+	// PaymentCreditCard is cartão de crédito (03), paid at once or in installments, with the card group.
+	PaymentCreditCard
+	// PaymentDebitCard is cartão de débito (04), with the card group.
+	PaymentDebitCard
+	// PaymentBoleto is boleto bancário (15), paid in installments.
+	PaymentBoleto
+	// PaymentPIX is PIX dinâmico (17), with the payment service provider and the end-to-end ID in the card group.
+	PaymentPIX
+	// PaymentNone is sem pagamento (90), the only payment of returns and adjustments.
+	PaymentNone
+)
+
+// paymentSpec describes a PaymentMethod: its name, its tPag code and whether it is paid in installments
+// (indPag 1) always, sometimes or never.
+type paymentSpec struct {
+	name         string
+	tPag         string
+	installments []string
+}
+
+// paymentSpecs holds the spec of each PaymentMethod.
+var paymentSpecs = map[PaymentMethod]paymentSpec{
+	PaymentCash:       {name: "PaymentCash", tPag: "01", installments: []string{"0"}},
+	PaymentCreditCard: {name: "PaymentCreditCard", tPag: "03", installments: []string{"0", "1"}},
+	PaymentDebitCard:  {name: "PaymentDebitCard", tPag: "04", installments: []string{"0"}},
+	PaymentBoleto:     {name: "PaymentBoleto", tPag: "15", installments: []string{"1"}},
+	PaymentPIX:        {name: "PaymentPIX", tPag: "17", installments: []string{"0"}},
+	PaymentNone:       {name: "PaymentNone", tPag: "90"},
+}
+
+// paymentInstitution is a card acquirer or a PIX payment service provider.
+type paymentInstitution struct {
+	name string
+	CNPJ string
+}
+
+// cardAcquirers are the main card acquirers of Brazil.
+var cardAcquirers = []paymentInstitution{
+	{"Cielo", "01027058000191"},
+	{"Rede", "01425787000104"},
+	{"Stone", "16501555000157"},
+	{"GetNet", "10440482000154"},
+	{"PagSeguro", "08561701000101"},
+}
+
+// pixProviders are PIX payment service providers, whose ISPB is the root of their CNPJ.
+var pixProviders = []paymentInstitution{
+	{"Banco do Brasil", "00000000000191"},
+	{"Caixa Economica Federal", "00360305000104"},
+	{"Itau Unibanco", "60701190000104"},
+	{"Bradesco", "60746948000112"},
+	{"Nu Pagamentos", "18236120000158"},
+}
+
+// cardBands are the card brand codes (tBand): 01 Visa, 02 Mastercard, 03 American Express, 05 Diners,
+// 06 Elo and 07 Hipercard.
+var cardBands = []string{"01", "01", "02", "02", "03", "05", "06", "07"}
+
+// mockPayment is a detPag entry of the document. Card and PIX payments carry the institution that
+// processed them, integrated to the automation (tpIntegra 1) or on a separate POS terminal (2).
+type mockPayment struct {
+	method      PaymentMethod
+	indPag      string
+	vPag        cents
+	tpIntegra   string
+	institution paymentInstitution
+	tBand       string
+	cAut        string
+}
+
+// String returns the name of the PaymentMethod.
+func (m PaymentMethod) String() string {
+	if m == PaymentRandom {
+		return "PaymentRandom"
+	}
+	if spec, ok := paymentSpecs[m]; ok {
+		return spec.name
+	}
+	return "Unknown"
+}
+
+// ParsePaymentMethod converts a string (e.g. "PaymentPIX" or "PIX") to a PaymentMethod.
+func ParsePaymentMethod(s string) (PaymentMethod, error) {
+	name := "Payment" + strings.TrimPrefix(s, "Payment")
+	if name == "PaymentRandom" {
+		return PaymentRandom, nil
+	}
+	for method, spec := range paymentSpecs {
+		if spec.name == name {
+			return method, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid PaymentMethod: %s", s)
+}
+
+// checkPayments fails when the payments cannot be informed in a document of the TemplateType. Returns
+// and adjustments are issued without payment, which cannot be combined with other means. The CF-e
+// informs a single means of payment of its own.
+func checkPayments(tt TemplateType, methods []PaymentMethod) error {
+	if len(methods) == 0 {
+		return nil
+	}
+	if tt == CFe {
+		return fmt.Errorf("payments are not supported for CFe documents")
+	}
+	if len(methods) > 100 {
+		return fmt.Errorf("too many payments: %d (the maximum is 100)", len(methods))
+	}
+	for _, method := range methods {
+		if _, ok := paymentSpecs[method]; !ok && method != PaymentRandom {
+			return fmt.Errorf("unknown payment method: %d", method)
+		}
+		if method == PaymentNone && len(methods) > 1 {
+			return fmt.Errorf("PaymentNone cannot be combined with other payments")
+		}
+		if (tt == NFeDevolucao || tt == NFeAjuste) && method != PaymentNone {
+			return fmt.Errorf("%v is not supported for %v documents, which are issued without payment", method, tt)
+		}
+	}
+	return nil
+}
+
+// randomPaymentMethod picks a means of payment of a sale: the boleto is left to the NF-e.
+func randomPaymentMethod(tt TemplateType) PaymentMethod {
+	methods := []PaymentMethod{PaymentCash, PaymentCreditCard, PaymentCreditCard, PaymentDebitCard, PaymentPIX, PaymentPIX}
+	if tt == NFe || tt == NFeComplementar {
+		methods = append(methods, PaymentBoleto, PaymentBoleto)
+	}
+	return methods[gofakeit.Number(0, len(methods)-1)]
+}
+
+// applyPayments sets the means of payment of the document, which are the chosen ones or, when none
+// is chosen, one and sometimes two random ones. Returns and adjustments have no payment.
+func (doc *mockDocument) applyPayments(methods []PaymentMethod) {
+	tt := doc.templateType
+	if len(methods) == 0 {
+		methods = []PaymentMethod{PaymentRandom}
+		if tt != CFe && gofakeit.Number(1, 4) == 1 {
+			methods = append(methods, PaymentRandom)
+		}
+	}
+	if tt == NFeDevolucao || tt == NFeAjuste {
+		methods = []PaymentMethod{PaymentNone}
+	}
+
+	doc.payments = make([]mockPayment, len(methods))
+	for i, method := range methods {
+		if method == PaymentRandom {
+			method = randomPaymentMethod(tt)
+		}
+		p := mockPayment{method: method}
+		if installments := paymentSpecs[method].installments; len(installments) > 0 {
+			p.indPag = installments[gofakeit.Number(0, len(installments)-1)]
+		}
+		switch method {
+		case PaymentCreditCard, PaymentDebitCard:
+			p.tpIntegra = gofakeit.RandomString([]string{"1", "2"})
+			p.institution = cardAcquirers[gofakeit.Number(0, len(cardAcquirers)-1)]
+			p.tBand = cardBands[gofakeit.Number(0, len(cardBands)-1)]
+			p.cAut = gofakeit.Numerify("######")
+		case PaymentPIX:
+			p.tpIntegra = gofakeit.RandomString([]string{"1", "2"})
+			p.institution = pixProviders[gofakeit.Number(0, len(pixProviders)-1)]
+			p.cAut = pixEndToEndID(p.institution, doc)
+		}
+		doc.payments[i] = p
+	}
+	doc.splitPayments()
+}
+
+// splitPayments splits the total of the document among its payments, the last one taking the
+// remainder. Half of the cash payments are rounded up to the next ten reais, with the change in
+// vTroco.
+func (doc *mockDocument) splitPayments() {
+	doc.vChange = 0
+	remaining := doc.vNF
+	for i := range doc.payments {
+		p := &doc.payments[i]
+		if p.method == PaymentNone {
+			p.vPag = 0
+			continue
+		}
+		left := cents(len(doc.payments) - i - 1)
+		p.vPag = remaining
+		if left > 0 {
+			p.vPag = max(remaining.applyRate(gofakeit.Number(2000, 6000)), 1)
+			p.vPag = min(p.vPag, max(remaining-left, 0))
+		}
+		remaining -= p.vPag
+		if p.method == PaymentCash && gofakeit.Bool() {
+			paid := (p.vPag/1000 + 1) * 1000
+			doc.vChange += paid - p.vPag
+			p.vPag = paid
+		}
+	}
+}
+
+// pixEndToEndID builds the end-to-end ID of a PIX payment: E, the ISPB of the provider, the date and
+// time of the payment and a sequence of 11 characters.
+func pixEndToEndID(provider paymentInstitution, doc *mockDocument) string {
+	return "E" + provider.CNPJ[:8] + doc.dhEmi.UTC().Format("200601021504") + gofakeit.Password(false, true, true, false, false, 11)
+}
+
+// pagGroupXML renders the payment group of the document, with the change of the cash payments.
+func (doc *mockDocument) pagGroupXML() string {
+	x := &xmlBuilder{}
+	x.open("pag")
+	for _, p := range doc.payments {
+		x.open("detPag")
+		if p.indPag != "" {
+			x.element("indPag", p.indPag)
+		}
+		x.element("tPag", paymentSpecs[p.method].tPag)
+		x.element("vPag", p.vPag.String())
+		if p.tpIntegra != "" {
+			x.open("card")
+			x.element("tpIntegra", p.tpIntegra)
+			x.element("CNPJ", p.institution.CNPJ)
+			if p.tBand != "" {
+				x.element("tBand", p.tBand)
+			}
+			x.element("cAut", p.cAut)
+			x.close("card")
+		}
+		x.close("detPag")
+	}
+	if doc.vChange > 0 {
+		x.element("vTroco", doc.vChange.String())
+	}
+	x.close("pag")
+	return x.String()
+}
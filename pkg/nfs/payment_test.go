@@ -0,0 +1,120 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+func TestParsePaymentMethod(t *testing.T) {
+	for method := PaymentRandom; method <= PaymentNone; method++ {
+		parsed, err := ParsePaymentMethod(method.String())
+		if err != nil || parsed != method {
+			t.Errorf("Expected %v, got %v (%v)", method, parsed, err)
+		}
+	}
+	if parsed, err := ParsePaymentMethod("PIX"); err != nil || parsed != PaymentPIX {
+		t.Errorf("Expected PaymentPIX, got %v (%v)", parsed, err)
+	}
+	if _, err := ParsePaymentMethod("Cheque"); err == nil {
+		t.Errorf("Expected an error for an unknown method")
+	}
+}
+
+func TestPaymentInstitutions(t *testing.T) {
+	for _, institution := range append(cardAcquirers, pixProviders...) {
+		if !br_documents.ValidateCNPJ(institution.CNPJ) {
+			t.Errorf("Expected a valid CNPJ of %s, got %s", institution.name, institution.CNPJ)
+		}
+	}
+}
+
+func TestApplyPayments(t *testing.T) {
+	methods := []PaymentMethod{PaymentCash, PaymentCreditCard, PaymentDebitCard, PaymentBoleto, PaymentPIX, PaymentRandom}
+	change := false
+	for i := 0; i < 100; i++ {
+		doc, _ := newMockDocument(NFe, &generationConfig{payments: methods})
+		if len(doc.payments) != len(methods) {
+			t.Fatalf("Expected a payment of each method, got %d", len(doc.payments))
+		}
+
+		var paid cents
+		for j, p := range doc.payments {
+			if methods[j] != PaymentRandom && p.method != methods[j] {
+				t.Errorf("Expected %v, got %v", methods[j], p.method)
+			}
+			if p.vPag <= 0 {
+				t.Errorf("Expected a positive payment, got %v", p.vPag)
+			}
+			card := p.method == PaymentCreditCard || p.method == PaymentDebitCard || p.method == PaymentPIX
+			if card != (p.tpIntegra != "") || (p.method == PaymentPIX) != (len(p.cAut) == 32) {
+				t.Errorf("Expected the card group for cards and PIX only, got %+v", p)
+			}
+			if (p.method == PaymentBoleto && p.indPag != "1") || (p.method == PaymentCash && p.indPag != "0") {
+				t.Errorf("Expected %v paid in installments for boletos only, got %s", p.method, p.indPag)
+			}
+			paid += p.vPag
+		}
+		if paid-doc.vChange != doc.vNF {
+			t.Errorf("Expected payments of %v with change %v to cover vNF %v", paid, doc.vChange, doc.vNF)
+		}
+		if doc.vChange > 0 {
+			change = true
+		}
+	}
+	if !change {
+		t.Errorf("Expected change on some cash payments")
+	}
+}
+
+func TestApplyPayments_WithoutPayment(t *testing.T) {
+	for _, tt := range []TemplateType{NFeDevolucao, NFeAjuste} {
+		doc, _ := newMockDocument(tt, &generationConfig{})
+		if len(doc.payments) != 1 || doc.payments[0].method != PaymentNone || doc.payments[0].vPag != 0 || doc.payments[0].indPag != "" {
+			t.Errorf("Expected no payment in %v documents, got %+v", tt, doc.payments)
+		}
+	}
+}
+
+func TestWithPayments(t *testing.T) {
+	for _, tt := range []TemplateType{NFe, NFCe} {
+		generator, err := NewTemplateGenerator(tt)
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		for i := 0; i < 20; i++ {
+			xmlBytes, err := generator.Generate(WithPayments(PaymentCash, PaymentPIX, PaymentCreditCard))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			document := string(xmlBytes)
+			if strings.Count(document, "<detPag>") != 3 || strings.Count(document, "<card>") != 2 {
+				t.Errorf("Expected three payments, two of them with the card group\n%s", document)
+			}
+			if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+				t.Fatalf("Expected no schema violations, got %v", errs)
+			}
+		}
+	}
+}
+
+func TestWithPayments_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator TemplateGenerator
+		methods   []PaymentMethod
+	}{
+		{"CFe", NewCFeGenerator(), []PaymentMethod{PaymentCash}},
+		{"payment of a return", NewNFeDevolucaoGenerator(), []PaymentMethod{PaymentPIX}},
+		{"no payment with others", NewNFeGenerator(), []PaymentMethod{PaymentNone, PaymentCash}},
+		{"unknown method", NewNFeGenerator(), []PaymentMethod{PaymentMethod(99)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.generator.Generate(WithPayments(tt.methods...)); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package nfs
+
+import (
+	"fmt"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// PISSituation is the group of the PIS and COFINS of an item, which share the same CST.
+type PISSituation int
+
+const (
+	// PISRandom picks a situation allowed for the emitter regime and the operation.
+	PISRandom PISSituation = iota
+	// PISAliq is tributável com alíquota básica (CST 01), over the item value.
+	PISAliq
+	// PISQtde is tributável por quantidade vendida (CST 03), e.g. beverages and fuels.
+	PISQtde
+	// PISNT is não tributável: monofásica, alíquota zero, isenta, sem incidência or suspensão (CST 04, 06 to 09).
+	PISNT
+	// PISOutr is outras operações: CST 49 or 99 on exits and 98 on entries.
+	PISOutr
+	// PISST is tributável por substituição tributária (CST 05), with the PISST and COFINSST groups.
+	PISST
+)
+
+// pisSpec describes a PISSituation: its name and whether it is available to the regular regime
+// only (CRT 3), as the Simples Nacional collects PIS and COFINS in the DAS.
+type pisSpec struct {
+	name    string
+	regular bool
+}
+
+// pisSpecs holds the spec of each PISSituation.
+var pisSpecs = map[PISSituation]pisSpec{
+	PISAliq: {name: "PISAliq", regular: true},
+	PISQtde: {name: "PISQtde", regular: true},
+	PISNT:   {name: "PISNT"},
+	PISOutr: {name: "PISOutr"},
+	PISST:   {name: "PISST", regular: true},
+}
+
+// pisNTCSTs are the CSTs of the PISNT group: 04 monofásica, 06 alíquota zero, 07 isenta,
+// 08 sem incidência and 09 suspensão.
+var pisNTCSTs = []string{"04", "06", "07", "08", "09"}
+
+// pisQtdeRates are the PIS and COFINS rates per unit of the goods taxed by quantity, in
+// ten-thousandths of real.
+var pisQtdeRates = []struct{ pis, cofins int }{
+	{pis: 186, cofins: 857},
+	{pis: 460, cofins: 2130},
+	{pis: 1150, cofins: 5300},
+}
+
+// String returns the name of the PISSituation.
+func (s PISSituation) String() string {
+	if s == PISRandom {
+		return "PISRandom"
+	}
+	if spec, ok := pisSpecs[s]; ok {
+		return spec.name
+	}
+	return "Unknown"
+}
+
+// ParsePISSituation converts a string (e.g. "PISAliq" or "PISST") to a PISSituation.
+func ParsePISSituation(s string) (PISSituation, error) {
+	if s == "PISRandom" {
+		return PISRandom, nil
+	}
+	for situation, spec := range pisSpecs {
+		if spec.name == s {
+			return situation, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid PISSituation: %s", s)
+}
+
+// checkPISSituations fails when the situations cannot be issued together with the ICMS situations
+// in a document of the TemplateType.
+func checkPISSituations(tt TemplateType, situations []PISSituation, icms []ICMSSituation) error {
+	if len(situations) == 0 {
+		return nil
+	}
+	if tt == CFe {
+		return fmt.Errorf("PIS situations are not supported for CFe documents")
+	}
+	if len(situations) > 990 {
+		return fmt.Errorf("too many items: %d (the maximum is 990)", len(situations))
+	}
+	simples, _ := simplesNacional(icms)
+	for _, situation := range situations {
+		spec, ok := pisSpecs[situation]
+		if !ok && situation != PISRandom {
+			return fmt.Errorf("unknown PIS situation: %d", situation)
+		}
+		if tt == NFeDevolucao && situation != PISRandom && situation != PISOutr {
+			return fmt.Errorf("%v is an exit situation; entries take PISOutr", situation)
+		}
+		if spec.regular && simples {
+			return fmt.Errorf("%v requires the regular regime (CRT 3), but the ICMS situations are CSOSN", situation)
+		}
+	}
+	return nil
+}
+
+// regularPIS reports whether any of the situations requires the regular regime.
+func regularPIS(situations []PISSituation) bool {
+	for _, situation := range situations {
+		if pisSpecs[situation].regular {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultPISSituation returns the PIS situation of the items of the document when none is chosen:
+// the basic rate on domestic sales of the regular regime, no incidence on exports and other
+// operations otherwise.
+func defaultPISSituation(doc *mockDocument) PISSituation {
+	if doc.operation == OperationExportacao {
+		return PISNT
+	}
+	if doc.CRT == "3" && doc.tpNF == "1" && (doc.operation == OperationDefault || operationSpecs[doc.operation].sale) {
+		return PISAliq
+	}
+	return PISOutr
+}
+
+// randomPISSituation picks a situation for the regime and the operation. Entries take PISOutr only.
+func randomPISSituation(doc *mockDocument) PISSituation {
+	if doc.tpNF == "0" {
+		return PISOutr
+	}
+	var candidates []PISSituation
+	for situation := PISAliq; situation <= PISST; situation++ {
+		if pisSpecs[situation].regular && doc.CRT != "3" {
+			continue
+		}
+		candidates = append(candidates, situation)
+	}
+	return candidates[gofakeit.Number(0, len(candidates)-1)]
+}
+
+// calculatePIS sets the CST and values of the PIS and COFINS of the item for its situation, at the
+// rates of the emitter regime (zero in the Simples Nacional).
+func (i *mockItem) calculatePIS(doc *mockDocument) {
+	switch i.pis {
+	case PISAliq:
+		i.pisCST = "01"
+		i.pPIS, i.pCOFINS = doc.pPIS, doc.pCOFINS
+	case PISQtde:
+		i.pisCST = "03"
+		rates := pisQtdeRates[gofakeit.Number(0, len(pisQtdeRates)-1)]
+		i.vAliqProdPIS, i.vAliqProdCOFINS = rates.pis, rates.cofins
+		i.vPIS = perUnit(i.quantity, i.vAliqProdPIS)
+		i.vCOFINS = perUnit(i.quantity, i.vAliqProdCOFINS)
+		return
+	case PISNT:
+		i.pisCST = pisNTCSTs[gofakeit.Number(0, len(pisNTCSTs)-1)]
+		if doc.idDest == "3" && doc.tpNF == "1" {
+			i.pisCST = "08" // exports are outside the incidence of PIS and COFINS
+		}
+		return
+	case PISST:
+		i.pisCST = "05"
+		i.pPISST, i.pCOFINSST = doc.pPIS, doc.pCOFINS
+		i.vPISST = i.net().applyRate(i.pPISST)
+		i.vCOFINSST = i.net().applyRate(i.pCOFINSST)
+		return
+	default:
+		i.pisCST = "49"
+		if doc.tpNF == "0" {
+			i.pisCST = "98"
+		} else if doc.CRT == "3" {
+			i.pisCST = "99"
+		}
+		i.pPIS, i.pCOFINS = doc.pPIS, doc.pCOFINS
+	}
+	i.vPIS = i.net().applyRate(i.pPIS)
+	i.vCOFINS = i.net().applyRate(i.pCOFINS)
+}
+
+// perUnit returns the tax of a quantity in ten-thousandths at a rate per unit in ten-thousandths of real.
+func perUnit(quantity int64, rate int) cents {
+	return cents((quantity*int64(rate) + 500000) / 1000000)
+}
+
+// pisGroupXML renders the PIS or COFINS group of the item (tax is "PIS" or "COFINS"): Aliq for the
+// basic rate, Qtde for the rate per unit, NT for the untaxed CSTs (including ST, taxed in the ST
+// group) and Outr for the other operations.
+func (i *mockItem) pisGroupXML(tax string) string {
+	rateName, valueName := "p"+tax, "v"+tax
+	rate, value, aliqProd := i.pPIS, i.vPIS, i.vAliqProdPIS
+	if tax == "COFINS" {
+		rate, value, aliqProd = i.pCOFINS, i.vCOFINS, i.vAliqProdCOFINS
+	}
+
+	var group string
+	elements := []xmlElement{{"CST", i.pisCST}}
+	switch i.pis {
+	case PISAliq:
+		group = tax + "Aliq"
+		elements = append(elements, xmlElement{"vBC", i.pisBase().String()}, xmlElement{rateName, formatRate(rate, 4)})
+	case PISQtde:
+		group = tax + "Qtde"
+		elements = append(elements, xmlElement{"qBCProd", formatQuantity(i.quantity)}, xmlElement{"vAliqProd", formatQuantity(int64(aliqProd))})
+	case PISNT, PISST:
+		group = tax + "NT"
+	default:
+		group = tax + "Outr"
+		elements = append(elements, xmlElement{"vBC", i.pisBase().String()}, xmlElement{rateName, formatRate(rate, 4)})
+	}
+	if group != tax+"NT" {
+		elements = append(elements, xmlElement{valueName, value.String()})
+	}
+
+	return xmlGroup(group, elements)
+}
+
+// pisSTGroupXML renders the PISST or COFINSST group of the item, which is empty unless the item
+// is taxed by substitution. The ST value is not added to the document total (indSoma 0).
+func (i *mockItem) pisSTGroupXML(tax string) string {
+	if i.pis != PISST {
+		return ""
+	}
+	rate, value := i.pPISST, i.vPISST
+	if tax == "COFINS" {
+		rate, value = i.pCOFINSST, i.vCOFINSST
+	}
+	return xmlGroup(tax+"ST", []xmlElement{
+		{"vBC", i.net().String()},
+		{"p" + tax, formatRate(rate, 4)},
+		{"v" + tax, value.String()},
+		{"indSoma" + tax + "ST", "0"},
+	})
+}
@@ -0,0 +1,88 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithPIS_Groups(t *testing.T) {
+	tests := []struct {
+		situation PISSituation
+		groups    []string
+	}{
+		{PISAliq, []string{"<PISAliq>", "<COFINSAliq>", "<CST>01</CST>"}},
+		{PISQtde, []string{"<PISQtde>", "<COFINSQtde>", "<qBCProd>", "<CST>03</CST>"}},
+		{PISNT, []string{"<PISNT>", "<COFINSNT>"}},
+		{PISOutr, []string{"<PISOutr>", "<COFINSOutr>", "<CST>99</CST>"}},
+		{PISST, []string{"<PISNT>", "<CST>05</CST>", "<PISST>", "<COFINSST>", "<indSomaPISST>0</indSomaPISST>"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.situation.String(), func(t *testing.T) {
+			xmlBytes, err := NewNFeGenerator().Generate(WithPIS(tc.situation))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for _, group := range tc.groups {
+				if !strings.Contains(string(xmlBytes), group) {
+					t.Errorf("Expected %s for %v", group, tc.situation)
+				}
+			}
+			if !strings.Contains(string(xmlBytes), "<CRT>3</CRT>") {
+				t.Errorf("Expected a regular regime emitter")
+			}
+			if errs := Validate(NFe, xmlBytes); len(errs) > 0 {
+				t.Errorf("Expected no schema violations, got %v", errs)
+			}
+		})
+	}
+}
+
+func TestWithPIS_Regime(t *testing.T) {
+	xmlBytes, err := NewNFCeGenerator().Generate(WithPIS(PISAliq))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(string(xmlBytes), "<CRT>3</CRT>") || !strings.Contains(string(xmlBytes), "<ICMS00>") {
+		t.Errorf("Expected PISAliq to be issued by a regular regime emitter")
+	}
+
+	xmlBytes, err = NewNFCeGenerator().Generate(WithICMS(CSOSN102), WithPIS(PISRandom, PISRandom, PISRandom))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(string(xmlBytes), "<PISAliq>") || strings.Contains(string(xmlBytes), "<PISQtde>") {
+		t.Errorf("Expected no regular regime PIS for a Simples Nacional emitter")
+	}
+}
+
+func TestWithPIS_Errors(t *testing.T) {
+	if _, err := NewNFeGenerator().Generate(WithICMS(CSOSN102), WithPIS(PISAliq)); err == nil {
+		t.Errorf("Expected an error for PISAliq in the Simples Nacional")
+	}
+	if _, err := NewNFeDevolucaoGenerator().Generate(WithPIS(PISNT)); err == nil {
+		t.Errorf("Expected an error for an exit situation on an entry")
+	}
+	if _, err := NewCFeGenerator().Generate(WithPIS(PISAliq)); err == nil {
+		t.Errorf("Expected an error for a CFe document")
+	}
+}
+
+func TestPerUnit(t *testing.T) {
+	// 12.5 units at R$ 0.0460 per unit
+	if got := perUnit(125000, 460); got != 58 {
+		t.Errorf("Expected 0.58, got %v", got)
+	}
+}
+
+func TestParsePISSituation(t *testing.T) {
+	for _, name := range []string{"PISRandom", "PISQtde", "PISST"} {
+		situation, err := ParsePISSituation(name)
+		if err != nil || situation.String() != name {
+			t.Errorf("Expected %s, got %v (%v)", name, situation, err)
+		}
+	}
+	if _, err := ParsePISSituation("PISXX"); err == nil {
+		t.Errorf("Expected an error for an unknown situation")
+	}
+}
@@ -0,0 +1,170 @@
+package nfs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mayckol/brfiscalfaker/pkg/br_locale"
+)
+
+// RecipientKind is how the recipient (dest) of the document is identified.
+type RecipientKind int
+
+const (
+	// RecipientDefault keeps the recipient of the template: a company (CNPJ) in NF-e documents and a
+	// person (CPF) in NFC-e, CF-e and NFeDevolucao documents.
+	RecipientDefault RecipientKind = iota
+	// RecipientCPF is a person, who is not an ICMS contributor (indIEDest 9).
+	RecipientCPF
+	// RecipientCNPJ is a company, with its IE when it is an ICMS contributor.
+	RecipientCNPJ
+	// RecipientForeign is identified by a foreign document (idEstrangeiro): the recipient abroad of an
+	// NF-e export, or a foreign consumer without a Brazilian address in an NFC-e.
+	RecipientForeign
+	// RecipientNone is the anonymous consumer of NFC-e and CF-e documents, which have no dest group.
+	RecipientNone
+)
+
+// recipientNames are the names of the RecipientKinds.
+var recipientNames = map[RecipientKind]string{
+	RecipientDefault: "RecipientDefault",
+	RecipientCPF:     "RecipientCPF",
+	RecipientCNPJ:    "RecipientCNPJ",
+	RecipientForeign: "RecipientForeign",
+	RecipientNone:    "RecipientNone",
+}
+
+// String returns the name of the RecipientKind.
+func (k RecipientKind) String() string {
+	if name, ok := recipientNames[k]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// ParseRecipientKind converts a string (e.g. "RecipientCNPJ" or "CNPJ") to a RecipientKind.
+func ParseRecipientKind(s string) (RecipientKind, error) {
+	name := "Recipient" + strings.TrimPrefix(s, "Recipient")
+	for kind, kindName := range recipientNames {
+		if strings.EqualFold(kindName, name) {
+			return kind, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid RecipientKind: %s", s)
+}
+
+// defaultRecipient returns the kind of the recipient of the template.
+func defaultRecipient(tt TemplateType) RecipientKind {
+	switch tt {
+	case NFCe, CFe, NFeDevolucao:
+		return RecipientCPF
+	default:
+		return RecipientCNPJ
+	}
+}
+
+// checkRecipientKind fails when the recipient cannot be identified that way in a document of the
+// TemplateType with the operation. Only NFC-e and CF-e documents may have no recipient, CF-e
+// recipients have a CPF or CNPJ only, and NF-e foreign recipients are exports.
+func checkRecipientKind(tt TemplateType, cfg *generationConfig) error {
+	kind := cfg.recipient
+	if kind == RecipientDefault {
+		return nil
+	}
+	if _, ok := recipientNames[kind]; !ok {
+		return fmt.Errorf("unknown recipient kind: %d", kind)
+	}
+	if cfg.referencedInvoice != nil {
+		return fmt.Errorf("%v is not supported with a referenced invoice, whose recipient is kept", kind)
+	}
+	switch kind {
+	case RecipientNone:
+		if tt != NFCe && tt != CFe {
+			return fmt.Errorf("%v is not supported for %v documents", kind, tt)
+		}
+	case RecipientForeign:
+		if tt != NFe && tt != NFCe {
+			return fmt.Errorf("%v is not supported for %v documents", kind, tt)
+		}
+		if tt == NFe && cfg.operation == OperationDefault {
+			if cfg.marketplace {
+				return fmt.Errorf("%v is not supported with marketplaces", kind)
+			}
+			return checkOperation(tt, OperationExportacao, cfg)
+		}
+	}
+
+	spec, ok := operationSpecs[cfg.operation]
+	switch {
+	case !ok:
+		return nil
+	case (spec.location == abroad) != (kind == RecipientForeign):
+		return fmt.Errorf("%v is not supported for %v", kind, cfg.operation)
+	case kind == RecipientCPF && spec.contributor:
+		return fmt.Errorf("%v is not supported for %v, whose recipient is an ICMS contributor", kind, cfg.operation)
+	}
+	return nil
+}
+
+// applyRecipient identifies the recipient of the kind, with the indicator of IE contributor
+// (indIEDest) and the IE matching it: people and foreign consumers are not ICMS contributors and
+// companies may be one in NF-e documents only. Recipients abroad of NF-e documents come from the
+// export operation.
+func (doc *mockDocument) applyRecipient(kind RecipientKind) {
+	doc.recipient = kind
+	switch kind {
+	case RecipientCPF:
+		doc.dest.CNPJ = ""
+		doc.destName = br_locale.PersonName()
+		doc.indIEDest = "9"
+		if doc.model == "55" {
+			doc.indFinal = "1" // a person buys for their own use
+		}
+	case RecipientCNPJ:
+		doc.dest.CPF = ""
+		doc.destName = xNome()
+		if doc.model != "55" {
+			doc.indIEDest = "9"
+		}
+	case RecipientForeign:
+		doc.destName = xNome()
+		if doc.templateType == NFCe {
+			// A foreign tourist, identified by the passport and without an address in Brazil.
+			doc.dest = mockParty{idEstrangeiro: strings.ToUpper(gofakeit.Lexify("??")) + gofakeit.Numerify("#######"), city: doc.dest.city}
+			doc.destName = br_locale.PersonName()
+			doc.indIEDest = "9"
+		}
+	case RecipientNone:
+		doc.dest = mockParty{city: doc.dest.city} // the consumer buys at the emitter city
+		doc.destName = ""
+		doc.indIEDest = ""
+	}
+	if doc.indIEDest != "1" {
+		doc.dest.IE = ""
+	}
+	doc.destEmail = ""
+	if doc.destName != "" {
+		doc.destEmail = email(doc.destName)
+	}
+	if doc.tpAmb == "2" && doc.templateType != CFe && kind != RecipientNone {
+		doc.destName = homologationRecipientName
+	}
+}
+
+// omittedGroups returns the groups that are left out of the document: the dest group of the
+// anonymous consumer, the address of the foreign consumer of an NFC-e and the authorization
+// protocol of an NFC-e issued offline.
+func (doc *mockDocument) omittedGroups() []string {
+	var groups []string
+	switch {
+	case doc.recipient == RecipientNone && doc.templateType != CFe:
+		groups = append(groups, "dest")
+	case doc.recipient == RecipientForeign && doc.templateType == NFCe:
+		groups = append(groups, "enderDest")
+	}
+	if doc.offline() {
+		groups = append(groups, "protNFe")
+	}
+	return groups
+}
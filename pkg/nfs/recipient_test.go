@@ -0,0 +1,105 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRecipientKind(t *testing.T) {
+	for kind := RecipientDefault; kind <= RecipientNone; kind++ {
+		parsed, err := ParseRecipientKind(kind.String())
+		if err != nil || parsed != kind {
+			t.Errorf("Expected %v, got %v (%v)", kind, parsed, err)
+		}
+	}
+	if parsed, err := ParseRecipientKind("Foreign"); err != nil || parsed != RecipientForeign {
+		t.Errorf("Expected RecipientForeign, got %v (%v)", parsed, err)
+	}
+	if _, err := ParseRecipientKind("Passport"); err == nil {
+		t.Errorf("Expected an error for an unknown kind")
+	}
+}
+
+func TestWithRecipientKind(t *testing.T) {
+	tests := []struct {
+		tt       TemplateType
+		kind     RecipientKind
+		expected []string
+		absent   []string
+	}{
+		{NFe, RecipientCPF, []string{"<dest>\n<CPF>", "<indIEDest>9</indIEDest>", "<indFinal>1</indFinal>"}, []string{"<dest>\n<CNPJ>", "<idEstrangeiro>"}},
+		{NFe, RecipientCNPJ, []string{"<dest>\n<CNPJ>"}, []string{"<idEstrangeiro>"}},
+		{NFe, RecipientForeign, []string{"<idEstrangeiro>", "<UF>EX</UF>", "<idDest>3</idDest>", "<indIEDest>9</indIEDest>"}, []string{"<dest>\n<CNPJ>"}},
+		{NFCe, RecipientCPF, []string{"<CPF>", "<indIEDest>9</indIEDest>", "<enderDest>"}, []string{"<idEstrangeiro>"}},
+		{NFCe, RecipientCNPJ, []string{"<dest>\n        <CNPJ>", "<indIEDest>9</indIEDest>"}, []string{"<dest>\n        <CPF>"}},
+		{NFCe, RecipientForeign, []string{"<idEstrangeiro>", "<idDest>1</idDest>", "<indIEDest>9</indIEDest>"}, []string{"<enderDest>", "<CPF>"}},
+		{NFCe, RecipientNone, nil, []string{"<dest>", "<indIEDest>"}},
+		{NFeDevolucao, RecipientCNPJ, []string{"<dest>\n        <CNPJ>"}, []string{"<dest>\n        <CPF>"}},
+		{CFe, RecipientCNPJ, []string{"<dest>\n<CNPJ>"}, []string{"<dest>\n<CPF>"}},
+		{CFe, RecipientNone, []string{"<dest>\n</dest>"}, []string{"<xNome>" + homologationRecipientName}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.tt.String()+"/"+tc.kind.String(), func(t *testing.T) {
+			generator, err := NewTemplateGenerator(tc.tt)
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			for i := 0; i < 10; i++ {
+				xmlBytes, err := generator.Generate(WithRecipientKind(tc.kind))
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				document := string(xmlBytes)
+				for _, element := range tc.expected {
+					if !strings.Contains(document, element) {
+						t.Errorf("Expected %q in the document\n%s", element, document)
+					}
+				}
+				for _, element := range tc.absent {
+					if strings.Contains(document, element) {
+						t.Errorf("Expected no %q in the document\n%s", element, document)
+					}
+				}
+				if errs := Validate(tc.tt, xmlBytes); len(errs) > 0 {
+					t.Fatalf("Expected no schema violations, got %v", errs)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyRecipient_IEDest(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		doc, _ := newMockDocument(NFe, &generationConfig{recipient: RecipientCNPJ})
+		if doc.dest.CPF != "" || (doc.indIEDest == "1") != (doc.dest.IE != "") {
+			t.Errorf("Expected a company with the IE of contributors only, got %+v with indIEDest %s", doc.dest, doc.indIEDest)
+		}
+		doc, _ = newMockDocument(NFe, &generationConfig{recipient: RecipientCPF})
+		if doc.dest.CNPJ != "" || doc.dest.IE != "" || doc.indIEDest != "9" {
+			t.Errorf("Expected a person who is not a contributor, got %+v with indIEDest %s", doc.dest, doc.indIEDest)
+		}
+	}
+}
+
+func TestWithRecipientKind_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator TemplateGenerator
+		options   []Option
+	}{
+		{"NF-e without recipient", NewNFeGenerator(), []Option{WithRecipientKind(RecipientNone)}},
+		{"foreign CF-e", NewCFeGenerator(), []Option{WithRecipientKind(RecipientForeign)}},
+		{"foreign return", NewNFeDevolucaoGenerator(), []Option{WithRecipientKind(RecipientForeign)}},
+		{"person of a transfer", NewNFeGenerator(), []Option{WithRecipientKind(RecipientCPF), WithOperation(OperationTransferencia)}},
+		{"company abroad", NewNFeGenerator(), []Option{WithRecipientKind(RecipientCNPJ), WithOperation(OperationExportacao)}},
+		{"foreign domestic sale", NewNFeGenerator(), []Option{WithRecipientKind(RecipientForeign), WithOperation(OperationVendaInterna)}},
+		{"unknown kind", NewNFeGenerator(), []Option{WithRecipientKind(RecipientKind(99))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.generator.Generate(tt.options...); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
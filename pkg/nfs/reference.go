@@ -0,0 +1,243 @@
+package nfs
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// invoiceKeyRe matches the access key in the Id of a generated document.
+var invoiceKeyRe = regexp.MustCompile(`Id="(?:NFe|CFe)([0-9]{44})"`)
+
+// Invoice is a generated document that others may reference: its TemplateType, its access key and its XML.
+type Invoice struct {
+	TemplateType TemplateType
+	AccessKey    string
+	XML          []byte
+}
+
+// GenerateInvoice generates a document of the TemplateType as an Invoice, which returns, complements
+// and adjustments reference with WithReferenced.
+func GenerateInvoice(tt TemplateType, options ...Option) (*Invoice, error) {
+	generator, err := NewTemplateGenerator(tt)
+	if err != nil {
+		return nil, err
+	}
+	xmlBytes, err := generator.Generate(options...)
+	if err != nil {
+		return nil, err
+	}
+	match := invoiceKeyRe.FindSubmatch(xmlBytes)
+	if match == nil {
+		return nil, fmt.Errorf("the %v document has no access key", tt)
+	}
+	return &Invoice{TemplateType: tt, AccessKey: string(match[1]), XML: xmlBytes}, nil
+}
+
+// referencedInvoice holds what a document takes from the NF-e or NFC-e it references: the access
+// key, the parties and the items.
+type referencedInvoice struct {
+	accessKey string
+	model     string
+	dhEmi     time.Time
+	CRT       string
+	emit      mockParty
+	emitName  string
+	dest      *mockParty // nil when the original has no recipient
+	destName  string
+	indIEDest string
+	items     []referencedItem
+}
+
+// referencedItem is an item of the referenced document.
+type referencedItem struct {
+	code      string
+	product   catalogProduct
+	gtin      string
+	quantity  int64
+	unitValue cents
+}
+
+// parseReferencedInvoice reads the NF-e or NFC-e referenced by a document of the TemplateType.
+// Only normal exits (tpNF 1, finNFe 1) issued in Brazil to a recipient in Brazil can be returned,
+// and only NF-e can be complemented or adjusted.
+func parseReferencedInvoice(tt TemplateType, document []byte) (*referencedInvoice, error) {
+	if tt != NFeDevolucao && tt != NFeComplementar && tt != NFeAjuste {
+		return nil, fmt.Errorf("referenced invoices are not supported for %v documents", tt)
+	}
+	root, err := parseXMLTree(document)
+	if err != nil {
+		return nil, fmt.Errorf("malformed referenced invoice: %v", err)
+	}
+	infNFe := root.find("infNFe")
+	if infNFe == nil {
+		return nil, fmt.Errorf("the referenced invoice is not an NF-e or NFC-e")
+	}
+
+	ref := &referencedInvoice{
+		accessKey: strings.TrimPrefix(infNFe.attrs["Id"], "NFe"),
+		model:     text(infNFe.find("ide", "mod")),
+		CRT:       text(infNFe.find("emit", "CRT")),
+		emit:      partyOf(infNFe.child("emit"), "enderEmit"),
+		emitName:  text(infNFe.find("emit", "xNome")),
+	}
+	if len(ref.accessKey) != 44 {
+		return nil, fmt.Errorf("the referenced invoice has no access key")
+	}
+	if tt != NFeDevolucao && ref.model != "55" {
+		return nil, fmt.Errorf("the referenced invoice is not an NF-e")
+	}
+	if text(infNFe.find("ide", "tpNF")) != "1" || text(infNFe.find("ide", "finNFe")) != "1" {
+		return nil, fmt.Errorf("the referenced invoice is not a normal exit (tpNF 1, finNFe 1)")
+	}
+	ref.dhEmi, err = time.Parse(dateTimeLayout, text(infNFe.find("ide", "dhEmi")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid dhEmi in the referenced invoice: %v", err)
+	}
+	if dest := infNFe.child("dest"); dest != nil {
+		party := partyOf(dest, "enderDest")
+		if party.idEstrangeiro != "" || party.city.uf == exterior.uf {
+			return nil, fmt.Errorf("references to invoices issued abroad are not supported")
+		}
+		ref.dest, ref.destName, ref.indIEDest = &party, text(dest.child("xNome")), text(dest.child("indIEDest"))
+	}
+
+	for _, det := range infNFe.children {
+		if det.name != "det" {
+			continue
+		}
+		prod := det.child("prod")
+		item := referencedItem{
+			code: text(prod.child("cProd")),
+			gtin: text(prod.child("cEAN")),
+			product: catalogProduct{
+				ncm:         text(prod.child("NCM")),
+				cest:        text(prod.child("CEST")),
+				description: text(prod.child("xProd")),
+				unit:        text(prod.child("uCom")),
+			},
+		}
+		if item.gtin == "SEM GTIN" {
+			item.gtin = ""
+		}
+		quantity, err := strconv.ParseFloat(text(prod.child("qCom")), 64)
+		if err != nil || quantity <= 0 {
+			return nil, fmt.Errorf("invalid qCom in item %s of the referenced invoice", det.attrs["nItem"])
+		}
+		unitValue, err := strconv.ParseFloat(text(prod.child("vUnCom")), 64)
+		if err != nil || unitValue <= 0 {
+			return nil, fmt.Errorf("invalid vUnCom in item %s of the referenced invoice", det.attrs["nItem"])
+		}
+		item.quantity = int64(math.Round(quantity * 10000))
+		item.unitValue = cents(math.Round(unitValue * 100))
+		item.product.fractional = item.quantity%10000 != 0
+		ref.items = append(ref.items, item)
+	}
+	if len(ref.items) == 0 {
+		return nil, fmt.Errorf("the referenced invoice has no items")
+	}
+	if len(ref.items) > 990 {
+		return nil, fmt.Errorf("too many items: %d (the maximum is 990)", len(ref.items))
+	}
+	return ref, nil
+}
+
+// partyOf reads the identification and location of a party of the referenced document.
+func partyOf(node *xmlNode, address string) mockParty {
+	return mockParty{
+		CNPJ:          text(node.child("CNPJ")),
+		CPF:           text(node.child("CPF")),
+		IE:            text(node.child("IE")),
+		idEstrangeiro: text(node.child("idEstrangeiro")),
+		city: municipality{
+			code: text(node.find(address, "cMun")),
+			name: text(node.find(address, "xMun")),
+			uf:   text(node.find(address, "UF")),
+		},
+	}
+}
+
+// text returns the trimmed text of the node, or "" when it is missing.
+func text(node *xmlNode) string {
+	return strings.TrimSpace(node.textOr(""))
+}
+
+// applyReference makes the document return, complement or adjust the goods of the referenced invoice.
+// A recipient that is an ICMS contributor issues the return itself, as an exit to the original emitter
+// (CFOP 5202 or 6202); otherwise the original emitter issues it as an entry of the goods (CFOP 1202 or
+// 2202). Complements and adjustments are issued by the original emitter to the same recipient.
+func (doc *mockDocument) applyReference(ref *referencedInvoice) {
+	doc.refNFe = ref.accessKey
+	if ref.returnedByRecipient(doc.templateType) {
+		doc.emit = *ref.dest
+		if ref.destName != homologationRecipientName {
+			doc.emitName = ref.destName
+		}
+		doc.dest = ref.emit
+		if doc.dest.CNPJ != "" {
+			doc.dest.CPF = ""
+		}
+		if doc.tpAmb != "2" {
+			doc.destName = ref.emitName
+		}
+		doc.tpNF = "1"
+		doc.indFinal = "0"
+		doc.indIEDest = "1"
+		doc.natOp = "Devolucao de compra para comercializacao"
+		return
+	}
+
+	doc.emit, doc.emitName = ref.emit, ref.emitName
+	if ref.CRT == "1" || ref.CRT == "3" {
+		doc.CRT = ref.CRT
+	}
+	if ref.dest != nil {
+		doc.dest = *ref.dest
+		if doc.tpAmb != "2" {
+			doc.destName = ref.destName
+		}
+		if doc.dest.CPF != "" {
+			doc.dest.CNPJ = ""
+		}
+	}
+	if doc.complementary() && ref.indIEDest != "" {
+		doc.indIEDest = ref.indIEDest
+		if ref.indIEDest != "1" {
+			doc.indFinal = "1"
+		}
+	}
+}
+
+// returnItem makes the item return the referenced item, with the percentage of its quantity
+// (whole units for goods sold by the unit) and the same unit value.
+func (i *mockItem) returnItem(ref referencedItem, percent int) {
+	i.code, i.product, i.gtin, i.unitValue = ref.code, ref.product, ref.gtin, ref.unitValue
+	i.quantity = (ref.quantity*int64(percent) + 50) / 100
+	if !ref.product.fractional {
+		i.quantity = max(i.quantity/10000, 1) * 10000
+	}
+	i.pDevol = int(i.quantity * 10000 / ref.quantity)
+}
+
+// referenceItem makes the item complement or adjust the referenced item, with its product and value.
+func (i *mockItem) referenceItem(ref referencedItem) {
+	i.code, i.product, i.gtin = ref.code, ref.product, ref.gtin
+	i.quantity, i.unitValue = ref.quantity, ref.unitValue
+}
+
+// returnedByRecipient reports whether the recipient of the referenced invoice issues the document of
+// the TemplateType: the return of goods bought by an ICMS contributor.
+func (ref *referencedInvoice) returnedByRecipient(tt TemplateType) bool {
+	return tt == NFeDevolucao && ref.dest != nil && ref.indIEDest == "1" && ref.dest.CNPJ != ""
+}
+
+// emitterUF returns the state of the emitter of the document of the TemplateType that references the invoice.
+func (ref *referencedInvoice) emitterUF(tt TemplateType) string {
+	if ref.returnedByRecipient(tt) {
+		return ref.dest.city.uf
+	}
+	return ref.emit.city.uf
+}
@@ -0,0 +1,271 @@
+package nfs
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// firstMatch returns the first submatch of the pattern in the document.
+func firstMatch(document, pattern string) string {
+	match := regexp.MustCompile(pattern).FindStringSubmatch(document)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func TestWithReferencedInvoice_Contributor(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		original, err := NewNFeGenerator().Generate(WithOperation(OperationVendaInterna), WithICMS(ICMSRandom, ICMSRandom))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		xmlBytes, err := NewNFeDevolucaoGenerator().Generate(WithReferencedInvoice(original))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		sale, document := string(original), string(xmlBytes)
+
+		key := firstMatch(sale, `Id="NFe([0-9]{44})"`)
+		if !strings.Contains(document, "<refNFe>"+key+"</refNFe>") {
+			t.Errorf("Expected the reference to %s", key)
+		}
+		emitCNPJ, destCNPJ := firstMatch(sale, `<emit>\s*<CNPJ>([0-9]+)`), firstMatch(sale, `<dest>\s*<CNPJ>([0-9]+)`)
+		if firstMatch(document, `<emit>\s*<CNPJ>([0-9]+)`) != destCNPJ || firstMatch(document, `<dest>\s*<CNPJ>([0-9]+)`) != emitCNPJ {
+			t.Errorf("Expected the parties to be swapped")
+		}
+		for _, element := range []string{"<tpNF>1</tpNF>", "<finNFe>4</finNFe>", "<CFOP>5", "<pDevol>100.00</pDevol>"} {
+			if !strings.Contains(document, element) {
+				t.Errorf("Expected %s", element)
+			}
+		}
+		if strings.Count(document, "<det nItem=") != 2 || firstMatch(document, `<xProd>([^<]+)`) != firstMatch(sale, `<xProd>([^<]+)`) {
+			t.Errorf("Expected the items of the sale")
+		}
+		if errs := Validate(NFeDevolucao, xmlBytes); len(errs) > 0 {
+			t.Fatalf("Expected no schema violations, got %v", errs)
+		}
+	}
+}
+
+func TestWithReferencedInvoice_RuralProducer(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		original, err := NewNFeGenerator().Generate(WithEmitterProfile(EmitterProdutorRural), WithOperation(OperationVendaInterna))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		xmlBytes, err := NewNFeDevolucaoGenerator().Generate(WithReferencedInvoice(original))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		sale, document := string(original), string(xmlBytes)
+
+		// The producer, identified by a CPF, is the recipient of the return.
+		if cpf := firstMatch(sale, `<emit>\s*<CPF>([0-9]+)`); firstMatch(document, `<dest>\s*<CPF>([0-9]+)`) != cpf {
+			t.Errorf("Expected the recipient %s\n%s", cpf, document)
+		}
+		if errs := Validate(NFeDevolucao, xmlBytes); len(errs) > 0 {
+			t.Fatalf("Expected no schema violations, got %v\n%s", errs, document)
+		}
+	}
+}
+
+func TestWithReferencedInvoice_Consumer(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		original, err := NewNFCeGenerator().Generate()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		xmlBytes, err := NewNFeDevolucaoGenerator().Generate(WithReferencedInvoice(original), WithPartialReturn(50))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		sale, document := string(original), string(xmlBytes)
+
+		if firstMatch(document, `<emit>\s*<CNPJ>([0-9]+)`) != firstMatch(sale, `<emit>\s*<CNPJ>([0-9]+)`) {
+			t.Errorf("Expected the return to be issued by the seller")
+		}
+		if firstMatch(document, `<CPF>([0-9]+)`) != firstMatch(sale, `<CPF>([0-9]+)`) {
+			t.Errorf("Expected the consumer of the sale")
+		}
+		if !strings.Contains(document, "<tpNF>0</tpNF>") || !strings.Contains(document, "<CFOP>1") {
+			t.Errorf("Expected an entry with CFOP 1202")
+		}
+		if errs := Validate(NFeDevolucao, xmlBytes); len(errs) > 0 {
+			t.Fatalf("Expected no schema violations, got %v", errs)
+		}
+	}
+}
+
+func TestWithReferencedInvoice_Complement(t *testing.T) {
+	for _, tt := range []TemplateType{NFeComplementar, NFeAjuste} {
+		for i := 0; i < 10; i++ {
+			original, err := NewNFeGenerator().Generate(WithICMS(ICMSRandom, ICMSRandom))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			generator, _ := NewTemplateGenerator(tt)
+			xmlBytes, err := generator.Generate(WithReferencedInvoice(original))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			sale, document := string(original), string(xmlBytes)
+
+			key := firstMatch(sale, `Id="NFe([0-9]{44})"`)
+			if !strings.Contains(document, "<refNFe>"+key+"</refNFe>") {
+				t.Errorf("%v: expected the reference to %s", tt, key)
+			}
+			if firstMatch(document, `<emit>\s*<CNPJ>([0-9]+)`) != firstMatch(sale, `<emit>\s*<CNPJ>([0-9]+)`) {
+				t.Errorf("%v: expected the emitter of the sale", tt)
+			}
+			if strings.Count(document, "<qCom>0.0000</qCom>") != 2 || firstMatch(document, `<xProd>([^<]+)`) != firstMatch(sale, `<xProd>([^<]+)`) {
+				t.Errorf("%v: expected the items of the sale without quantity", tt)
+			}
+			if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+				t.Fatalf("%v: expected no schema violations, got %v", tt, errs)
+			}
+		}
+	}
+}
+
+func TestNFeComplementar_Values(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		xmlBytes, err := NewNFeComplementarGenerator().Generate()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		document := string(xmlBytes)
+		if !strings.Contains(document, "<finNFe>2</finNFe>") || !strings.Contains(document, "<refNFe>") {
+			t.Fatalf("Expected a complementary NF-e referencing another")
+		}
+		if firstMatch(document, `<vUnCom>([^<]+)`) != "0.00" || firstMatch(document, `<vProd>([^<]+)`) == "0.00" {
+			t.Errorf("Expected the complemented price only, got vUnCom %s and vProd %s",
+				firstMatch(document, `<vUnCom>([^<]+)`), firstMatch(document, `<vProd>([^<]+)`))
+		}
+	}
+}
+
+func TestNFeAjuste_Values(t *testing.T) {
+	// The adjustments of items sold for a cent, whose share of the value is no value at all.
+	qComRe, vUnComRe := regexp.MustCompile(`<qCom>[^<]+</qCom>`), regexp.MustCompile(`<vUnCom>[^<]+</vUnCom>`)
+	for _, situation := range []ICMSSituation{CST90, CST20, CST51, CST70} {
+		t.Run(situation.String(), func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				original, err := NewNFeGenerator().Generate(WithOperation(OperationVendaInterestadual), WithICMS(CST00))
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				cheap := qComRe.ReplaceAllString(string(original), "<qCom>1.0000</qCom>")
+				cheap = vUnComRe.ReplaceAllString(cheap, "<vUnCom>0.01</vUnCom>")
+
+				xmlBytes, err := NewNFeAjusteGenerator().Generate(WithReferencedInvoice([]byte(cheap)), WithICMS(situation))
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				document := string(xmlBytes)
+				for _, element := range []string{"<finNFe>3</finNFe>", "<refNFe>", "<vProd>0.00</vProd>", "<tPag>90</tPag>", "<vPag>0.00</vPag>"} {
+					if !strings.Contains(document, element) {
+						t.Errorf("Expected %s", element)
+					}
+				}
+				if firstMatch(document, `<CFOP>([0-9]+)`)[1:] != "949" {
+					t.Errorf("Expected the CFOP x949, got %s", firstMatch(document, `<CFOP>([0-9]+)`))
+				}
+				for _, vICMS := range regexp.MustCompile(`<vICMS>([^<]+)</vICMS>`).FindAllStringSubmatch(document, -1) {
+					if vICMS[1] == "0.00" {
+						t.Fatalf("Expected an adjusted ICMS in every item\n%s", document)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWithPartialReturn(t *testing.T) {
+	tests := []struct {
+		quantity   int64
+		fractional bool
+		percent    int
+		expected   int64
+		pDevol     int
+	}{
+		{quantity: 40000, percent: 50, expected: 20000, pDevol: 5000},
+		{quantity: 30000, percent: 50, expected: 10000, pDevol: 3333},
+		{quantity: 10000, percent: 10, expected: 10000, pDevol: 10000},
+		{quantity: 25000, fractional: true, percent: 20, expected: 5000, pDevol: 2000},
+	}
+	for _, test := range tests {
+		var item mockItem
+		item.returnItem(referencedItem{quantity: test.quantity, product: catalogProduct{fractional: test.fractional}}, test.percent)
+		if item.quantity != test.expected || item.pDevol != test.pDevol {
+			t.Errorf("Expected %d (pDevol %d) of %d at %d%%, got %d (%d)", test.expected, test.pDevol, test.quantity, test.percent, item.quantity, item.pDevol)
+		}
+	}
+}
+
+func TestWithReferenced(t *testing.T) {
+	sale, err := GenerateInvoice(NFe, WithOperation(OperationVendaInterna))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if sale.TemplateType != NFe || !strings.Contains(string(sale.XML), `Id="NFe`+sale.AccessKey+`"`) {
+		t.Fatalf("Expected the NFe with the access key %s\n%s", sale.AccessKey, sale.XML)
+	}
+	for _, tt := range []TemplateType{NFeDevolucao, NFeComplementar, NFeAjuste} {
+		document, err := GenerateInvoice(tt, WithReferenced(sale))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !strings.Contains(string(document.XML), "<refNFe>"+sale.AccessKey+"</refNFe>") {
+			t.Errorf("Expected the %v to reference %s", tt, sale.AccessKey)
+		}
+		if errs := Validate(tt, document.XML); len(errs) > 0 {
+			t.Errorf("Expected no schema violations, got %v", errs)
+		}
+	}
+
+	for _, invoice := range []*Invoice{nil, {TemplateType: NFe}} {
+		if _, err := NewNFeDevolucaoGenerator().Generate(WithReferenced(invoice)); err == nil {
+			t.Errorf("Expected an error referencing %v", invoice)
+		}
+	}
+}
+
+func TestWithReferencedInvoice_Errors(t *testing.T) {
+	original, err := NewNFeGenerator().Generate()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	returned, err := NewNFeDevolucaoGenerator().Generate()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	exported, err := NewNFeGenerator().Generate(WithOperation(OperationExportacao))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	consumer, err := NewNFCeGenerator().Generate()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		generator TemplateGenerator
+		options   []Option
+	}{
+		{"NFe generator", NewNFeGenerator(), []Option{WithReferencedInvoice(original)}},
+		{"malformed", NewNFeDevolucaoGenerator(), []Option{WithReferencedInvoice([]byte("<NFe>"))}},
+		{"return of a return", NewNFeDevolucaoGenerator(), []Option{WithReferencedInvoice(returned)}},
+		{"export", NewNFeDevolucaoGenerator(), []Option{WithReferencedInvoice(exported)}},
+		{"complement of an NFC-e", NewNFeComplementarGenerator(), []Option{WithReferencedInvoice(consumer)}},
+		{"percentage", NewNFeDevolucaoGenerator(), []Option{WithReferencedInvoice(original), WithPartialReturn(0)}},
+	}
+	for _, test := range tests {
+		if _, err := test.generator.Generate(test.options...); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
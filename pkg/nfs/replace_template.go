@@ -4,13 +4,72 @@ import (
 	"fmt"
 	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
 	"html"
-	"log"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 type DependencyGraph map[string][]string
 
+// optionalPlaceholders are the placeholders whose whole tag is left out when their value is empty.
+var optionalPlaceholders = map[string]bool{
+	"cEAN":                     true,
+	"CEST":                     true,
+	"detProdCEST":              true,
+	"vDescItem":                true,
+	"detProdVDesc":             true,
+	"destIE":                   true,
+	"destCNPJ":                 true,
+	"destIdEstrangeiro":        true,
+	"CPF":                      true,
+	"destCPF":                  true,
+	"emitCNPJ":                 true,
+	"emitCPF":                  true,
+	"destXNome":                true,
+	"CNPJDest":                 true,
+	"enderDestCEP":             true,
+	"vFCPUFDest":               true,
+	"vICMSUFDest":              true,
+	"vICMSUFRemet":             true,
+	"totalICMSTotvFCPUFDest":   true,
+	"totalICMSTotvICMSUFDest":  true,
+	"totalICMSTotvICMSUFRemet": true,
+	"indIntermed":              true,
+	"dhCont":                   true,
+	"xJust":                    true,
+}
+
+// fragmentPlaceholders are the placeholders replaced by an XML fragment, which is not escaped.
+var fragmentPlaceholders = map[string]bool{
+	"ICMSGroup":     true,
+	"IPIGroup":      true,
+	"ISSQNGroup":    true,
+	"ISSQNTotGroup": true,
+	"PISGroup":      true,
+	"PISSTGroup":    true,
+	"COFINSGroup":   true,
+	"COFINSSTGroup": true,
+
+	"ICMSUFDestGroup":  true,
+	"ISGroup":          true,
+	"IBSCBSGroup":      true,
+	"ISTotGroup":       true,
+	"IBSCBSTotGroup":   true,
+	"NFrefGroup":       true,
+	"DIGroup":          true,
+	"detExportGroup":   true,
+	"IIGroup":          true,
+	"exportaGroup":     true,
+	"transpGroup":      true,
+	"pagGroup":         true,
+	"infIntermedGroup": true,
+	"rastroGroup":      true,
+	"sectorGroup":      true,
+}
+
+// detBlockRe matches the <det> block of a template, with the indentation of its first line.
+var detBlockRe = regexp.MustCompile(`(?ms)^[ \t]*<det\b.*?</det>`)
+
 func topologicalSort(keys []string, dependencies DependencyGraph) ([]string, error) {
 	sorted := []string{}
 	visited := make(map[string]bool)
@@ -47,12 +106,141 @@ func topologicalSort(keys []string, dependencies DependencyGraph) ([]string, err
 // ReplaceTemplate takes an XML template and replaces placeholders with mock values.
 // It handles dependencies between placeholders and removes entire tags for blocked placeholders,
 // including any surrounding whitespace and newline characters to prevent blank lines.
+// The <det> block of the template is repeated for each item of the document.
 func ReplaceTemplate(template string, options ...Option) ([]byte, error) {
-	cfg := &generationConfig{}
+	cfg := &generationConfig{returnPercent: 100}
 	for _, opt := range options {
 		opt(cfg)
 	}
 
+	templateType := inferTemplateType(template)
+	if err := checkICMSSituations(templateType, cfg.icms); err != nil {
+		return nil, err
+	}
+	if err := checkPISSituations(templateType, cfg.pis, cfg.icms); err != nil {
+		return nil, err
+	}
+	if err := checkIPISituations(templateType, cfg.ipi); err != nil {
+		return nil, err
+	}
+	if err := checkServiceItems(templateType, cfg.services, cfg.icms); err != nil {
+		return nil, err
+	}
+	if err := checkTaxReform(templateType, cfg.taxReform); err != nil {
+		return nil, err
+	}
+	if err := checkOperation(templateType, cfg.operation, cfg); err != nil {
+		return nil, err
+	}
+	if err := checkProductSectors(templateType, cfg.sectors); err != nil {
+		return nil, err
+	}
+	if err := checkPayments(templateType, cfg.payments); err != nil {
+		return nil, err
+	}
+	if err := checkMarketplace(templateType, cfg); err != nil {
+		return nil, err
+	}
+	if err := checkRecipientKind(templateType, cfg); err != nil {
+		return nil, err
+	}
+	if err := checkEmitterProfile(templateType, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.referencedInvoice != nil {
+		reference, err := parseReferencedInvoice(templateType, cfg.referencedInvoice)
+		if err != nil {
+			return nil, err
+		}
+		cfg.reference = reference
+	}
+	if err := checkContingency(templateType, cfg); err != nil {
+		return nil, err
+	}
+	if err := checkEmissionDate(cfg); err != nil {
+		return nil, err
+	}
+	if err := checkSequence(templateType, cfg); err != nil {
+		return nil, err
+	}
+	if err := checkUniverse(templateType, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.returnPercent < 1 || cfg.returnPercent > 100 {
+		return nil, fmt.Errorf("invalid returned percentage: %d (from 1 to 100)", cfg.returnPercent)
+	}
+
+	// Values shared by several placeholders, so that the document is consistent
+	doc, err := newMockDocument(templateType, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.fault != 0 {
+		if _, ok := faultSpecs[cfg.fault]; !ok {
+			return nil, fmt.Errorf("unknown fault: %d", cfg.fault)
+		}
+		if doc.templateType == CFe || (cfg.fault == FaultItemValueMismatch && doc.complementary()) ||
+			(cfg.fault == FaultHomologationRecipient && doc.recipient == RecipientNone) {
+			return nil, fmt.Errorf("fault %v is not supported for %v documents", cfg.fault, doc.templateType)
+		}
+		cfg.fault.applyModel(doc)
+	}
+
+	// Each item fills its own copy of the <det> block
+	result := template
+	if location := detBlockRe.FindStringIndex(template); location != nil {
+		items := make([]string, len(doc.items))
+		for i := range doc.items {
+			doc.item = &doc.items[i]
+			filled, err := fillPlaceholders(template[location[0]:location[1]], cfg, doc)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = filled
+		}
+		result = template[:location[0]] + strings.Join(items, "\n") + template[location[1]:]
+	}
+	doc.item = &doc.total
+
+	result, err = fillPlaceholders(result, cfg, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Groups the document does not have, such as the recipient of an anonymous consumer
+	for _, name := range doc.omittedGroups() {
+		groupRe := regexp.MustCompile(fmt.Sprintf(`(?s)\n?[ \t]*<%s\b[^>]*>.*?</%s>`, name, name))
+		result = groupRe.ReplaceAllString(result, "")
+	}
+	if doc.offline() {
+		result = unwrapProc(result) // not authorized yet, the NFC-e is a bare NFe
+	}
+
+	// Remove entire XML tags that correspond to blocked placeholders,
+	// including any surrounding whitespace and newline characters
+	for _, blockedKey := range cfg.blockedPlaceholders {
+		// Define a regex pattern to match the entire tag containing the blocked placeholder
+		// The (?s) flag enables dot-all mode, allowing .*? to match newline characters
+		// \s* ensures that any leading or trailing whitespace (including newlines) is captured
+		tagPattern := fmt.Sprintf(`(?s)\s*<%s\b[^>]*>.*?</%s>\s*`, regexp.QuoteMeta(blockedKey), regexp.QuoteMeta(blockedKey))
+		tagRe := regexp.MustCompile(tagPattern)
+		result = tagRe.ReplaceAllString(result, "")
+	}
+
+	result = strings.TrimSpace(result)
+
+	if cfg.fault != 0 {
+		if result, err = cfg.fault.applyOutput(result, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	return []byte(result), nil
+}
+
+// fillPlaceholders replaces the placeholders of the template with mock values of the document.
+func fillPlaceholders(template string, cfg *generationConfig, doc *mockDocument) (string, error) {
 	// Regular expression to find placeholders in the form {%key%}
 	re := regexp.MustCompile(`\{\%(\w+)%\}`)
 
@@ -77,14 +265,20 @@ func ReplaceTemplate(template string, options ...Option) ([]byte, error) {
 
 	// Define dependencies
 	dependencies := DependencyGraph{
-		"accessKey": {"emitCNPJ"},
+		"cDV":             {"accessKey"},
+		"chNFe":           {"accessKey"},
+		"qrCode":          {"accessKey", "tpAmb", "DigestValue"},
+		"cEANTrib":        {"cEAN"},
+		"detProdCEANTrib": {"detProdCEAN"},
+		"uTrib":           {"uCom"},
+		"detProdUTrib":    {"detProdUCom"},
 		// Add more dependencies as needed
 	}
 
 	// Perform topological sort
 	sortedKeys, err := topologicalSort(keys, dependencies)
 	if err != nil {
-		return nil, fmt.Errorf("error sorting keys: %v", err)
+		return "", fmt.Errorf("error sorting keys: %v", err)
 	}
 
 	// Map to store generated values for each unique key
@@ -92,261 +286,311 @@ func ReplaceTemplate(template string, options ...Option) ([]byte, error) {
 
 	// Iterate through all sorted keys and generate mock values
 	for _, key := range sortedKeys {
-		replacements[key] = generateMockValue(key, replacements, cfg)
+		replacements[key] = generateMockValue(key, replacements, cfg, doc)
 	}
 
-	// Replace all placeholders in the template with generated values
+	// Optional elements whose value was generated empty are left out of the document
 	result := template
+	for key, value := range replacements {
+		if value != "" || !optionalPlaceholders[key] {
+			continue
+		}
+		emptyTagRe := regexp.MustCompile(fmt.Sprintf(`\s*<\w+>\{%%%s%%\}</\w+>`, regexp.QuoteMeta(key)))
+		result = emptyTagRe.ReplaceAllString(result, "")
+	}
+
+	// Fragments are written as they are, indented as their placeholder; empty ones are left out
+	for key, value := range replacements {
+		if !fragmentPlaceholders[key] {
+			continue
+		}
+		if value == "" {
+			emptyRe := regexp.MustCompile(fmt.Sprintf(`\n?[ \t]*\{%%%s%%\}`, regexp.QuoteMeta(key)))
+			result = emptyRe.ReplaceAllString(result, "")
+			continue
+		}
+		fragmentRe := regexp.MustCompile(fmt.Sprintf(`(?m)^[ \t]*\{%%%s%%\}`, regexp.QuoteMeta(key)))
+		result = fragmentRe.ReplaceAllStringFunc(result, func(line string) string {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			return indentFragment(value, indent)
+		})
+	}
+
+	// Replace all placeholders in the template with generated values
 	for key, value := range replacements {
 		placeholder := fmt.Sprintf("{%%%s%%}", key)
 		escapedValue := html.EscapeString(value)
 		result = strings.ReplaceAll(result, placeholder, escapedValue)
 	}
 
-	// Remove entire XML tags that correspond to blocked placeholders,
-	// including any surrounding whitespace and newline characters
-	for _, blockedKey := range cfg.blockedPlaceholders {
-		// Define a regex pattern to match the entire tag containing the blocked placeholder
-		// The (?s) flag enables dot-all mode, allowing .*? to match newline characters
-		// \s* ensures that any leading or trailing whitespace (including newlines) is captured
-		tagPattern := fmt.Sprintf(`(?s)\s*<%s\b[^>]*>.*?</%s>\s*`, regexp.QuoteMeta(blockedKey), regexp.QuoteMeta(blockedKey))
-		tagRe := regexp.MustCompile(tagPattern)
-		result = tagRe.ReplaceAllString(result, "")
-	}
-
-	result = strings.TrimSpace(result)
+	return result, nil
+}
 
-	return []byte(result), nil
+// indentFragment indents each line of the fragment. In templates without indentation,
+// the fragment is written without it too.
+func indentFragment(fragment, indent string) string {
+	lines := strings.Split(fragment, "\n")
+	for i, line := range lines {
+		if indent == "" {
+			lines[i] = strings.TrimLeft(line, " ")
+		} else {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
 }
 
 // generateMockValue generates mock data based on the placeholder key.
-// It uses provided CPF/CNPJ if available.
-func generateMockValue(key string, replacements map[string]string, cfg *generationConfig) string {
+// Values shared by several placeholders come from the mock document; it uses provided CPF/CNPJ if available.
+func generateMockValue(key string, replacements map[string]string, cfg *generationConfig, doc *mockDocument) string {
+	if value, ok := doc.item.productValue(key, doc.templateType); ok {
+		return value
+	}
+
 	switch key {
 	case "accessKey":
-		emitCNPJ, exists := replacements["emitCNPJ"]
-		if !exists || emitCNPJ == "" {
-			log.Printf("Warning: emitCNPJ not set before accessKey generation.")
-			emitCNPJ = br_documents.CNPJ()
-		}
-		accessKey := br_documents.AccessKey(br_documents.AccessKeyConfig{
-			CNPJ: emitCNPJ,
-		})
-		return accessKey
+		return doc.accessKey
+	case "refNFe":
+		return doc.refNFe
 	case "cUF":
-		return cUF()
+		ufCode, _ := br_documents.UFCode(doc.emit.city.uf)
+		return ufCode
 	case "cNF":
-		return Number(10000000, 99999999)
+		return doc.cNF
 	case "natOp":
-		return NatOp()
+		return doc.natOp
 	case "serie":
-		return Number(1, 999)
+		return strconv.Itoa(doc.serie)
 	case "nNF":
-		return nNF()
+		return strconv.Itoa(doc.nNF)
 	case "dhEmi":
-		return DhEmi()
+		return doc.dhEmi.Format(dateTimeLayout)
 	case "tpNF":
-		return tpNF()
+		return doc.tpNF
 	case "idDest":
-		return idDest()
+		return doc.idDest
 	case "cMunFG":
-		return cMun()
+		return doc.emit.city.code
 	case "tpImp":
-		return tpImp()
+		return doc.tpImp
 	case "tpEmis":
-		return tpEmis()
+		return doc.tpEmis
 	case "cDV":
-		return cDV()
+		accessKey := replacements["accessKey"]
+		return accessKey[len(accessKey)-1:]
 	case "tpAmb":
-		return tpAmb()
+		return doc.tpAmb
 	case "finNFe":
-		return finNFe()
+		return doc.finNFe
 	case "indFinal":
-		return indFinal()
+		return doc.indFinal
 	case "indPres":
-		return indPres()
+		return doc.indPres
 	case "indIntermed":
-		return indIntermed()
+		return doc.indIntermed
+	case "infIntermedGroup":
+		return doc.infIntermedGroupXML()
 	case "procEmi":
 		return procEmi()
 	case "verProc":
 		return verProc()
-	case "emitCNPJ", "CNPJ", "destCNPJ", "transpTransportaCNPJ", "cardCNPJ", "retiradaCNPJ", "entregaCNPJ":
+	case "dhCont":
+		if doc.dhCont.IsZero() {
+			return ""
+		}
+		return doc.dhCont.Format(dateTimeLayout)
+	case "xJust":
+		return doc.xJust
+	case "emitCNPJ":
+		return doc.emit.CNPJ
+	case "emitCPF":
+		return doc.emit.CPF
+	case "destCNPJ":
+		if doc.dest.CNPJ == "" {
+			return "" // identified by the CPF or idEstrangeiro
+		}
+		if cfg.CNPJ != "" {
+			return cfg.CNPJ
+		}
+		return doc.dest.CNPJ
+	case "destIdEstrangeiro":
+		return doc.dest.idEstrangeiro
+	case "NFrefGroup":
+		return doc.nfRefGroupXML()
+	case "CNPJ", "retiradaCNPJ", "entregaCNPJ":
 		if cfg.CNPJ != "" {
 			return cfg.CNPJ
 		}
 		return br_documents.CNPJ()
 	case "emitXNome":
-		return xNome()
+		return doc.emitName
 	case "xLgr":
-		return xLgr()
+		return doc.emit.street()
 	case "nro":
-		return nro()
+		return doc.emit.number()
 	case "xCpl":
-		return xCpl()
+		return doc.emit.complement()
 	case "xBairro":
-		return xBairro()
+		return doc.emit.neighborhood()
 	case "cMun":
-		return cMun()
+		return doc.emit.city.code
 	case "xMun":
-		return xMun()
+		return doc.emit.city.name
 	case "UF":
-		return UF()
+		return doc.emit.city.uf
 	case "CEP":
-		return CEP()
+		return doc.emit.postalCode()
 	case "cPais":
 		return cPais()
 	case "xPais":
 		return xPais()
 	case "fone":
-		return fone()
+		return doc.emit.phone()
 	case "IE":
-		return IE()
+		return doc.emit.IE
 	case "CRT":
-		return CRT()
-	case "CPF":
-		if cfg.CPF != "" {
-			return cfg.CPF
+		return doc.CRT
+	case "CPF", "destCPF":
+		return doc.dest.CPF
+	case "CNPJDest":
+		if doc.dest.CPF != "" {
+			return "" // identified by the CPF
 		}
-		return br_documents.CPF()
+		return doc.dest.CNPJ
 	case "destXNome":
-		return xNome()
+		return doc.destName
 	case "xLgrDest":
-		return xLgr()
+		return doc.dest.street()
 	case "nroDest":
-		return nro()
+		return doc.dest.number()
 	case "xCplDest":
-		return xCpl()
+		return doc.dest.complement()
 	case "xBairroDest":
-		return xBairro()
+		return doc.dest.neighborhood()
 	case "cMunDest":
-		return cMun()
+		return doc.dest.city.code
 	case "xMunDest":
-		return xMun()
+		return doc.dest.city.name
 	case "UFDest":
-		return UF()
+		return doc.dest.city.uf
 	case "CEPDest":
-		return CEP()
+		return doc.dest.postalCode()
 	case "cPaisDest":
-		return cPais()
+		return doc.dest.country()
 	case "xPaisDest":
-		return xPais()
+		return doc.dest.countryName()
 	case "foneDest":
-		return fone()
+		return doc.dest.phone()
 	case "indIEDest":
-		return indIEDest()
+		return doc.indIEDest
 	case "email":
-		return email()
+		return doc.destEmail
 	case "nItem":
-		return nItem()
+		return strconv.Itoa(doc.item.number)
 	case "cProd":
+		if doc.item.code != "" {
+			return doc.item.code
+		}
 		return cProd()
-	case "cEAN":
-		return cEAN()
-	case "xProd":
-		return xProd()
-	case "NCM":
-		return NCM()
 	case "CFOP":
-		return CFOP()
-	case "uCom":
-		return uCom()
+		return doc.item.CFOP
 	case "qCom":
-		return qCom()
+		return formatQuantity(doc.item.quantity)
 	case "vUnCom":
-		return vUnCom()
+		return doc.item.unitValue.String()
 	case "vProd":
-		return vProd()
+		return doc.item.vProd.String()
 	case "cEANTrib":
-		return cEANTrib()
+		return replacements["cEAN"]
 	case "uTrib":
-		return uTrib()
+		return replacements["uCom"]
 	case "qTrib":
-		return qTrib()
+		return formatQuantity(doc.item.quantity)
 	case "vUnTrib":
-		return vUnTrib()
+		return doc.item.unitValue.String()
 	case "vDesc":
-		return vDesc()
+		return doc.item.vDesc.String()
+	case "vDescItem", "detProdVDesc":
+		if doc.item.vDesc == 0 {
+			return "" // optional when the item has no discount
+		}
+		return doc.item.vDesc.String()
 	case "indTot":
-		return indTot()
+		return "1" // 1 = o valor do item compõe o valor total da NF-e
 	case "vTotTrib":
-		return vTotTrib()
-	case "orig":
-		return orig()
-	case "CSOSN":
-		return CSOSN()
-	case "CST_PIS":
-		return CST_PIS()
-	case "vBC_PIS":
-		return vBC_PIS()
-	case "pPIS":
-		return pPIS()
+		return doc.item.vTotTrib.String()
+	case "ICMSGroup":
+		if doc.templateType == CFe {
+			return doc.item.cfeICMSGroupXML()
+		}
+		return doc.item.icmsGroupXML()
+	case "ISSQNGroup":
+		return doc.item.issqnGroupXML(doc.templateType)
+	case "ISSQNTotGroup":
+		return doc.issqnTotXML()
+	case "ICMSUFDestGroup":
+		return doc.item.icmsUFDestXML()
+	case "ISGroup":
+		return doc.item.isGroupXML()
+	case "IBSCBSGroup":
+		return doc.item.ibsCBSGroupXML()
+	case "ISTotGroup":
+		return doc.item.isTotXML()
+	case "IBSCBSTotGroup":
+		return doc.item.ibsCBSTotXML()
+	case "DIGroup":
+		return doc.diGroupXML()
+	case "detExportGroup":
+		return doc.detExportGroupXML()
+	case "IIGroup":
+		return doc.iiGroupXML()
+	case "exportaGroup":
+		return doc.exportaGroupXML()
+	case "transpGroup":
+		return doc.transpGroupXML()
+	case "rastroGroup":
+		return doc.item.rastroGroupXML()
+	case "sectorGroup":
+		return doc.sectorGroupXML()
+	case "totalICMSTotvII":
+		return doc.item.vII.String()
+	case "IPIGroup":
+		return doc.item.ipiGroupXML()
+	case "PISGroup", "COFINSGroup":
+		return doc.item.pisGroupXML(strings.TrimSuffix(key, "Group"))
+	case "PISSTGroup", "COFINSSTGroup":
+		return doc.item.pisSTGroupXML(strings.TrimSuffix(key, "STGroup"))
 	case "vPIS":
-		return vPIS()
-	case "CST_COFINS":
-		return CST_COFINS()
-	case "vBC_COFINS":
-		return vBC_COFINS()
-	case "pCOFINS":
-		return pCOFINS()
+		return doc.item.vPIS.String()
 	case "vCOFINS":
-		return vCOFINS()
+		return doc.item.vCOFINS.String()
 	case "infAdProd":
 		return infAdProd()
 	case "vBC_total":
-		return vBC_total()
+		return doc.item.vBC.String()
 	case "vICMS_total":
-		return vICMS_total()
-	case "vICMSDeson":
-		return vICMSDeson()
-	case "vFCP":
-		return vFCP()
-	case "vBCST":
-		return vBCST()
-	case "vST":
-		return vST()
-	case "vFCPST":
-		return vFCPST()
-	case "vFCPSTRet":
-		return vFCPSTRet()
+		return doc.item.vICMS.String()
 	case "vProd_total":
-		return vProd_total()
-	case "vFrete":
-		return vFrete()
-	case "vSeg":
-		return vSeg()
+		return doc.item.vProd.String()
 	case "vDesc_total":
-		return vDesc_total()
-	case "vII":
-		return vII()
+		return doc.item.vDesc.String()
 	case "vIPI":
-		return vIPI()
-	case "vIPIDevol":
-		return vIPIDevol()
+		return doc.item.vIPI.String()
 	case "vPIS_total":
-		return vPIS_total()
+		return doc.item.vPIS.String()
 	case "vCOFINS_total":
-		return vCOFINS_total()
-	case "vOutro":
-		return vOutro()
+		return doc.item.vCOFINS.String()
 	case "vNF":
-		return vNF()
+		return doc.vNF.String()
 	case "vTotTrib_total":
-		return vTotTrib_total()
-	case "modFrete":
-		return modFrete()
-	case "tPag":
-		return tPag()
-	case "vPag":
-		return vPag()
-	case "tpIntegra":
-		return tpIntegra()
-	case "tBand":
-		return tBand()
-	case "cAut":
-		return cAut()
+		return doc.item.vTotTrib.String()
+	case "pagGroup":
+		return doc.pagGroupXML()
 	case "qrCode":
-		return qrCode()
+		if doc.offline() {
+			return offlineQRCode(replacements["accessKey"], replacements["tpAmb"], doc, replacements["DigestValue"])
+		}
+		return qrCode(replacements["accessKey"], replacements["tpAmb"])
 	case "urlChave":
 		return urlChave()
 	case "DigestValue":
@@ -356,13 +600,13 @@ func generateMockValue(key string, replacements map[string]string, cfg *generati
 	case "X509Certificate":
 		return X509Certificate()
 	case "tpAmbProt":
-		return tpAmbProt()
+		return doc.tpAmb
 	case "verAplic":
 		return verAplic()
 	case "chNFe":
-		return chNFe()
+		return replacements["accessKey"]
 	case "dhRecbto":
-		return dhRecbto()
+		return doc.dhRecbto.Format(dateTimeLayout)
 	case "nProt":
 		return nProt()
 	case "digVal":
@@ -371,132 +615,80 @@ func generateMockValue(key string, replacements map[string]string, cfg *generati
 		return cStat()
 	case "xMotivo":
 		return xMotivo()
-	case "transpTransportaXNome":
-		return transpTransportaXNome()
-	case "transpTransportaIE":
-		return transpTransportaIE()
-	case "transpTransportaXEnder":
-		return transpTransportaXEnder()
-	case "transpTransportaXMun":
-		return transpTransportaXMun()
-	case "transpTransportaUF":
-		return transpTransportaUF()
-	case "transpVeicTranspPlaca":
-		return transpVeicTranspPlaca()
-	case "transpVeicTranspUF":
-		return transpVeicTranspUF()
-	case "transpVeicTranspRNTC":
-		return transpVeicTranspRNTC()
-	case "transpReboquePlaca":
-		return transpReboquePlaca()
-	case "transpReboqueUF":
-		return transpReboqueUF()
-	case "transpReboqueRNTC":
-		return transpReboqueRNTC()
-	case "transpVolQVol":
-		return transpVolQVol()
-	case "transpVolEsp":
-		return transpVolEsp()
-	case "transpVolMarca":
-		return transpVolMarca()
-	case "transpVolNVol":
-		return transpVolNVol()
-	case "transpVolPesoL":
-		return transpVolPesoL()
-	case "transpVolPesoB":
-		return transpVolPesoB()
-	case "transpVolLacresNLacre":
-		return transpVolLacresNLacre()
 	case "infAdicInfAdFisco":
 		return infAdicInfAdFisco()
-	case "impostoCOFINSAliqCST":
-		return impostoCOFINSAliqCST()
-	case "impostoCOFINSAliqvBC":
-		return impostoCOFINSAliqvBC()
-	case "impostoCOFINSAliqpCOFINS":
-		return impostoCOFINSAliqpCOFINS()
-	case "impostoCOFINSAliqvCOFINS":
-		return impostoCOFINSAliqvCOFINS()
 	case "totalICMSTotvBC":
-		return totalICMSTotvBC()
+		return doc.item.vBC.String()
 	case "totalICMSTotvICMS":
-		return totalICMSTotvICMS()
-	case "totalICMSTotvBCST":
-		return totalICMSTotvBCST()
-	case "totalICMSTotvST":
-		return totalICMSTotvST()
+		return doc.item.vICMS.String()
 	case "totalICMSTotvProd":
-		return totalICMSTotvProd()
-	case "totalICMSTotvFrete":
-		return totalICMSTotvFrete()
-	case "totalICMSTotvSeg":
-		return totalICMSTotvSeg()
+		return doc.item.vProd.String()
 	case "totalICMSTotvDesc":
-		return totalICMSTotvDesc()
-	case "totalICMSTotvII":
-		return totalICMSTotvII()
+		return doc.item.vDesc.String()
 	case "totalICMSTotvIPI":
-		return totalICMSTotvIPI()
+		return doc.item.vIPI.String()
 	case "totalICMSTotvPIS":
-		return totalICMSTotvPIS()
+		return doc.item.vPIS.String()
 	case "totalICMSTotvCOFINS":
-		return totalICMSTotvCOFINS()
-	case "totalICMSTotvOutro":
-		return totalICMSTotvOutro()
+		return doc.item.vCOFINS.String()
 	case "totalICMSTotvNF":
-		return totalICMSTotvNF()
-	case "transpModFrete":
-		return transpModFrete()
+		return doc.vNF.String()
 	case "emitXFant":
-		return emitXFant()
+		return emitXFant(doc.emitName)
 	case "enderEmitXLgr":
-		return enderEmitXLgr()
+		return doc.emit.street()
 	case "enderEmitNro":
-		return enderEmitNro()
+		return doc.emit.number()
 	case "enderEmitXCpl":
-		return enderEmitXCpl()
+		return doc.emit.complement()
 	case "enderEmitXBairro":
-		return enderEmitXBairro()
+		return doc.emit.neighborhood()
 	case "enderEmitCMun":
-		return enderEmitCMun()
+		return doc.emit.city.code
 	case "enderEmitXMun":
-		return enderEmitXMun()
+		return doc.emit.city.name
 	case "enderEmitUF":
-		return enderEmitUF()
+		return doc.emit.city.uf
 	case "enderEmitCEP":
-		return enderEmitCEP()
+		return doc.emit.postalCode()
 	case "enderEmitCPais":
 		return enderEmitCPais()
 	case "enderEmitXPais":
 		return enderEmitXPais()
 	case "enderEmitFone":
-		return enderEmitFone()
+		return doc.emit.phone()
 	case "emitIE":
-		return emitIE()
+		return doc.emit.IE
 	case "enderDestXLgr":
-		return enderDestXLgr()
+		return doc.dest.street()
 	case "enderDestNro":
-		return enderDestNro()
+		return doc.dest.number()
 	case "enderDestXCpl":
-		return enderDestXCpl()
+		return doc.dest.complement()
 	case "enderDestXBairro":
-		return enderDestXBairro()
+		return doc.dest.neighborhood()
 	case "enderDestCMun":
-		return enderDestCMun()
+		return doc.dest.city.code
 	case "enderDestXMun":
-		return enderDestXMun()
+		return doc.dest.city.name
 	case "enderDestUF":
-		return enderDestUF()
+		return doc.dest.city.uf
 	case "enderDestCEP":
-		return enderDestCEP()
+		if doc.dest.idEstrangeiro != "" {
+			return "" // addresses abroad have no CEP
+		}
+		return doc.dest.postalCode()
 	case "enderDestCPais":
-		return enderDestCPais()
+		return doc.dest.country()
 	case "enderDestXPais":
-		return enderDestXPais()
+		return doc.dest.countryName()
 	case "enderDestFone":
-		return enderDestFone()
+		return doc.dest.phone()
 	case "destIE":
-		return destIE()
+		if doc.indIEDest == "9" {
+			return "" // non-contributors are not identified by their IE
+		}
+		return doc.dest.IE
 	case "retiradaXLgr":
 		return retiradaXLgr()
 	case "retiradaNro":
@@ -506,11 +698,11 @@ func generateMockValue(key string, replacements map[string]string, cfg *generati
 	case "retiradaXBairro":
 		return retiradaXBairro()
 	case "retiradaCMun":
-		return retiradaCMun()
+		return doc.pickup.code
 	case "retiradaXMun":
-		return retiradaXMun()
+		return doc.pickup.name
 	case "retiradaUF":
-		return retiradaUF()
+		return doc.pickup.uf
 	case "entregaXLgr":
 		return entregaXLgr()
 	case "entregaNro":
@@ -520,59 +712,139 @@ func generateMockValue(key string, replacements map[string]string, cfg *generati
 	case "entregaXBairro":
 		return entregaXBairro()
 	case "entregaCMun":
-		return entregaCMun()
+		return doc.delivery.code
 	case "entregaXMun":
-		return entregaXMun()
+		return doc.delivery.name
 	case "entregaUF":
-		return entregaUF()
+		return doc.delivery.uf
 	case "detNItem":
-		return detNItem()
+		return strconv.Itoa(doc.item.number)
 	case "detProdCProd":
+		if doc.item.code != "" {
+			return doc.item.code
+		}
 		return detProdCProd()
-	case "detProdCEAN":
-		return detProdCEAN()
-	case "detProdXProd":
-		return detProdXProd()
 	case "detProdCFOP":
-		return detProdCFOP()
-	case "detProdUCom":
-		return detProdUCom()
+		return doc.item.CFOP
 	case "detProdQCom":
-		return detProdQCom()
+		return formatQuantity(doc.item.quantity)
 	case "detProdVUnCom":
-		return detProdVUnCom()
+		return doc.item.unitValue.String()
 	case "detProdVProd":
-		return detProdVProd()
+		return doc.item.vProd.String()
 	case "detProdCEANTrib":
-		return detProdCEANTrib()
+		return replacements["detProdCEAN"]
 	case "detProdUTrib":
-		return detProdUTrib()
+		return replacements["detProdUCom"]
 	case "detProdQTrib":
-		return detProdQTrib()
+		return formatQuantity(doc.item.quantity)
 	case "detProdVUnTrib":
-		return detProdVUnTrib()
-	case "impostoICMS00orig":
-		return impostoICMS00orig()
-	case "impostoICMS00CST":
-		return impostoICMS00CST()
-	case "impostoICMS00modBC":
-		return impostoICMS00modBC()
-	case "impostoICMS00vBC":
-		return impostoICMS00vBC()
-	case "impostoICMS00pICMS":
-		return impostoICMS00pICMS()
-	case "impostoICMS00vICMS":
-		return impostoICMS00vICMS()
-	case "impostoPISAliqCST":
-		return impostoPISAliqCST()
-	case "impostoPISAliqvBC":
-		return impostoPISAliqvBC()
-	case "impostoPISAliqpPIS":
-		return impostoPISAliqpPIS()
-	case "impostoPISAliqvPIS":
-		return impostoPISAliqvPIS()
-	case "indPag":
-		return indPag()
+		return doc.item.unitValue.String()
+	case "dhSaiEnt":
+		return doc.dhSaiEnt.Format(dateTimeLayout)
+	case "detProdIndTot":
+		return detProdIndTot()
+	case "vICMS":
+		return doc.item.vICMS.String()
+	case "vBC":
+		return doc.item.pisBase().String()
+	case "CST_PISAliq", "CST_COFINSAliq":
+		return doc.item.pisCST
+	case "pPISSAT":
+		return fmt.Sprintf("0.%04d", doc.item.pPIS)
+	case "pCOFINSSAT":
+		return fmt.Sprintf("0.%04d", doc.item.pCOFINS)
+	case "vIPI_total":
+		return doc.item.vIPI.String()
+	case "pDevol":
+		if doc.item.pDevol == 0 {
+			return "100.00"
+		}
+		return formatRate(doc.item.pDevol, 2)
+	case "infCpl":
+		return infCpl()
+	case "infAdFisco":
+		return infAdFisco()
+	case "infRespTecCNPJ", "softwareHouseCNPJ":
+		return br_documents.CNPJ()
+	case "infRespTecXContato":
+		return infRespTecXContato()
+	case "infRespTecEmail":
+		return email(xNome())
+	case "infRespTecFone":
+		return fone(UF())
+	case "cNFCFe":
+		return doc.cNF
+	case "nserieSAT":
+		return fmt.Sprintf("%09d", doc.serie)
+	case "nCFe":
+		return fmt.Sprintf("%06d", doc.nNF)
+	case "dEmi":
+		return doc.dhEmi.Format("20060102")
+	case "hEmi":
+		return doc.dhEmi.Format("150405")
+	case "signAC":
+		return signAC()
+	case "assinaturaQRCODE":
+		return assinaturaQRCODE()
+	case "numeroCaixa":
+		return numeroCaixa()
+	case "cRegTrib":
+		return "3" // 3 = Regime Normal
+	case "indRatISSQN":
+		return indRatISSQN()
+	case "indRegra":
+		return indRegra()
+	case "vItem":
+		return doc.item.net().String()
+	case "vItem12741":
+		return doc.item.vTotTrib.String()
+	case "vCFe":
+		return doc.vNF.String()
+	case "vCFeLei12741":
+		return doc.item.vTotTrib.String()
+	case "cMP":
+		return paymentSpecs[doc.payments[0].method].tPag
+	case "vMP":
+		return doc.payments[0].vPag.String()
+	case "cAdmC":
+		return cAdmC()
+	case "obsFiscoXCampo":
+		return obsFiscoXCampo()
+	case "obsFiscoXTexto":
+		return obsFiscoXTexto()
+	case "vTroco":
+		return doc.vChange.String()
+	case "vICMSDeson", "totalICMSTotvICMSDeson":
+		return doc.item.vICMSDeson.String()
+	case "vBCST", "totalICMSTotvBCST":
+		return doc.item.vBCST.String()
+	case "vST", "totalICMSTotvST":
+		return doc.item.vICMSST.String()
+	case "vFCP", "totalICMSTotvFCP":
+		return doc.item.vFCP.String()
+	case "vFCPST", "totalICMSTotvFCPST":
+		return doc.item.vFCPST.String()
+	case "vFCPUFDest", "totalICMSTotvFCPUFDest":
+		if doc.item.pICMSInter == 0 {
+			return "" // informed with DIFAL only
+		}
+		return doc.item.vFCPUFDest.String()
+	case "vICMSUFDest", "totalICMSTotvICMSUFDest":
+		if doc.item.pICMSInter == 0 {
+			return ""
+		}
+		return doc.item.vICMSUFDest.String()
+	case "vICMSUFRemet", "totalICMSTotvICMSUFRemet":
+		if doc.item.pICMSInter == 0 {
+			return ""
+		}
+		return "0.00" // all of the DIFAL goes to the destination state since 2019
+	case "vFCPSTRet", "vFrete", "vSeg", "vII", "vOutro",
+		"vIPIDevol", "vIPIDevol_total", "vPISST", "vCOFINSST",
+		"totalICMSTotvFCPSTRet", "totalICMSTotvFrete", "totalICMSTotvSeg", "totalICMSTotvIPIDevol",
+		"totalICMSTotvOutro":
+		return "0.00"
 	default:
 		return ""
 	}
@@ -0,0 +1,85 @@
+package nfs
+
+import "time"
+
+// unbounded is used as maxOccurs for elements without an upper limit.
+const unbounded = -1
+
+// Basic types shared by the NF-e and CF-e schemas (tiposBasico_v4.00.xsd, CFe_v0.08.xsd).
+var (
+	tCnpj        = pattern(`[0-9A-Z]{12}[0-9]{2}`)
+	tCpf         = pattern(`[0-9]{11}`)
+	tChNFe       = pattern(`[0-9]{44}`)
+	tCodMunIBGE  = pattern(`[0-9]{7}`)
+	tCEP         = pattern(`[0-9]{8}`)
+	tFone        = pattern(`[0-9]{6,14}`)
+	tEmail       = pattern(`[^@]+@[^\.]+\..+`).length(1, 60)
+	tCodPais     = pattern(`[0-9]{1,4}`)
+	tIe          = pattern(`[0-9]{2,14}|ISENTO`)
+	tIeDest      = pattern(`ISENTO|[0-9]{2,14}`)
+	tIeST        = pattern(`[0-9]{2,14}`)
+	tBase64      = pattern(`[A-Za-z0-9+/]+={0,2}`)
+	tDec1302     = pattern(`0|0\.[0-9]{2}|[1-9][0-9]{0,12}(\.[0-9]{2})?`)
+	tDec1302Opc  = pattern(`0\.[0-9][1-9]|0\.[1-9][0-9]|[1-9][0-9]{0,12}(\.[0-9]{2})?`)
+	tDec1203     = pattern(`0|0\.[0-9]{3}|[1-9][0-9]{0,11}(\.[0-9]{3})?`)
+	tDec1104     = pattern(`0|0\.[0-9]{4}|[1-9][0-9]{0,10}(\.[0-9]{4})?`)
+	tDec1104v    = pattern(`0|0\.[0-9]{1,4}|[1-9][0-9]{0,10}(\.[0-9]{1,4})?`)
+	tDec1110v    = pattern(`0|0\.[0-9]{1,10}|[1-9][0-9]{0,10}(\.[0-9]{1,10})?`)
+	tDec0803v    = pattern(`0|0\.[0-9]{1,3}|[1-9][0-9]{0,7}(\.[0-9]{1,3})?`)
+	tDec1204     = pattern(`0|0\.[0-9]{4}|[1-9][0-9]{0,11}(\.[0-9]{4})?`)
+	tDec0302a04  = pattern(`0|0\.[0-9]{2,4}|[1-9][0-9]{0,2}(\.[0-9]{2,4})?`)
+	tDec0302Max  = pattern(`0(\.[0-9]{2})?|100(\.00)?|[1-9][0-9]?(\.[0-9]{2})?`)
+	tDec0302a04M = pattern(`0(\.[0-9]{2,4})?|[1-9][0-9]?(\.[0-9]{2,4})?|100(\.0{2,4})?`)
+	tDec0204     = pattern(`0|0\.[0-9]{4}|[1-9][0-9]?(\.[0-9]{4})?`)
+	tDec1302Neg  = pattern(`-?(0|0\.[0-9]{2}|[1-9][0-9]{0,12}(\.[0-9]{2})?)`)
+	tUf          = enum("AC", "AL", "AM", "AP", "BA", "CE", "DF", "ES", "GO", "MA", "MG", "MS", "MT", "PA", "PB", "PE", "PI", "PR", "RJ", "RN", "RO", "RR", "RS", "SC", "SE", "SP", "TO")
+	tUfEX        = enum("AC", "AL", "AM", "AP", "BA", "CE", "DF", "ES", "GO", "MA", "MG", "MS", "MT", "PA", "PB", "PE", "PI", "PR", "RJ", "RN", "RO", "RR", "RS", "SC", "SE", "SP", "TO", "EX")
+	tCodUfIBGE   = enum("11", "12", "13", "14", "15", "16", "17", "21", "22", "23", "24", "25", "26", "27", "28", "29", "31", "32", "33", "35", "41", "42", "43", "50", "51", "52", "53")
+	tDateTimeUTC = pattern(`20[0-9]{2}-(0[1-9]|1[0-2])-(0[1-9]|[12][0-9]|3[01])T([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9]([-+](0[0-9]|1[01]):00|\+12:00)`).checked(layoutCheck(time.RFC3339))
+	tData        = pattern(`20[0-9]{2}-(0[1-9]|1[0-2])-(0[1-9]|[12][0-9]|3[01])`).checked(layoutCheck("2006-01-02"))
+)
+
+// layoutCheck returns a check that the value parses with the given time layout.
+func layoutCheck(layout string) func(string) bool {
+	return func(value string) bool {
+		_, err := time.Parse(layout, value)
+		return err == nil
+	}
+}
+
+// signatureRule describes the XMLDSig Signature element embedded in fiscal documents.
+func signatureRule() *elementRule {
+	return group("Signature",
+		group("SignedInfo",
+			opaque("CanonicalizationMethod"),
+			opaque("SignatureMethod"),
+			group("Reference",
+				group("Transforms",
+					opaque("Transform").repeated(1, unbounded),
+				),
+				opaque("DigestMethod"),
+				el("DigestValue", tBase64),
+			).attr("URI", pattern(`#.+`)),
+		),
+		el("SignatureValue", tBase64),
+		group("KeyInfo",
+			group("X509Data",
+				el("X509Certificate", tBase64),
+			),
+		),
+	)
+}
+
+// schemaFor returns the root rule used to validate documents of the TemplateType.
+func schemaFor(tt TemplateType) (particle, bool) {
+	switch tt {
+	case CFe:
+		return cfeSchema, true
+	case NFe, NFeDevolucao, NFeComplementar, NFeAjuste:
+		return nfeSchema55, true
+	case NFCe:
+		return nfeSchema65, true
+	default:
+		return nil, false
+	}
+}
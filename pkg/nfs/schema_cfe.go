@@ -0,0 +1,184 @@
+package nfs
+
+// Structural rules derived from the CF-e-SAT layout 0.08 (CFe_v0.08.xsd).
+
+var cfeSchema particle = cfeRoot()
+
+// cfeRoot builds the rule for a CF-e document as returned by the SAT.
+func cfeRoot() *elementRule {
+	vItem := el("vItem", tDec1302)
+
+	ide := group("ide",
+		el("cUF", tCodUfIBGE),
+		el("cNF", pattern(`[0-9]{6}`)),
+		el("mod", enum("59")),
+		el("nserieSAT", pattern(`[0-9]{9}`)),
+		el("nCFe", pattern(`[0-9]{6}`)),
+		el("dEmi", pattern(`20[0-9]{2}(0[1-9]|1[0-2])(0[1-9]|[12][0-9]|3[01])`).checked(layoutCheck("20060102"))),
+		el("hEmi", pattern(`([01][0-9]|2[0-3])[0-5][0-9][0-5][0-9]`)),
+		el("cDV", pattern(`[0-9]`)),
+		el("tpAmb", enum("1", "2")),
+		el("CNPJ", tCnpj),
+		el("signAC", tString(1, 344)),
+		el("assinaturaQRCODE", tBase64.length(344, 344)),
+		el("numeroCaixa", pattern(`[0-9]{3}`)),
+	)
+
+	emit := group("emit",
+		el("CNPJ", tCnpj),
+		el("xNome", tString(1, 60)).optional(),
+		el("xFant", tString(1, 60)).optional(),
+		group("enderEmit",
+			el("xLgr", tString(2, 60)),
+			el("nro", tString(1, 60)),
+			el("xCpl", tString(1, 60)).optional(),
+			el("xBairro", tString(1, 60)),
+			el("xMun", tString(2, 60)),
+			el("CEP", tCEP),
+		).optional(),
+		el("IE", pattern(`[0-9]{2,12}`)),
+		el("IM", tString(1, 15)).optional(),
+		el("cRegTrib", enum("1", "3")),
+		el("cRegTribISSQN", enum("1", "2", "3", "4", "5", "6")).optional(),
+		el("indRatISSQN", enum("S", "N")),
+	)
+
+	dest := group("dest",
+		choice(seq(el("CNPJ", tCnpj)), seq(el("CPF", tCpf))).optional(),
+		el("xNome", tString(2, 60)).optional(),
+	)
+
+	pisCofins := func(tax string) (*elementRule, *elementRule) {
+		rate := el("p"+tax, tDec0204)
+		value := el("v"+tax, tDec1302)
+		base := choice(
+			seq(vBC, rate),
+			seq(el("qBCProd", tDec1204), el("vAliqProd", tDec1104)),
+		)
+		main := group(tax, choice(
+			seq(group(tax+"Aliq", el("CST", enum("01", "02", "05")), vBC, rate, value)),
+			seq(group(tax+"Qtde", el("CST", enum("03")), el("qBCProd", tDec1204), el("vAliqProd", tDec1104), value)),
+			seq(group(tax+"NT", el("CST", enum("04", "06", "07", "08", "09")))),
+			seq(group(tax+"SN", el("CST", enum("49")))),
+			seq(group(tax+"Outr", el("CST", enum("99")), base, value)),
+		))
+		st := group(tax+"ST", base, value).optional()
+		return main, st
+	}
+	pis, pisST := pisCofins("PIS")
+	cofins, cofinsST := pisCofins("COFINS")
+
+	cfeOrig := el("Orig", enum("0", "1", "2", "3", "4", "5", "6", "7", "8"))
+	icms := group("ICMS", choice(
+		seq(group("ICMS00", cfeOrig, el("CST", enum("00", "20", "90")), pICMS, vICMS)),
+		seq(group("ICMS40", cfeOrig, el("CST", enum("40", "41", "50", "60")))),
+		seq(group("ICMSSN102", cfeOrig, el("CSOSN", enum("102", "300", "400", "500")))),
+		seq(group("ICMSSN900", cfeOrig, el("CSOSN", enum("900")), pICMS, vICMS)),
+	))
+
+	issqn := group("ISSQN",
+		el("vDeducISSQN", tDec1302),
+		vBC,
+		el("vAliq", tDec0302a04),
+		el("vISSQN", tDec1302),
+		el("cMunFG", tCodMunIBGE).optional(),
+		el("cListServ", pattern(`[0-9]{2}\.[0-9]{2}`)).optional(),
+		el("cServTribMun", tString(1, 20)).optional(),
+		el("cNatOp", enum("01", "02", "03", "04", "05", "06", "07", "08")),
+		el("indIncFisc", enum("1", "2")),
+	)
+
+	det := group("det",
+		group("prod",
+			el("cProd", tString(1, 60)),
+			el("cEAN", pattern(`[0-9]{8}|[0-9]{12,14}`)).optional(),
+			el("xProd", tString(1, 120)),
+			el("NCM", pattern(`[0-9]{2}|[0-9]{8}`)).optional(),
+			el("CEST", pattern(`[0-9]{7}`)).optional(),
+			el("CFOP", pattern(`[0-9]{4}`)),
+			el("uCom", tString(1, 6)),
+			el("qCom", tDec1104),
+			el("vUnCom", tDec1110v),
+			el("vProd", tDec1302),
+			el("indRegra", enum("A", "T")),
+			el("vDesc", tDec1302).optional(),
+			el("vOutro", tDec1302).optional(),
+			vItem,
+			el("vRatDesc", tDec1302).optional(),
+			el("vRatAcr", tDec1302).optional(),
+			group("obsFiscoDet", el("xTextoDet", tString(1, 60))).attr("xCampoDet", tString(1, 20)).repeated(0, 10),
+		),
+		group("imposto",
+			el("vItem12741", tDec1302).optional(),
+			choice(seq(icms), seq(issqn)),
+			pis,
+			pisST,
+			cofins,
+			cofinsST,
+		),
+		el("infAdProd", tString(1, 500)).optional(),
+	).attr("nItem", pattern(`[1-9][0-9]{0,2}`)).repeated(1, 500)
+
+	total := group("total",
+		group("ICMSTot",
+			vICMS,
+			el("vProd", tDec1302),
+			el("vDesc", tDec1302),
+			el("vPIS", tDec1302),
+			el("vCOFINS", tDec1302),
+			el("vPISST", tDec1302),
+			el("vCOFINSST", tDec1302),
+			el("vOutro", tDec1302),
+		).optional(),
+		group("ISSQNtot",
+			vBC,
+			el("vISS", tDec1302),
+			el("vPIS", tDec1302),
+			el("vCOFINS", tDec1302),
+			el("vPISST", tDec1302),
+			el("vCOFINSST", tDec1302),
+		).optional(),
+		group("DescAcrEntr", choice(
+			seq(el("vDescSubtot", tDec1302)),
+			seq(el("vAcresSubtot", tDec1302)),
+		)).optional(),
+		el("vCFe", tDec1302),
+		el("vCFeLei12741", tDec1302).optional(),
+	)
+
+	pgto := group("pgto",
+		group("MP",
+			el("cMP", enum("01", "02", "03", "04", "05", "10", "11", "12", "13", "15", "16", "17", "18", "19", "20", "21", "22", "90", "99")),
+			el("vMP", tDec1302),
+			el("cAdmC", pattern(`[0-9]{3}`)).optional(),
+		).repeated(1, 10),
+		el("vTroco", tDec1302),
+	)
+
+	infAdic := group("infAdic",
+		el("infCpl", tString(1, 5000)).optional(),
+		group("obsFisco", el("xTexto", tString(1, 60))).attr("xCampo", tString(1, 20)).repeated(0, 10),
+	).optional()
+
+	return group("CFe",
+		group("infCFe",
+			ide,
+			emit,
+			dest,
+			group("entrega",
+				el("xLgr", tString(2, 60)),
+				el("nro", tString(1, 60)),
+				el("xCpl", tString(1, 60)).optional(),
+				el("xBairro", tString(2, 60)),
+				el("xMun", tString(2, 60)),
+				el("UF", tUf),
+			).optional(),
+			det,
+			total,
+			pgto,
+			infAdic,
+		).attr("Id", pattern(`CFe[0-9]{44}`)).attr("versao", pattern(`0\.0[6-9]`)).
+			attr("versaoDadosEnt", pattern(`0\.0[6-9]`)).attr("versaoSB", pattern(`[0-9]{6}`)),
+		signatureRule(),
+	)
+}
@@ -0,0 +1,781 @@
+package nfs
+
+// Structural rules derived from the NF-e/NFC-e layout 4.00 (PL_009_V4 and later technical notes).
+// Only the groups that can appear in generated or commonly received documents are described;
+// any element outside of them is reported as unexpected.
+
+var (
+	nfeSchema55 = nfeRoot("55")
+	nfeSchema65 = nfeRoot("65")
+)
+
+// Amount and rate shorthands used across the tax groups.
+var (
+	vBC      = el("vBC", tDec1302)
+	vICMS    = el("vICMS", tDec1302)
+	pICMS    = el("pICMS", tDec0302a04)
+	modBC    = el("modBC", enum("0", "1", "2", "3"))
+	pRedBC   = el("pRedBC", tDec0302a04M)
+	icmsOrig = el("orig", enum("0", "1", "2", "3", "4", "5", "6", "7", "8"))
+
+	icmsFCP = optionalSeq(
+		el("vBCFCP", tDec1302),
+		el("pFCP", tDec0302a04),
+		el("vFCP", tDec1302),
+	)
+	icmsST = seq(
+		el("modBCST", enum("0", "1", "2", "3", "4", "5", "6")),
+		el("pMVAST", tDec0302a04).optional(),
+		el("pRedBCST", tDec0302a04M).optional(),
+		el("vBCST", tDec1302),
+		el("pICMSST", tDec0302a04),
+		el("vICMSST", tDec1302),
+	)
+	icmsFCPST = optionalSeq(
+		el("vBCFCPST", tDec1302),
+		el("pFCPST", tDec0302a04),
+		el("vFCPST", tDec1302),
+	)
+	icmsDeson = optionalSeq(
+		el("vICMSDeson", tDec1302),
+		el("motDesICMS", enum("1", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "16", "90")),
+		el("indDeduzDeson", enum("0", "1")).optional(),
+	)
+	icmsSTDeson = optionalSeq(
+		el("vICMSSTDeson", tDec1302),
+		el("motDesICMSST", enum("3", "9", "12")),
+	)
+	icmsSTRet = optionalSeq(
+		el("vBCSTRet", tDec1302),
+		el("pST", tDec0302a04),
+		el("vICMSSubstituto", tDec1302).optional(),
+		el("vICMSSTRet", tDec1302),
+	)
+	icmsFCPSTRet = optionalSeq(
+		el("vBCFCPSTRet", tDec1302),
+		el("pFCPSTRet", tDec0302a04),
+		el("vFCPSTRet", tDec1302),
+	)
+	icmsEfet = optionalSeq(
+		el("pRedBCEfet", tDec0302a04M),
+		el("vBCEfet", tDec1302),
+		el("pICMSEfet", tDec0302a04),
+		el("vICMSEfet", tDec1302),
+	)
+	icmsCredSN = seq(
+		el("pCredSN", tDec0302a04),
+		el("vCredICMSSN", tDec1302),
+	)
+)
+
+// nfeICMS describes the ICMS group with every CST and CSOSN variant.
+var nfeICMS = group("ICMS", choice(
+	seq(group("ICMS00", icmsOrig, el("CST", enum("00")), modBC, vBC, pICMS, vICMS,
+		optionalSeq(el("pFCP", tDec0302a04), el("vFCP", tDec1302)))),
+	seq(group("ICMS02", icmsOrig, el("CST", enum("02")),
+		el("qBCMono", tDec1104).optional(), el("adRemICMS", tDec0302a04), el("vICMSMono", tDec1302))),
+	seq(group("ICMS10", concat(
+		seq(icmsOrig, el("CST", enum("10")), modBC, vBC, pICMS, vICMS, icmsFCP),
+		icmsST, seq(icmsFCPST, icmsSTDeson))...)),
+	seq(group("ICMS15", icmsOrig, el("CST", enum("15")),
+		el("qBCMono", tDec1104).optional(), el("adRemICMS", tDec0302a04), el("vICMSMono", tDec1302),
+		el("qBCMonoReten", tDec1104).optional(), el("adRemICMSReten", tDec0302a04), el("vICMSMonoReten", tDec1302),
+		optionalSeq(el("pRedAdRem", tDec0302a04), el("motRedAdRem", enum("1", "9"))))),
+	seq(group("ICMS20", icmsOrig, el("CST", enum("20")), modBC, pRedBC, vBC, pICMS, vICMS, icmsFCP, icmsDeson)),
+	seq(group("ICMS30", concat(
+		seq(icmsOrig, el("CST", enum("30"))),
+		icmsST, seq(icmsFCPST, icmsDeson))...)),
+	seq(group("ICMS40", icmsOrig, el("CST", enum("40", "41", "50")), icmsDeson)),
+	seq(group("ICMS51", icmsOrig, el("CST", enum("51")),
+		modBC.optional(), pRedBC.optional(), el("cBenefRBC", tString(8, 10)).optional(), vBC.optional(), pICMS.optional(),
+		el("vICMSOp", tDec1302).optional(), el("pDif", tDec0302a04M).optional(), el("vICMSDif", tDec1302).optional(),
+		vICMS.optional(), icmsFCP, optionalSeq(el("pFCPDif", tDec0302a04), el("vFCPDif", tDec1302), el("vFCPEfet", tDec1302)))),
+	seq(group("ICMS53", icmsOrig, el("CST", enum("53")),
+		el("qBCMono", tDec1104).optional(), el("adRemICMS", tDec0302a04).optional(), el("vICMSMonoOp", tDec1302).optional(),
+		el("pDif", tDec0302a04M).optional(), el("vICMSMonoDif", tDec1302).optional(), el("vICMSMono", tDec1302).optional())),
+	seq(group("ICMS60", icmsOrig, el("CST", enum("60")), icmsSTRet, icmsFCPSTRet, icmsEfet)),
+	seq(group("ICMS61", icmsOrig, el("CST", enum("61")),
+		el("qBCMonoRet", tDec1104).optional(), el("adRemICMSRet", tDec0302a04), el("vICMSMonoRet", tDec1302))),
+	seq(group("ICMS70", concat(
+		seq(icmsOrig, el("CST", enum("70")), modBC, pRedBC, vBC, pICMS, vICMS, icmsFCP),
+		icmsST, seq(icmsFCPST, icmsDeson, icmsSTDeson))...)),
+	seq(group("ICMS90", icmsOrig, el("CST", enum("90")),
+		optionalSeq(modBC, vBC, pRedBC.optional(), pICMS, vICMS, icmsFCP),
+		optionalSeq(concat(icmsST, seq(icmsFCPST))...),
+		icmsDeson, icmsSTDeson)),
+	seq(group("ICMSPart", concat(
+		seq(icmsOrig, el("CST", enum("10", "90")), modBC, vBC, pRedBC.optional(), pICMS, vICMS),
+		icmsST, seq(icmsFCPST, el("pBCOp", tDec0302a04M), el("UFST", tUfEX)))...)),
+	seq(group("ICMSST", icmsOrig, el("CST", enum("41", "60")),
+		el("vBCSTRet", tDec1302), el("pST", tDec0302a04).optional(), el("vICMSSubstituto", tDec1302).optional(),
+		el("vICMSSTRet", tDec1302), icmsFCPSTRet, el("vBCSTDest", tDec1302), el("vICMSSTDest", tDec1302), icmsEfet)),
+	seq(group("ICMSSN101", concat(seq(icmsOrig, el("CSOSN", enum("101"))), icmsCredSN)...)),
+	seq(group("ICMSSN102", icmsOrig, el("CSOSN", enum("102", "103", "300", "400")))),
+	seq(group("ICMSSN201", concat(
+		seq(icmsOrig, el("CSOSN", enum("201"))),
+		icmsST, seq(icmsFCPST, optionalSeq(icmsCredSN...)))...)),
+	seq(group("ICMSSN202", concat(
+		seq(icmsOrig, el("CSOSN", enum("202", "203"))),
+		icmsST, seq(icmsFCPST))...)),
+	seq(group("ICMSSN500", icmsOrig, el("CSOSN", enum("500")), icmsSTRet, icmsFCPSTRet, icmsEfet)),
+	seq(group("ICMSSN900", icmsOrig, el("CSOSN", enum("900")),
+		optionalSeq(modBC, vBC, pRedBC.optional(), pICMS, vICMS),
+		optionalSeq(concat(icmsST, seq(icmsFCPST))...),
+		optionalSeq(icmsCredSN...))),
+))
+
+// nfeIPI describes the IPI group.
+var nfeIPI = group("IPI",
+	el("CNPJProd", tCnpj).optional(),
+	el("cSelo", tString(1, 60)).optional(),
+	el("qSelo", pattern(`[0-9]{1,12}`)).optional(),
+	el("cEnq", tString(1, 3)),
+	choice(
+		seq(group("IPITrib",
+			el("CST", enum("00", "49", "50", "99")),
+			choice(
+				seq(vBC, el("pIPI", tDec0302a04)),
+				seq(el("qUnid", tDec1204), el("vUnid", tDec1104)),
+			),
+			el("vIPI", tDec1302),
+		)),
+		seq(group("IPINT",
+			el("CST", enum("01", "02", "03", "04", "05", "51", "52", "53", "54", "55")),
+		)),
+	),
+)
+
+// nfeII describes the import tax group.
+var nfeII = group("II",
+	vBC,
+	el("vDespAdu", tDec1302),
+	el("vII", tDec1302),
+	el("vIOF", tDec1302),
+)
+
+// nfeISSQN describes the service tax group of an item.
+var nfeISSQN = group("ISSQN",
+	vBC,
+	el("vAliq", tDec0302a04),
+	el("vISSQN", tDec1302),
+	el("cMunFG", tCodMunIBGE),
+	el("cListServ", pattern(`[0-9]{2}\.[0-9]{2}`)),
+	el("vDeducao", tDec1302).optional(),
+	el("vOutro", tDec1302).optional(),
+	el("vDescIncond", tDec1302).optional(),
+	el("vDescCond", tDec1302).optional(),
+	el("vISSRet", tDec1302).optional(),
+	el("indISS", enum("1", "2", "3", "4", "5", "6", "7")),
+	el("cServico", tString(1, 20)).optional(),
+	el("cMun", tCodMunIBGE).optional(),
+	el("cPais", tCodPais).optional(),
+	el("nProcesso", tString(1, 30)).optional(),
+	el("indIncentivo", enum("1", "2")),
+)
+
+// pisCofinsGroups builds the PIS or COFINS group; tax is "PIS" or "COFINS".
+func pisCofinsGroups(tax string) (*elementRule, *elementRule) {
+	rate := el("p"+tax, tDec0302a04)
+	value := el("v"+tax, tDec1302)
+	base := choice(
+		seq(vBC, rate),
+		seq(el("qBCProd", tDec1204), el("vAliqProd", tDec1104)),
+	)
+
+	main := group(tax, choice(
+		seq(group(tax+"Aliq", el("CST", enum("01", "02")), vBC, rate, value)),
+		seq(group(tax+"Qtde", el("CST", enum("03")), el("qBCProd", tDec1204), el("vAliqProd", tDec1104), value)),
+		seq(group(tax+"NT", el("CST", enum("04", "05", "06", "07", "08", "09")))),
+		seq(group(tax+"Outr", el("CST", enum("49", "50", "51", "52", "53", "54", "55", "56", "60", "61", "62", "63",
+			"64", "65", "66", "67", "70", "71", "72", "73", "74", "75", "98", "99")), base, value)),
+	))
+	st := group(tax+"ST", base, value, el("indSoma"+tax+"ST", enum("0", "1")).optional())
+	return main, st
+}
+
+// nfeImposto describes the taxes of an item.
+func nfeImposto() *elementRule {
+	pis, pisST := pisCofinsGroups("PIS")
+	cofins, cofinsST := pisCofinsGroups("COFINS")
+	return group("imposto",
+		el("vTotTrib", tDec1302).optional(),
+		choice(
+			seq(nfeICMS, nfeIPI.optional(), nfeII.optional()),
+			seq(nfeIPI.optional(), nfeISSQN),
+		),
+		pis.optional(),
+		pisST.optional(),
+		cofins.optional(),
+		cofinsST.optional(),
+		group("ICMSUFDest",
+			el("vBCUFDest", tDec1302),
+			el("vBCFCPUFDest", tDec1302).optional(),
+			el("pFCPUFDest", tDec0302a04).optional(),
+			el("pICMSUFDest", tDec0302a04),
+			el("pICMSInter", enum("4.00", "7.00", "12.00")),
+			el("pICMSInterPart", tDec0302a04),
+			el("vFCPUFDest", tDec1302).optional(),
+			el("vICMSUFDest", tDec1302),
+			el("vICMSUFRemet", tDec1302),
+		).optional(),
+		nfeIS.optional(),
+		nfeIBSCBS.optional(),
+	)
+}
+
+// reformRate describes the group of one of the reform taxes of an item (gIBSUF, gIBSMun or gCBS).
+func reformRate(name, rate, value string) *elementRule {
+	return group(name,
+		el(rate, tDec0302a04),
+		group("gDif",
+			el("pDif", tDec0302a04),
+			el("vDif", tDec1302),
+		).optional(),
+		group("gDevTrib",
+			el("vDevTrib", tDec1302),
+		).optional(),
+		group("gRed",
+			el("pRedAliq", tDec0302a04),
+			el("pAliqEfet", tDec0302a04),
+		).optional(),
+		el(value, tDec1302),
+	)
+}
+
+// Tax reform groups of an item (NT 2025.002): the Imposto Seletivo and the IBS and CBS.
+var (
+	nfeIS = group("IS",
+		el("CSTIS", pattern(`[0-9]{3}`)),
+		el("cClassTribIS", pattern(`[0-9]{6}`)),
+		el("vBCIS", tDec1302),
+		el("pIS", tDec0302a04),
+		el("pISEspec", tDec0302a04).optional(),
+		optionalSeq(
+			el("uTrib", tString(1, 6)),
+			el("qTrib", tDec1104v),
+		),
+		el("vIS", tDec1302),
+	)
+	nfeIBSCBS = group("IBSCBS",
+		el("CST", pattern(`[0-9]{3}`)),
+		el("cClassTrib", pattern(`[0-9]{6}`)),
+		group("gIBSCBS",
+			vBC,
+			reformRate("gIBSUF", "pIBSUF", "vIBSUF"),
+			reformRate("gIBSMun", "pIBSMun", "vIBSMun"),
+			el("vIBS", tDec1302),
+			reformRate("gCBS", "pCBS", "vCBS"),
+		).optional(),
+	)
+)
+
+// nfeProd describes the product group of an item.
+var nfeProd = group("prod",
+	el("cProd", tString(1, 60)),
+	el("cEAN", pattern(`SEM GTIN|[0-9]{0}|[0-9]{8}|[0-9]{12,14}`)),
+	el("xProd", tString(1, 120)),
+	el("NCM", pattern(`[0-9]{2}|[0-9]{8}`)),
+	el("NVE", pattern(`[A-Z]{2}[0-9]{4}`)).repeated(0, 8),
+	optionalSeq(
+		el("CEST", pattern(`[0-9]{7}`)),
+		el("indEscala", enum("S", "N")).optional(),
+		el("CNPJFab", tCnpj).optional(),
+	),
+	el("cBenef", pattern(`([!-ÿ]{8}|[!-ÿ]{10}|SEM CBENEF)?`)).optional(),
+	el("EXTIPI", pattern(`[0-9]{2,3}`)).optional(),
+	el("CFOP", pattern(`[1-3,5-7][0-9]{3}`)),
+	el("uCom", tString(1, 6)),
+	el("qCom", tDec1104v),
+	el("vUnCom", tDec1110v),
+	el("vProd", tDec1302),
+	el("cEANTrib", pattern(`SEM GTIN|[0-9]{0}|[0-9]{8}|[0-9]{12,14}`)),
+	el("uTrib", tString(1, 6)),
+	el("qTrib", tDec1104v),
+	el("vUnTrib", tDec1110v),
+	el("vFrete", tDec1302Opc).optional(),
+	el("vSeg", tDec1302Opc).optional(),
+	el("vDesc", tDec1302Opc).optional(),
+	el("vOutro", tDec1302Opc).optional(),
+	el("indTot", enum("0", "1")),
+	group("DI",
+		el("nDI", tString(1, 15)),
+		el("dDI", tData),
+		el("xLocDesemb", tString(1, 60)),
+		el("UFDesemb", tUf),
+		el("dDesemb", tData),
+		el("tpViaTransp", enum("1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13", "14", "15")),
+		el("vAFRMM", tDec1302).optional(),
+		el("tpIntermedio", enum("1", "2", "3")),
+		choice(seq(el("CNPJ", tCnpj)), seq(el("CPF", tCpf))).optional(),
+		el("UFTerceiro", tUf).optional(),
+		el("cExportador", tString(1, 60)),
+		group("adi",
+			el("nAdicao", pattern(`[1-9][0-9]{0,2}`)).optional(),
+			el("nSeqAdic", pattern(`[1-9][0-9]{0,4}`)),
+			el("cFabricante", tString(1, 60)),
+			el("vDescDI", tDec1302Opc).optional(),
+			el("nDraw", pattern(`[0-9]{0,11}`)).optional(),
+		).repeated(1, 999),
+	).repeated(0, 100),
+	group("detExport",
+		el("nDraw", pattern(`[0-9]{0,11}`)).optional(),
+		group("exportInd",
+			el("nRE", pattern(`[0-9]{0,12}`)),
+			el("chNFe", tChNFe),
+			el("qExport", tDec1104v),
+		).optional(),
+	).repeated(0, 500),
+	el("xPed", tString(1, 15)).optional(),
+	el("nItemPed", pattern(`[0-9]{1,6}`)).optional(),
+	el("nFCI", pattern(`[A-F0-9]{8}-[A-F0-9]{4}-[A-F0-9]{4}-[A-F0-9]{4}-[A-F0-9]{12}`)).optional(),
+	group("rastro",
+		el("nLote", tString(1, 20)),
+		el("qLote", tDec0803v),
+		el("dFab", tData),
+		el("dVal", tData),
+		el("cAgreg", tString(1, 20)).optional(),
+	).repeated(0, 500),
+	choice(
+		seq(nfeVeicProd),
+		seq(group("med",
+			el("cProdANVISA", pattern(`[0-9]{11}|[0-9]{13}|ISENTO`)),
+			el("xMotivoIsencao", tString(1, 255)).optional(),
+			el("vPMC", tDec1302),
+		)),
+		seq(group("arma",
+			el("tpArma", enum("0", "1")),
+			el("nSerie", tString(1, 15)),
+			el("nCano", tString(1, 15)),
+			el("descr", tString(1, 256)),
+		).repeated(1, 500)),
+		seq(nfeComb),
+		seq(el("nRECOPI", pattern(`[0-9]{20}`))),
+	).optional(),
+)
+
+// nfeVeicProd describes the new vehicle group of an item.
+var nfeVeicProd = group("veicProd",
+	el("tpOp", enum("0", "1", "2", "3")),
+	el("chassi", pattern(`[A-Z0-9]+`).length(17, 17)),
+	el("cCor", tString(1, 4)),
+	el("xCor", tString(1, 40)),
+	el("pot", tString(1, 4)),
+	el("cilin", tString(1, 4)),
+	el("pesoL", tString(1, 9)),
+	el("pesoB", tString(1, 9)),
+	el("nSerie", tString(1, 9)),
+	el("tpComb", tString(1, 2)),
+	el("nMotor", tString(1, 21)),
+	el("CMT", tString(1, 9)),
+	el("dist", tString(1, 4)),
+	el("anoMod", pattern(`[0-9]{4}`)),
+	el("anoFab", pattern(`[0-9]{4}`)),
+	el("tpPint", tString(1, 1)),
+	el("tpVeic", pattern(`[0-9]{1,2}`)),
+	el("espVeic", pattern(`[0-9]`)),
+	el("VIN", enum("R", "N")),
+	el("condVeic", enum("1", "2", "3")),
+	el("cMod", pattern(`[0-9]{1,6}`)),
+	el("cCorDENATRAN", pattern(`[0-9]{1,2}`)),
+	el("lota", pattern(`[0-9]{1,3}`)),
+	el("tpRest", enum("0", "1", "2", "3", "4", "9")),
+)
+
+// nfeComb describes the fuel group of an item.
+var nfeComb = group("comb",
+	el("cProdANP", pattern(`[0-9]{9}`)),
+	el("descANP", tString(2, 95)),
+	el("pGLP", tDec0302a04M).optional(),
+	el("pGNn", tDec0302a04M).optional(),
+	el("pGNi", tDec0302a04M).optional(),
+	el("vPart", tDec1302).optional(),
+	el("CODIF", pattern(`[0-9]{1,21}`)).optional(),
+	el("qTemp", tDec1204).optional(),
+	el("UFCons", tUf),
+	group("CIDE",
+		el("qBCProd", tDec1204),
+		el("vAliqProd", tDec1104),
+		el("vCIDE", tDec1302),
+	).optional(),
+)
+
+// nfeAddress builds the address group used by the emitter and the recipient.
+func nfeAddress(name string, uf *valueType) *elementRule {
+	return group(name,
+		el("xLgr", tString(2, 60)),
+		el("nro", tString(1, 60)),
+		el("xCpl", tString(1, 60)).optional(),
+		el("xBairro", tString(2, 60)),
+		el("cMun", tCodMunIBGE),
+		el("xMun", tString(2, 60)),
+		el("UF", uf),
+		el("CEP", tCEP).optional(),
+		el("cPais", tCodPais).optional(),
+		el("xPais", tString(1, 60)).optional(),
+		el("fone", tFone).optional(),
+	)
+}
+
+// nfeLocal builds the pickup and delivery location groups.
+func nfeLocal(name string) *elementRule {
+	return group(name,
+		choice(seq(el("CNPJ", tCnpj)), seq(el("CPF", tCpf))),
+		el("xNome", tString(2, 60)).optional(),
+		el("xLgr", tString(2, 60)),
+		el("nro", tString(1, 60)),
+		el("xCpl", tString(1, 60)).optional(),
+		el("xBairro", tString(2, 60)),
+		el("cMun", tCodMunIBGE),
+		el("xMun", tString(2, 60)),
+		el("UF", tUfEX),
+		el("CEP", tCEP).optional(),
+		el("cPais", tCodPais).optional(),
+		el("xPais", tString(1, 60)).optional(),
+		el("fone", tFone).optional(),
+		el("email", tEmail).optional(),
+		el("IE", tIeST).optional(),
+	).optional()
+}
+
+// nfeInfNFe builds the infNFe group for the given model (55 or 65).
+func nfeInfNFe(model string) *elementRule {
+	serie := pattern(`0|[1-9][0-9]{0,2}`)
+	nNF := pattern(`[1-9][0-9]{0,8}`)
+
+	ide := group("ide",
+		el("cUF", tCodUfIBGE),
+		el("cNF", pattern(`[0-9]{8}`)),
+		el("natOp", tString(1, 60)),
+		el("mod", enum(model)),
+		el("serie", serie),
+		el("nNF", nNF),
+		el("dhEmi", tDateTimeUTC),
+		el("dhSaiEnt", tDateTimeUTC).optional(),
+		el("tpNF", enum("0", "1")),
+		el("idDest", enum("1", "2", "3")),
+		el("cMunFG", tCodMunIBGE),
+		el("tpImp", enum("0", "1", "2", "3", "4", "5")),
+		el("tpEmis", enum("1", "2", "3", "4", "5", "6", "7", "9")),
+		el("cDV", pattern(`[0-9]`)),
+		el("tpAmb", enum("1", "2")),
+		el("finNFe", enum("1", "2", "3", "4")),
+		el("indFinal", enum("0", "1")),
+		el("indPres", enum("0", "1", "2", "3", "4", "5", "9")),
+		el("indIntermed", enum("0", "1")).optional(),
+		el("procEmi", enum("0", "1", "2", "3")),
+		el("verProc", tString(1, 20)),
+		optionalSeq(
+			el("dhCont", tDateTimeUTC),
+			el("xJust", tString(15, 256)),
+		),
+		group("NFref", choice(
+			seq(el("refNFe", tChNFe)),
+			seq(el("refNFeSig", tChNFe)),
+			seq(group("refNF",
+				el("cUF", tCodUfIBGE),
+				el("AAMM", pattern(`[0-9]{2}(0[1-9]|1[0-2])`)),
+				el("CNPJ", tCnpj),
+				el("mod", enum("01", "02")),
+				el("serie", serie),
+				el("nNF", nNF),
+			)),
+			seq(group("refNFP",
+				el("cUF", tCodUfIBGE),
+				el("AAMM", pattern(`[0-9]{2}(0[1-9]|1[0-2])`)),
+				choice(seq(el("CNPJ", tCnpj)), seq(el("CPF", tCpf))),
+				el("IE", tIeDest),
+				el("mod", enum("01", "04")),
+				el("serie", serie),
+				el("nNF", nNF),
+			)),
+			seq(el("refCTe", tChNFe)),
+			seq(group("refECF",
+				el("mod", enum("2B", "2C", "2D")),
+				el("nECF", pattern(`[0-9]{1,3}`)),
+				el("nCOO", pattern(`[0-9]{1,6}`)),
+			)),
+		)).repeated(0, 500),
+	)
+
+	emit := group("emit",
+		choice(seq(el("CNPJ", tCnpj)), seq(el("CPF", tCpf))),
+		el("xNome", tString(2, 60)),
+		el("xFant", tString(1, 60)).optional(),
+		nfeAddress("enderEmit", tUf),
+		el("IE", tIe),
+		el("IEST", tIeST).optional(),
+		optionalSeq(
+			el("IM", tString(1, 15)),
+			el("CNAE", pattern(`[0-9]{7}`)).optional(),
+		),
+		el("CRT", enum("1", "2", "3", "4")),
+	)
+
+	dest := group("dest",
+		choice(
+			seq(el("CNPJ", tCnpj)),
+			seq(el("CPF", tCpf)),
+			seq(el("idEstrangeiro", pattern(`([!-ÿ]{0}|[!-ÿ]{5,20})?`))),
+		),
+		el("xNome", tString(2, 60)).optional(),
+		nfeAddress("enderDest", tUfEX).optional(),
+		el("indIEDest", enum("1", "2", "9")),
+		el("IE", tIeDest).optional(),
+		el("ISUF", pattern(`[0-9]{8,9}`)).optional(),
+		el("IM", tString(1, 15)).optional(),
+		el("email", tEmail).optional(),
+	).optional()
+
+	det := group("det",
+		nfeProd,
+		nfeImposto(),
+		group("impostoDevol",
+			el("pDevol", tDec0302Max),
+			group("IPI",
+				el("vIPIDevol", tDec1302),
+			),
+		).optional(),
+		el("infAdProd", tString(1, 500)).optional(),
+	).attr("nItem", pattern(`[1-9][0-9]?|[1-8][0-9]{2}|9[0-8][0-9]|990`)).repeated(1, 990)
+
+	total := group("total",
+		group("ICMSTot",
+			vBC,
+			vICMS,
+			el("vICMSDeson", tDec1302),
+			el("vFCPUFDest", tDec1302).optional(),
+			el("vICMSUFDest", tDec1302).optional(),
+			el("vICMSUFRemet", tDec1302).optional(),
+			el("vFCP", tDec1302),
+			el("vBCST", tDec1302),
+			el("vST", tDec1302),
+			el("vFCPST", tDec1302),
+			el("vFCPSTRet", tDec1302),
+			el("qBCMono", tDec1104).optional(),
+			el("vICMSMono", tDec1302).optional(),
+			el("qBCMonoReten", tDec1104).optional(),
+			el("vICMSMonoReten", tDec1302).optional(),
+			el("qBCMonoRet", tDec1104).optional(),
+			el("vICMSMonoRet", tDec1302).optional(),
+			el("vProd", tDec1302),
+			el("vFrete", tDec1302),
+			el("vSeg", tDec1302),
+			el("vDesc", tDec1302),
+			el("vII", tDec1302),
+			el("vIPI", tDec1302),
+			el("vIPIDevol", tDec1302),
+			el("vPIS", tDec1302),
+			el("vCOFINS", tDec1302),
+			el("vOutro", tDec1302),
+			el("vNF", tDec1302),
+			el("vTotTrib", tDec1302).optional(),
+		),
+		group("ISSQNtot",
+			el("vServ", tDec1302Opc).optional(),
+			el("vBC", tDec1302Opc).optional(),
+			el("vISS", tDec1302Opc).optional(),
+			el("vPIS", tDec1302Opc).optional(),
+			el("vCOFINS", tDec1302Opc).optional(),
+			el("dCompet", tData),
+			el("vDeducao", tDec1302Opc).optional(),
+			el("vOutro", tDec1302Opc).optional(),
+			el("vDescIncond", tDec1302Opc).optional(),
+			el("vDescCond", tDec1302Opc).optional(),
+			el("vISSRet", tDec1302Opc).optional(),
+			el("cRegTrib", enum("1", "2", "3", "4", "5", "6")).optional(),
+		).optional(),
+		group("retTrib",
+			el("vRetPIS", tDec1302).optional(),
+			el("vRetCOFINS", tDec1302).optional(),
+			el("vRetCSLL", tDec1302).optional(),
+			el("vBCIRRF", tDec1302).optional(),
+			el("vIRRF", tDec1302).optional(),
+			el("vBCRetPrev", tDec1302).optional(),
+			el("vRetPrev", tDec1302).optional(),
+		).optional(),
+		group("ISTot",
+			el("vIS", tDec1302),
+		).optional(),
+		group("IBSCBSTot",
+			el("vBCIBSCBS", tDec1302),
+			group("gIBS",
+				group("gIBSUF",
+					el("vDif", tDec1302),
+					el("vDevTrib", tDec1302),
+					el("vIBSUF", tDec1302),
+				),
+				group("gIBSMun",
+					el("vDif", tDec1302),
+					el("vDevTrib", tDec1302),
+					el("vIBSMun", tDec1302),
+				),
+				el("vIBS", tDec1302),
+				el("vCredPres", tDec1302),
+				el("vCredPresCondSus", tDec1302),
+			).optional(),
+			group("gCBS",
+				el("vDif", tDec1302),
+				el("vDevTrib", tDec1302),
+				el("vCBS", tDec1302),
+				el("vCredPres", tDec1302),
+				el("vCredPresCondSus", tDec1302),
+			).optional(),
+		).optional(),
+	)
+
+	plate := pattern(`[A-Z]{2,3}[0-9]{4}|[A-Z]{3,4}[0-9]{3}|[A-Z0-9]{7}`)
+	transp := group("transp",
+		el("modFrete", enum("0", "1", "2", "3", "4", "9")),
+		group("transporta",
+			choice(seq(el("CNPJ", tCnpj)), seq(el("CPF", tCpf))).optional(),
+			el("xNome", tString(2, 60)).optional(),
+			el("IE", tIeDest).optional(),
+			el("xEnder", tString(1, 60)).optional(),
+			el("xMun", tString(1, 60)).optional(),
+			el("UF", tUfEX).optional(),
+		).optional(),
+		group("retTransp",
+			el("vServ", tDec1302),
+			el("vBCRet", tDec1302),
+			el("pICMSRet", tDec0302a04),
+			el("vICMSRet", tDec1302),
+			el("CFOP", pattern(`5351|5352|5353|5354|5355|5356|5357|5359|5360|5931|5932|6351|6352|6353|6354|6355|6356|6357|6359|6360|6931|6932|7358`)),
+			el("cMunFG", tCodMunIBGE),
+		).optional(),
+		choice(
+			seq(
+				group("veicTransp", el("placa", plate), el("UF", tUfEX).optional(), el("RNTC", tString(1, 20)).optional()).optional(),
+				group("reboque", el("placa", plate), el("UF", tUfEX).optional(), el("RNTC", tString(1, 20)).optional()).repeated(0, 5),
+			),
+			seq(el("vagao", tString(1, 20))),
+			seq(el("balsa", tString(1, 20))),
+		).optional(),
+		group("vol",
+			el("qVol", pattern(`[0-9]{1,15}`)).optional(),
+			el("esp", tString(1, 60)).optional(),
+			el("marca", tString(1, 60)).optional(),
+			el("nVol", tString(1, 60)).optional(),
+			el("pesoL", tDec1203).optional(),
+			el("pesoB", tDec1203).optional(),
+			group("lacres", el("nLacre", tString(1, 60))).repeated(0, 5000),
+		).repeated(0, 5000),
+	)
+
+	cobr := group("cobr",
+		group("fat",
+			el("nFat", tString(1, 60)).optional(),
+			el("vOrig", tDec1302).optional(),
+			el("vDesc", tDec1302).optional(),
+			el("vLiq", tDec1302).optional(),
+		).optional(),
+		group("dup",
+			el("nDup", tString(1, 60)).optional(),
+			el("dVenc", tData).optional(),
+			el("vDup", tDec1302Opc),
+		).repeated(0, 120),
+	).optional()
+
+	pag := group("pag",
+		group("detPag",
+			el("indPag", enum("0", "1")).optional(),
+			el("tPag", enum("01", "02", "03", "04", "05", "10", "11", "12", "13", "15", "16", "17", "18", "19", "20", "21", "22", "90", "99")),
+			el("xPag", tString(2, 60)).optional(),
+			el("vPag", tDec1302),
+			el("dPag", tData).optional(),
+			optionalSeq(
+				el("CNPJPag", tCnpj),
+				el("UFPag", tUf),
+			),
+			group("card",
+				el("tpIntegra", enum("1", "2")),
+				el("CNPJ", tCnpj).optional(),
+				el("tBand", pattern(`[0-9]{2}`)).optional(),
+				el("cAut", tString(1, 128)).optional(),
+				el("CNPJReceb", tCnpj).optional(),
+				el("idTermPag", tString(1, 40)).optional(),
+			).optional(),
+		).repeated(1, 100),
+		el("vTroco", tDec1302).optional(),
+	)
+
+	infAdic := group("infAdic",
+		el("infAdFisco", tString(1, 2000)).optional(),
+		el("infCpl", tString(1, 5000)).optional(),
+		group("obsCont", el("xTexto", tString(1, 60))).attr("xCampo", tString(1, 20)).repeated(0, 10),
+		group("obsFisco", el("xTexto", tString(1, 60))).attr("xCampo", tString(1, 20)).repeated(0, 10),
+		group("procRef",
+			el("nProc", tString(1, 60)),
+			el("indProc", enum("0", "1", "2", "3", "4", "9")),
+			el("tpAto", enum("08", "10", "12", "14", "15")).optional(),
+		).repeated(0, 100),
+	).optional()
+
+	return group("infNFe",
+		ide,
+		emit,
+		dest,
+		nfeLocal("retirada"),
+		nfeLocal("entrega"),
+		group("autXML", choice(seq(el("CNPJ", tCnpj)), seq(el("CPF", tCpf)))).repeated(0, 10),
+		det,
+		total,
+		transp,
+		cobr,
+		pag,
+		group("infIntermed",
+			el("CNPJ", tCnpj),
+			el("idCadIntTran", tString(2, 60)),
+		).optional(),
+		infAdic,
+		group("exporta",
+			el("UFSaidaPais", tUf),
+			el("xLocExporta", tString(1, 60)),
+			el("xLocDespacho", tString(1, 60)).optional(),
+		).optional(),
+		group("infRespTec",
+			el("CNPJ", tCnpj),
+			el("xContato", tString(2, 60)),
+			el("email", tEmail.length(6, 60)),
+			el("fone", tFone),
+			optionalSeq(
+				el("idCSRT", pattern(`[0-9]{2}`)),
+				el("hashCSRT", tBase64),
+			),
+		).optional(),
+	).attr("versao", enum("4.00")).attr("Id", pattern(`NFe[0-9]{44}`))
+}
+
+// nfeRoot builds the rule for a NF-e document of the given model,
+// either bare (<NFe>) or wrapped with its authorization protocol (<nfeProc>).
+func nfeRoot(model string) particle {
+	nfe := group("NFe",
+		nfeInfNFe(model),
+		group("infNFeSupl",
+			el("qrCode", tString(100, 600)),
+			el("urlChave", tString(21, 85)),
+		).optional(),
+		signatureRule(),
+	)
+
+	protNFe := group("protNFe",
+		group("infProt",
+			el("tpAmb", enum("1", "2")),
+			el("verAplic", tString(1, 20)),
+			el("chNFe", tChNFe),
+			el("dhRecbto", tDateTimeUTC),
+			el("nProt", pattern(`[0-9]{15}`)).optional(),
+			el("digVal", tBase64).optional(),
+			el("cStat", pattern(`[0-9]{3}`)),
+			el("xMotivo", tString(1, 255)),
+			optionalSeq(
+				el("cMsg", pattern(`[0-9]{1,4}`)),
+				el("xMsg", tString(1, 200)),
+			),
+		).optionalAttr("Id", pattern(`ID[0-9]{15}`)),
+	).attr("versao", enum("4.00"))
+
+	return choice(
+		seq(group("nfeProc", nfe, protNFe).attr("versao", enum("4.00"))),
+		seq(nfe),
+	)
+}
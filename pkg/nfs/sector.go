@@ -0,0 +1,370 @@
+package nfs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+// ProductSector is a sector of goods whose NF-e items carry a specific group. The sectors are flags,
+// which may be combined (e.g. Fuel | Medicine) to give the items to each of them in turn.
+type ProductSector int
+
+const (
+	// Fuel is fuels sold by distributors and gas stations, with the comb group (ANP product code).
+	Fuel ProductSector = 1 << iota
+	// Medicine is medicines for human use, with the med group (ANVISA registration) and the batch (rastro).
+	Medicine
+	// NewVehicle is new vehicles sold by manufacturers and dealers, with the veicProd group.
+	NewVehicle
+	// Weapon is firearms, with an arma group for each unit.
+	Weapon
+)
+
+// productSectors lists each ProductSector with its name, in the order the items are given to them.
+var productSectors = []struct {
+	sector ProductSector
+	name   string
+}{
+	{Fuel, "Fuel"},
+	{Medicine, "Medicine"},
+	{NewVehicle, "NewVehicle"},
+	{Weapon, "Weapon"},
+}
+
+// fuelProduct is a fuel with its product code and description in the ANP table (SIMP).
+type fuelProduct struct {
+	catalogProduct
+	anp     string
+	descANP string
+}
+
+// fuels are the fuels of the embedded ANP table.
+var fuels = []fuelProduct{
+	{catalogProduct{ncm: "27101259", description: "Gasolina C comum", unit: "LT", price: 629, fractional: true}, "320102001", "GASOLINA C COMUM"},
+	{catalogProduct{ncm: "27101259", description: "Gasolina C aditivada", unit: "LT", price: 649, fractional: true}, "320103001", "GASOLINA C ADITIVADA"},
+	{catalogProduct{ncm: "22071090", description: "Etanol hidratado combustivel", unit: "LT", price: 429, fractional: true}, "810101001", "ETANOL HIDRATADO COMUM"},
+	{catalogProduct{ncm: "27101921", description: "Oleo diesel B S10", unit: "LT", price: 609, fractional: true}, "820101034", "OLEO DIESEL B S10 - COMUM"},
+	{catalogProduct{ncm: "27101921", description: "Oleo diesel B S500", unit: "LT", price: 589, fractional: true}, "820101012", "OLEO DIESEL B S500 - COMUM"},
+	{catalogProduct{ncm: "27112100", description: "Gas natural veicular", unit: "M3", price: 499, fractional: true}, "220101003", "GAS NATURAL VEICULAR"},
+	{catalogProduct{ncm: "27111910", description: "Gas liquefeito de petroleo botijao P13", unit: "UN", price: 11000}, "210203001", "GLP"},
+}
+
+// glpANP is the ANP code of the liquefied petroleum gas, which informs the share of its origins.
+const glpANP = "210203001"
+
+// medicines are medicines for human use, sold by the box.
+var medicines = []catalogProduct{
+	{ncm: "30049099", description: "Dipirona monoidratada 500mg 10 comprimidos", unit: "CX", price: 690},
+	{ncm: "30049099", description: "Paracetamol 750mg 20 comprimidos", unit: "CX", price: 1290},
+	{ncm: "30049099", description: "Losartana potassica 50mg 30 comprimidos", unit: "CX", price: 1990},
+	{ncm: "30049099", description: "Omeprazol 20mg 28 capsulas", unit: "CX", price: 2490},
+	{ncm: "30049099", description: "Ibuprofeno 600mg 20 comprimidos", unit: "CX", price: 1890},
+	{ncm: "30042099", description: "Azitromicina 500mg 3 comprimidos", unit: "CX", price: 3290},
+}
+
+// vehicleModel is a new vehicle with the data of its model in the RENAVAM: the manufacturer (WMI of
+// the chassis), power (CV), displacement (cm3), fuel, type, species, capacity, wheelbase (mm), net and
+// gross weights and maximum traction (t).
+type vehicleModel struct {
+	catalogProduct
+	wmi, pot, cilin, tpComb, tpVeic, espVeic, lota, dist, pesoL, pesoB, CMT string
+}
+
+// vehicleModels are the models of new vehicles.
+var vehicleModels = []vehicleModel{
+	{catalogProduct{ncm: "87032100", description: "Automovel hatch 1.0 flex 0km", unit: "UN", price: 8490000},
+		"9BW", "84", "999", "16", "06", "1", "5", "2566", "1.0310", "1.4500", "1.4500"},
+	{catalogProduct{ncm: "87032310", description: "Automovel sedan 1.6 flex 0km", unit: "UN", price: 10990000},
+		"9BD", "117", "1598", "16", "06", "1", "5", "2637", "1.1500", "1.5900", "1.5900"},
+	{catalogProduct{ncm: "87042190", description: "Caminhonete cabine dupla 2.8 diesel 4x4 0km", unit: "UN", price: 26990000},
+		"9BG", "200", "2776", "03", "23", "2", "5", "3096", "2.0100", "3.0500", "3.5000"},
+	{catalogProduct{ncm: "87112020", description: "Motocicleta 160cc flex 0km", unit: "UN", price: 1699000},
+		"9C2", "15", "162", "16", "04", "1", "2", "1335", "0.1380", "0.2980", "0.2980"},
+}
+
+// vehicleColors are the DENATRAN color codes with their names.
+var vehicleColors = []struct{ code, name string }{
+	{"02", "AZUL"}, {"04", "BRANCA"}, {"05", "CINZA"}, {"10", "PRATA"}, {"11", "PRETA"}, {"15", "VERMELHA"},
+}
+
+// weaponModel is a firearm with the full description of the arma group and whether it is of
+// permitted (0) or restricted (1) use.
+type weaponModel struct {
+	catalogProduct
+	tpArma string
+	descr  string
+}
+
+// weapons are the firearms.
+var weapons = []weaponModel{
+	{catalogProduct{ncm: "93020000", description: "Pistola semiautomatica calibre .380 ACP", unit: "UN", price: 549000},
+		"0", "Pistola semiautomatica calibre .380 ACP, capacidade 15 tiros, cano de 102 mm, acabamento oxidado"},
+	{catalogProduct{ncm: "93020000", description: "Revolver calibre .38 SPL", unit: "UN", price: 469000},
+		"0", "Revolver calibre .38 SPL, capacidade 6 tiros, cano de 4 polegadas, acabamento inox"},
+	{catalogProduct{ncm: "93020000", description: "Pistola semiautomatica calibre 9mm", unit: "UN", price: 689000},
+		"1", "Pistola semiautomatica calibre 9x19mm, capacidade 17 tiros, cano de 114 mm, acabamento oxidado"},
+	{catalogProduct{ncm: "93032000", description: "Espingarda calibre 12 de repeticao", unit: "UN", price: 529000},
+		"0", "Espingarda calibre 12 de repeticao por acao de bombeamento, capacidade 7 tiros, cano de 610 mm"},
+}
+
+// sectorItem holds the values of the specific group of an item of a ProductSector.
+type sectorItem struct {
+	sector ProductSector
+
+	// Fuel
+	fuel  fuelProduct
+	CODIF string
+
+	// Medicine, with its batch
+	anvisa string
+	vPMC   cents
+	nLote  string
+	dFab   time.Time
+	dVal   time.Time
+
+	// NewVehicle
+	vehicle      vehicleModel
+	tpOp         string
+	chassis      string
+	cCor         string
+	color        int
+	tpPint       string
+	nMotor       string
+	anoFab       int
+	anoMod       int
+	cMod         string
+	tpRest       string
+	vehicleSerie string
+
+	// Weapon, with the serial numbers of the weapon and of its barrel for each unit
+	weapon  weaponModel
+	serials [][2]string
+}
+
+// String returns the names of the sectors, joined by "|".
+func (s ProductSector) String() string {
+	var names []string
+	for _, known := range productSectors {
+		if s&known.sector != 0 {
+			names = append(names, known.name)
+		}
+	}
+	if len(names) == 0 || s&^allProductSectors() != 0 {
+		return "Unknown"
+	}
+	return strings.Join(names, "|")
+}
+
+// ParseProductSector converts a string (e.g. "Fuel" or "Fuel|Medicine", also separated by commas)
+// to a ProductSector.
+func ParseProductSector(s string) (ProductSector, error) {
+	var sectors ProductSector
+	for _, name := range strings.FieldsFunc(s, func(r rune) bool { return r == '|' || r == ',' }) {
+		found := false
+		for _, known := range productSectors {
+			if known.name == strings.TrimSpace(name) {
+				sectors |= known.sector
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid ProductSector: %s", s)
+		}
+	}
+	if sectors == 0 {
+		return 0, fmt.Errorf("invalid ProductSector: %s", s)
+	}
+	return sectors, nil
+}
+
+// allProductSectors returns every known sector.
+func allProductSectors() ProductSector {
+	var all ProductSector
+	for _, known := range productSectors {
+		all |= known.sector
+	}
+	return all
+}
+
+// checkProductSectors fails when the sectors cannot be issued in a document of the TemplateType.
+// The NFC-e of gas stations and pharmacies carries fuels and medicines only.
+func checkProductSectors(tt TemplateType, sectors ProductSector) error {
+	if sectors == 0 {
+		return nil
+	}
+	if sectors&^allProductSectors() != 0 {
+		return fmt.Errorf("unknown product sector: %d", sectors)
+	}
+	if tt != NFe && tt != NFCe {
+		return fmt.Errorf("product sectors are not supported for %v documents", tt)
+	}
+	if tt == NFCe && sectors&(NewVehicle|Weapon) != 0 {
+		return fmt.Errorf("vehicles and weapons are not supported for NFCe documents")
+	}
+	return nil
+}
+
+// sectorList returns the sectors in the order the items are given to them.
+func sectorList(sectors ProductSector) []ProductSector {
+	var list []ProductSector
+	for _, known := range productSectors {
+		if sectors&known.sector != 0 {
+			list = append(list, known.sector)
+		}
+	}
+	return list
+}
+
+// itemSector returns the sector of the item with the index, or 0 when no sector is chosen.
+func itemSector(sectors ProductSector, index int) ProductSector {
+	list := sectorList(sectors)
+	if len(list) == 0 {
+		return 0
+	}
+	return list[index%len(list)]
+}
+
+// sectorProduct makes the item a product of the sector, with the values of its specific group.
+// Vehicles are sold one per item, as each has its own chassis, and weapons by the unit.
+func (i *mockItem) sectorProduct(doc *mockDocument, sector ProductSector) {
+	s := &sectorItem{sector: sector}
+	switch sector {
+	case Fuel:
+		s.fuel = fuels[gofakeit.Number(0, len(fuels)-1)]
+		s.CODIF = gofakeit.Numerify("############")
+		i.product = s.fuel.catalogProduct
+		if i.product.fractional {
+			i.quantity = int64(gofakeit.Number(50000, 5000000)) // liters or cubic meters, up to a tank truck
+		}
+	case Medicine:
+		i.product = medicines[gofakeit.Number(0, len(medicines)-1)]
+		s.anvisa = "1" + gofakeit.Numerify("############")
+		s.nLote = gofakeit.Numerify("L#####") + gofakeit.RandomString([]string{"A", "B", "C"})
+		s.dFab = doc.dhEmi.AddDate(0, 0, -gofakeit.Number(30, 540))
+		s.dVal = s.dFab.AddDate(2, 0, 0)
+	case NewVehicle:
+		s.vehicle = vehicleModels[gofakeit.Number(0, len(vehicleModels)-1)]
+		s.anoFab = doc.dhEmi.Year()
+		s.anoMod = s.anoFab + gofakeit.Number(0, 1)
+		s.chassis = br_documents.Chassis(s.vehicle.wmi, s.anoMod)
+		s.vehicleSerie = s.chassis[11:]
+		s.tpOp = gofakeit.RandomString([]string{"1", "1", "2", "3"})
+		s.color = gofakeit.Number(0, len(vehicleColors)-1)
+		s.cCor = gofakeit.Numerify("####")
+		s.tpPint = gofakeit.RandomString([]string{"S", "M"}) // sólida or metálica
+		s.nMotor = gofakeit.Letter() + gofakeit.Numerify("##########")
+		s.cMod = gofakeit.Numerify("######")
+		s.tpRest = gofakeit.RandomString([]string{"0", "0", "1"}) // none or alienação fiduciária
+		i.product = s.vehicle.catalogProduct
+		i.quantity = 10000
+	case Weapon:
+		s.weapon = weapons[gofakeit.Number(0, len(weapons)-1)]
+		i.product = s.weapon.catalogProduct
+		i.quantity = int64(gofakeit.Number(1, 3)) * 10000
+		for range i.quantity / 10000 {
+			prefix := strings.ToUpper(gofakeit.Letter() + gofakeit.Letter() + gofakeit.Letter())
+			s.serials = append(s.serials, [2]string{prefix + gofakeit.Numerify("######"), prefix + gofakeit.Numerify("######")})
+		}
+	}
+	i.sector = s
+	i.gtin = productGTIN(i.product)
+	if sector == NewVehicle {
+		i.gtin = "" // vehicles are identified by the chassis
+	}
+	i.unitValue = i.product.price.applyRate(gofakeit.Number(9000, 11000))
+	if sector == Medicine {
+		// The maximum consumer price (PMC) stays above the selling price.
+		s.vPMC = i.unitValue.applyRate(gofakeit.Number(12000, 14000))
+	}
+}
+
+// rastroGroupXML renders the batch of the item, which is informed for medicines only.
+func (i *mockItem) rastroGroupXML() string {
+	if i.sector == nil || i.sector.sector != Medicine {
+		return ""
+	}
+	return xmlGroup("rastro", []xmlElement{
+		{"nLote", i.sector.nLote},
+		{"qLote", fmt.Sprintf("%d.%03d", i.quantity/10000, i.quantity%10000/10)},
+		{"dFab", i.sector.dFab.Format("2006-01-02")},
+		{"dVal", i.sector.dVal.Format("2006-01-02")},
+	})
+}
+
+// sectorGroupXML renders the specific group of the item (comb, med, veicProd or arma), which is empty
+// outside of the product sectors. Fuels are consumed in the recipient state and, for the LPG, the
+// share of its origins and the starting value of the container (vPart) are informed.
+func (doc *mockDocument) sectorGroupXML() string {
+	i := doc.item
+	if i.sector == nil {
+		return ""
+	}
+	s := i.sector
+	x := &xmlBuilder{}
+	switch s.sector {
+	case Fuel:
+		x.open("comb")
+		x.element("cProdANP", s.fuel.anp)
+		x.element("descANP", s.fuel.descANP)
+		if s.fuel.anp == glpANP {
+			x.element("pGLP", "100.0000")
+			x.element("pGNn", "0.0000")
+			x.element("pGNi", "0.0000")
+			x.element("vPart", i.unitValue.String())
+		}
+		x.element("CODIF", s.CODIF)
+		uf := doc.dest.city.uf
+		if uf == exterior.uf {
+			uf = doc.emit.city.uf
+		}
+		x.element("UFCons", uf)
+		x.close("comb")
+	case Medicine:
+		x.open("med")
+		x.element("cProdANVISA", s.anvisa)
+		x.element("vPMC", s.vPMC.String())
+		x.close("med")
+	case NewVehicle:
+		color := vehicleColors[s.color]
+		x.open("veicProd")
+		x.element("tpOp", s.tpOp)
+		x.element("chassi", s.chassis)
+		x.element("cCor", s.cCor)
+		x.element("xCor", color.name)
+		x.element("pot", s.vehicle.pot)
+		x.element("cilin", s.vehicle.cilin)
+		x.element("pesoL", s.vehicle.pesoL)
+		x.element("pesoB", s.vehicle.pesoB)
+		x.element("nSerie", s.vehicleSerie)
+		x.element("tpComb", s.vehicle.tpComb)
+		x.element("nMotor", s.nMotor)
+		x.element("CMT", s.vehicle.CMT)
+		x.element("dist", s.vehicle.dist)
+		x.element("anoMod", fmt.Sprint(s.anoMod))
+		x.element("anoFab", fmt.Sprint(s.anoFab))
+		x.element("tpPint", s.tpPint)
+		x.element("tpVeic", s.vehicle.tpVeic)
+		x.element("espVeic", s.vehicle.espVeic)
+		x.element("VIN", "N") // N = normal, not re-stamped
+		x.element("condVeic", "1")
+		x.element("cMod", s.cMod)
+		x.element("cCorDENATRAN", color.code)
+		x.element("lota", s.vehicle.lota)
+		x.element("tpRest", s.tpRest)
+		x.close("veicProd")
+	case Weapon:
+		for _, serial := range s.serials {
+			x.open("arma")
+			x.element("tpArma", s.weapon.tpArma)
+			x.element("nSerie", serial[0])
+			x.element("nCano", serial[1])
+			x.element("descr", s.weapon.descr)
+			x.close("arma")
+		}
+	}
+	return x.String()
+}
@@ -0,0 +1,124 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+func TestParseProductSector(t *testing.T) {
+	for _, sectors := range []ProductSector{Fuel, Medicine, NewVehicle, Weapon, Fuel | Medicine, Fuel | Medicine | NewVehicle | Weapon} {
+		parsed, err := ParseProductSector(sectors.String())
+		if err != nil || parsed != sectors {
+			t.Errorf("Expected %v, got %v (%v)", sectors, parsed, err)
+		}
+	}
+	if parsed, err := ParseProductSector("Fuel,Weapon"); err != nil || parsed != Fuel|Weapon {
+		t.Errorf("Expected Fuel|Weapon, got %v (%v)", parsed, err)
+	}
+	for _, invalid := range []string{"", "Food", "Fuel|Food"} {
+		if _, err := ParseProductSector(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestWithProductSector_Groups(t *testing.T) {
+	tests := []struct {
+		sector ProductSector
+		groups []string
+	}{
+		{Fuel, []string{"<comb>", "<cProdANP>", "<UFCons>"}},
+		{Medicine, []string{"<rastro>", "<nLote>", "<med>", "<cProdANVISA>"}},
+		{NewVehicle, []string{"<veicProd>", "<chassi>", "<cCorDENATRAN>"}},
+		{Weapon, []string{"<arma>", "<nSerie>", "<nCano>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sector.String(), func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				xmlBytes, err := NewNFeGenerator().Generate(WithProductSector(tt.sector))
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				document := string(xmlBytes)
+				for _, group := range tt.groups {
+					if !strings.Contains(document, group) {
+						t.Fatalf("Expected %s in the item\n%s", group, document)
+					}
+				}
+				if errs := Validate(NFe, xmlBytes); len(errs) > 0 {
+					t.Fatalf("Expected no schema violations, got %v", errs)
+				}
+			}
+		})
+	}
+}
+
+func TestWithProductSector_Items(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		doc, _ := newMockDocument(NFe, &generationConfig{sectors: Fuel | Medicine | NewVehicle | Weapon})
+		if len(doc.items) != 4 {
+			t.Fatalf("Expected an item of each sector, got %d", len(doc.items))
+		}
+		for index, item := range doc.items {
+			s := item.sector
+			if s == nil || s.sector != itemSector(Fuel|Medicine|NewVehicle|Weapon, index) {
+				t.Fatalf("Expected the item %d in its sector, got %+v", index+1, s)
+			}
+			switch s.sector {
+			case Fuel:
+				if len(s.fuel.anp) != 9 || item.product.ncm != s.fuel.ncm {
+					t.Errorf("Expected the ANP code of the fuel, got %+v", s.fuel)
+				}
+			case Medicine:
+				if len(s.anvisa) != 13 || s.vPMC <= item.unitValue {
+					t.Errorf("Expected the ANVISA registration and a PMC above %v, got %+v", item.unitValue, s)
+				}
+				if !s.dFab.Before(doc.dhEmi) || !s.dVal.After(doc.dhEmi) {
+					t.Errorf("Expected the batch made before %v and valid after it, got %v to %v", doc.dhEmi, s.dFab, s.dVal)
+				}
+			case NewVehicle:
+				if !br_documents.ValidateChassis(s.chassis) || !strings.HasPrefix(s.chassis, s.vehicle.wmi) {
+					t.Errorf("Expected a valid chassis of %s, got %s", s.vehicle.wmi, s.chassis)
+				}
+				if item.quantity != 10000 || item.gtin != "" {
+					t.Errorf("Expected a single vehicle without GTIN, got %d", item.quantity)
+				}
+			case Weapon:
+				if int64(len(s.serials)) != item.quantity/10000 {
+					t.Errorf("Expected an arma group for each of the %d units, got %d", item.quantity/10000, len(s.serials))
+				}
+			}
+		}
+	}
+}
+
+func TestWithProductSector_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator TemplateGenerator
+		sectors   ProductSector
+	}{
+		{"CFe", NewCFeGenerator(), Fuel},
+		{"devolução", NewNFeDevolucaoGenerator(), Medicine},
+		{"vehicle in an NFC-e", NewNFCeGenerator(), NewVehicle},
+		{"weapon in an NFC-e", NewNFCeGenerator(), Fuel | Weapon},
+		{"unknown sector", NewNFeGenerator(), ProductSector(64)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.generator.Generate(WithProductSector(tt.sectors)); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+
+	xmlBytes, err := NewNFCeGenerator().Generate(WithProductSector(Fuel | Medicine))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if errs := Validate(NFCe, xmlBytes); len(errs) > 0 {
+		t.Fatalf("Expected no schema violations, got %v", errs)
+	}
+}
@@ -0,0 +1,170 @@
+package nfs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// maxNNF is the greatest number (nNF) of a series.
+const maxNNF = 999999999
+
+// Sequence is the numbering of a series shared by a batch of documents, as the stream of one
+// emitter: each document takes the next number (nNF) of the series and is emitted after the one
+// before it, by the same emitter. Numbers may be skipped on purpose, leaving gaps in the stream.
+// A Sequence is safe for concurrent use.
+type Sequence struct {
+	mu       sync.Mutex
+	serie    int
+	next     int
+	gapRate  int // the chance, in percent, of skipping numbers before each document
+	skipped  []int
+	model    string
+	emit     *mockParty // the emitter of the first document
+	emitName string
+	last     time.Time // the emission of the last document
+}
+
+// NewSequence returns a Sequence of the series (0 to 999) whose first document takes the number
+// startNNF (1 to 999999999).
+func NewSequence(serie, startNNF int) *Sequence {
+	return &Sequence{serie: serie, next: startNNF}
+}
+
+// Skip leaves the next count numbers unused, as a gap in the stream. It fails, skipping nothing,
+// when the gap goes past the last number of the series.
+func (s *Sequence) Skip(count int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.skip(count)
+}
+
+// SetGapRate makes each document skip from 1 to 5 numbers before its own with the chance given in
+// percent (0 to 100).
+func (s *Sequence) SetGapRate(percent int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gapRate = percent
+}
+
+// Skipped returns the numbers left unused so far, in order: the ones whose use must be voided
+// (inutilização) at SEFAZ.
+func (s *Sequence) Skipped() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.skipped...)
+}
+
+// skip leaves the next count numbers unused, or fails when the gap goes past the last number of the series.
+func (s *Sequence) skip(count int) error {
+	if s.next+count-1 > maxNNF {
+		return fmt.Errorf("series %d exhausted: cannot skip %d numbers from %d (up to %d)", s.serie, count, s.next, maxNNF)
+	}
+	for i := 0; i < count; i++ {
+		s.skipped = append(s.skipped, s.next)
+		s.next++
+	}
+	return nil
+}
+
+// checkSequence fails when the documents of the TemplateType cannot take the numbers of the sequence:
+// CF-e documents are numbered by the SAT equipment, a series is numbered for a single model, and the
+// emission must be after the last document of the sequence.
+func checkSequence(tt TemplateType, cfg *generationConfig) error {
+	s := cfg.sequence
+	if s == nil {
+		return nil
+	}
+	if tt == CFe {
+		return fmt.Errorf("sequences are not supported for %v documents, numbered by the SAT", tt)
+	}
+	if cfg.referencedInvoice != nil {
+		return fmt.Errorf("sequences are not supported with a referenced invoice, whose emitter is kept")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.serie < 0 || s.serie > 999 {
+		return fmt.Errorf("invalid series: %d (from 0 to 999)", s.serie)
+	}
+	if s.next < 1 || s.next > maxNNF {
+		return fmt.Errorf("invalid number: %d (from 1 to %d)", s.next, maxNNF)
+	}
+	model := "55"
+	if tt == NFCe {
+		model = "65"
+	}
+	if s.model != "" && s.model != model {
+		return fmt.Errorf("%v documents cannot share the series %d of model %s", tt, s.serie, s.model)
+	}
+	if cfg.emissionDate && !s.last.IsZero() && !cfg.emissionTo.After(s.last) {
+		return fmt.Errorf("invalid emission date: the last document of the sequence was emitted at %s",
+			s.last.Format(dateTimeLayout))
+	}
+	return nil
+}
+
+// emitterUF returns the state of the emitter of the sequence, or uf before its first document.
+func (s *Sequence) emitterUF(uf string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emit == nil {
+		return uf
+	}
+	return s.emit.city.uf
+}
+
+// keepEmitter makes the document issued by the emitter of the sequence, which is the emitter of
+// its first document.
+func (s *Sequence) keepEmitter(doc *mockDocument) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emit == nil {
+		emit := doc.emit
+		s.emit, s.emitName = &emit, doc.emitName
+		return
+	}
+	doc.emit, doc.emitName = *s.emit, s.emitName
+}
+
+// number gives the document the next number of the sequence, skipping numbers at the gap rate, and
+// fails when the series is exhausted. Documents after the first are emitted up to ten minutes after
+// the one before and within the emission range of the config, or never in the future without one
+// when there is time left.
+func (s *Sequence) number(doc *mockDocument, cfg *generationConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next > maxNNF {
+		return fmt.Errorf("series %d exhausted: the number %d was the last one", s.serie, maxNNF)
+	}
+	if s.gapRate > 0 && gofakeit.Number(1, 100) <= s.gapRate {
+		// A gap never takes the last number left for the document.
+		if err := s.skip(min(gofakeit.Number(1, 5), maxNNF-s.next)); err != nil {
+			return err
+		}
+	}
+	doc.serie, doc.nNF = s.serie, s.next
+	s.next++
+	s.model = doc.model
+
+	if !s.last.IsZero() {
+		from, to := s.last.Add(time.Second), time.Now().In(brasilia).Truncate(time.Second)
+		if cfg.emissionDate {
+			to = cfg.emissionTo.Truncate(time.Second)
+			if start := cfg.emissionFrom.Truncate(time.Second); start.After(from) {
+				from = start
+			}
+		}
+		if to.Before(from) {
+			to = from
+		}
+		if limit := from.Add(599 * time.Second); to.After(limit) {
+			to = limit
+		}
+		doc.dhEmi = from.Add(time.Duration(gofakeit.Number(0, int(to.Sub(from)/time.Second))) * time.Second)
+	}
+	s.last = doc.dhEmi
+	return nil
+}
@@ -0,0 +1,203 @@
+package nfs
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithSequence(t *testing.T) {
+	numberRe := regexp.MustCompile(`<serie>([0-9]+)</serie>\s*<nNF>([0-9]+)</nNF>\s*<dhEmi>([^<]+)</dhEmi>`)
+	emitRe := regexp.MustCompile(`<emit>\s*<(?:CNPJ|CPF)>([0-9]+)</`)
+	for _, tt := range []TemplateType{NFe, NFCe, NFeDevolucao} {
+		t.Run(tt.String(), func(t *testing.T) {
+			generator, err := NewTemplateGenerator(tt)
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			sequence := NewSequence(7, 1000)
+			sequence.SetGapRate(20)
+			var numbers []int
+			var emitter string
+			var last time.Time
+			for i := 0; i < 30; i++ {
+				if i == 10 {
+					if err := sequence.Skip(3); err != nil {
+						t.Fatalf("Expected no error, got %v", err)
+					}
+				}
+				xmlBytes, err := generator.Generate(WithSequence(sequence))
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				document := string(xmlBytes)
+				match := numberRe.FindStringSubmatch(document)
+				if match == nil || match[1] != "7" {
+					t.Fatalf("Expected the series 7, got %v", match)
+				}
+				number, _ := strconv.Atoi(match[2])
+				numbers = append(numbers, number)
+				dhEmi, _ := time.Parse(dateTimeLayout, match[3])
+				if !last.IsZero() && !dhEmi.After(last) {
+					t.Errorf("Expected the emission after %v, got %v", last, dhEmi)
+				}
+				last = dhEmi
+				if cnpj := emitRe.FindStringSubmatch(document)[1]; emitter == "" {
+					emitter = cnpj
+				} else if cnpj != emitter {
+					t.Errorf("Expected the emitter %s of the sequence, got %s", emitter, cnpj)
+				}
+				if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+					t.Fatalf("Expected no schema violations, got %v", errs)
+				}
+			}
+
+			// The numbers used and skipped make up the whole stream, without repetitions.
+			skipped := sequence.Skipped()
+			if len(skipped) < 3 {
+				t.Errorf("Expected at least the 3 skipped numbers, got %v", skipped)
+			}
+			stream := slices.Sorted(slices.Values(append(slices.Clone(numbers), skipped...)))
+			for i, number := range stream {
+				if number != 1000+i {
+					t.Fatalf("Expected a stream of consecutive numbers from 1000, got %v", stream)
+				}
+			}
+			if !slices.IsSorted(numbers) {
+				t.Errorf("Expected increasing numbers, got %v", numbers)
+			}
+		})
+	}
+}
+
+func TestWithSequence_Concurrent(t *testing.T) {
+	sequence := NewSequence(1, 1)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := NewNFCeGenerator().Generate(WithSequence(sequence)); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if doc, _ := newMockDocument(NFCe, &generationConfig{sequence: sequence}); doc.nNF != 21 {
+		t.Errorf("Expected the number 21 after 20 documents, got %d", doc.nNF)
+	}
+}
+
+func TestWithNumbering(t *testing.T) {
+	xmlBytes, err := NewNFeGenerator().Generate(WithNumbering(0, 123456789))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !regexp.MustCompile(`<serie>0</serie>\s*<nNF>123456789</nNF>`).Match(xmlBytes) {
+		t.Errorf("Expected the series 0 and the number 123456789\n%s", xmlBytes)
+	}
+	if !regexp.MustCompile(`Id="NFe[0-9]{20}55000123456789`).Match(xmlBytes) {
+		t.Errorf("Expected the numbering in the access key\n%s", xmlBytes)
+	}
+}
+
+func TestWithSequence_EmissionDate(t *testing.T) {
+	numberRe := regexp.MustCompile(`<nNF>([0-9]+)</nNF>\s*<dhEmi>([^<]+)</dhEmi>`)
+	first := time.Date(2025, 5, 1, 10, 0, 0, 0, brasilia)
+	ranges := []struct {
+		from, to time.Time
+	}{
+		{first, first},
+		{first.Add(2 * time.Hour), first.Add(3 * time.Hour)}, // starts after the last document
+		{first, first.Add(24 * time.Hour)},                   // starts before the last document
+		{first.Add(5 * 24 * time.Hour), first.Add(6 * 24 * time.Hour)},
+	}
+
+	sequence := NewSequence(1, 1)
+	var last time.Time
+	for i := 0; i < 20; i++ {
+		r := ranges[min(i, len(ranges)-1)]
+		option := WithEmissionDateRange(r.from, r.to)
+		if r.from.Equal(r.to) {
+			option = WithEmissionDate(r.from)
+		}
+		xmlBytes, err := NewNFeGenerator().Generate(WithSequence(sequence), option)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		match := numberRe.FindSubmatch(xmlBytes)
+		dhEmi, _ := time.Parse(dateTimeLayout, string(match[2]))
+		if dhEmi.Before(r.from) || dhEmi.After(r.to) {
+			t.Errorf("Expected the document %s emitted from %v to %v, got %v", match[1], r.from, r.to, dhEmi)
+		}
+		if !last.IsZero() && !dhEmi.After(last) {
+			t.Errorf("Expected the document %s emitted after %v, got %v", match[1], last, dhEmi)
+		}
+		last = dhEmi
+	}
+}
+
+func TestSequence_Exhausted(t *testing.T) {
+	sequence := NewSequence(1, maxNNF-2)
+	if err := sequence.Skip(4); err == nil {
+		t.Errorf("Expected an error skipping past the last number")
+	}
+	if err := sequence.Skip(2); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sequence.SetGapRate(100)
+	xmlBytes, err := NewNFeGenerator().Generate(WithSequence(sequence))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !regexp.MustCompile(`<nNF>999999999</nNF>`).Match(xmlBytes) {
+		t.Errorf("Expected the last number of the series\n%s", xmlBytes)
+	}
+	if _, err := NewNFeGenerator().Generate(WithSequence(sequence)); err == nil {
+		t.Errorf("Expected an error after the last number of the series")
+	}
+	if err := sequence.Skip(1); err == nil {
+		t.Errorf("Expected an error skipping past the last number")
+	}
+	if skipped := sequence.Skipped(); len(skipped) != 2 || skipped[1] != maxNNF-1 {
+		t.Errorf("Expected the numbers %d and %d skipped, got %v", maxNNF-2, maxNNF-1, skipped)
+	}
+}
+
+func TestWithSequence_Errors(t *testing.T) {
+	referenced, err := NewNFeGenerator().Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate the referenced invoice: %v", err)
+	}
+	nfce := NewSequence(1, 1)
+	if _, err := NewNFCeGenerator().Generate(WithSequence(nfce)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	emitted := NewSequence(1, 1)
+	if _, err := NewNFeGenerator().Generate(WithSequence(emitted), WithEmissionDate(time.Date(2025, 5, 1, 10, 0, 0, 0, brasilia))); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		generator TemplateGenerator
+		options   []Option
+	}{
+		{"CFe", NewCFeGenerator(), []Option{WithNumbering(1, 1)}},
+		{"referenced invoice", NewNFeDevolucaoGenerator(), []Option{WithNumbering(1, 1), WithReferencedInvoice(referenced)}},
+		{"invalid series", NewNFeGenerator(), []Option{WithNumbering(1000, 1)}},
+		{"invalid number", NewNFeGenerator(), []Option{WithNumbering(1, 0)}},
+		{"another model", NewNFeGenerator(), []Option{WithSequence(nfce)}},
+		{"emission before the last", NewNFeGenerator(), []Option{WithSequence(emitted), WithEmissionDate(time.Date(2025, 4, 1, 10, 0, 0, 0, brasilia))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.generator.Generate(tt.options...); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
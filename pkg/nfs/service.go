@@ -0,0 +1,161 @@
+package nfs
+
+import (
+	"fmt"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// serviceListItem is an item of the service list of the LC 116/2003, which tells the ISSQN due.
+type serviceListItem struct {
+	code        string
+	description string
+}
+
+// serviceList lists the services generated for the service items.
+var serviceList = []serviceListItem{
+	{"01.05", "Licenciamento de programa de computador"},
+	{"01.07", "Suporte tecnico em informatica"},
+	{"07.10", "Limpeza e conservacao de imoveis"},
+	{"14.01", "Manutencao e conserto de maquinas e equipamentos"},
+	{"14.02", "Assistencia tecnica"},
+	{"14.06", "Instalacao e montagem de equipamentos"},
+	{"17.01", "Assessoria e consultoria"},
+	{"17.02", "Servicos de apoio administrativo"},
+}
+
+// issRates are the municipal ISSQN rates, from the 2% floor to the 5% ceiling, in hundredths of percent.
+var issRates = []int{200, 300, 350, 500}
+
+// checkServiceItems fails when the item numbers cannot be services in a document of the TemplateType.
+// Service items have no ICMS, so they cannot have an ICMS situation other than ICMSRandom.
+func checkServiceItems(tt TemplateType, numbers []int, icms []ICMSSituation) error {
+	if len(numbers) == 0 {
+		return nil
+	}
+	if tt != NFe && tt != CFe {
+		return fmt.Errorf("service items are not supported for %v documents", tt)
+	}
+	limit := 990
+	if tt == CFe {
+		limit = 500
+	}
+	for _, number := range numbers {
+		if number < 1 || number > limit {
+			return fmt.Errorf("invalid service item number: %d (the items are numbered from 1 to %d)", number, limit)
+		}
+		if number <= len(icms) && icms[number-1] != ICMSRandom {
+			return fmt.Errorf("item %d is a service and cannot have the ICMS situation %v", number, icms[number-1])
+		}
+	}
+	return nil
+}
+
+// serviceItem reports whether the item number is marked as a service.
+func serviceItem(numbers []int, number int) bool {
+	for _, n := range numbers {
+		if n == number {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceItemCount returns the number of items needed for the service item numbers.
+func serviceItemCount(numbers []int) int {
+	count := 0
+	for _, number := range numbers {
+		count = max(count, number)
+	}
+	return count
+}
+
+// calculateISSQN makes the item a service rendered in the emitter municipality, with the
+// ISSQN at the municipal rate over its value and the CFOP of services taxed by the ISSQN.
+func (i *mockItem) calculateISSQN(doc *mockDocument) {
+	service := serviceList[gofakeit.Number(0, len(serviceList)-1)]
+	i.service = &service
+	i.CFOP = cfopPrefix(doc.tpNF, doc.idDest) + "933" // prestação de serviço tributado pelo ISSQN
+	i.cMunFG = doc.emit.city.code
+	i.pISS = issRates[gofakeit.Number(0, len(issRates)-1)]
+	i.vISSQN = i.net().applyRate(i.pISS)
+}
+
+// serviceProductValue returns the value of a product placeholder of a service item: the service
+// description, NCM 00 and no GTIN. ok is false for the other placeholders.
+func (i *mockItem) serviceProductValue(key string) (value string, ok bool) {
+	switch key {
+	case "xProd", "detProdXProd":
+		return i.service.description, true
+	case "NCM", "detProdNCM":
+		return "00", true
+	case "CEST", "detProdCEST":
+		return "", true
+	case "cEAN":
+		return "", true // optional in the CF-e
+	case "detProdCEAN":
+		return "SEM GTIN", true
+	case "uCom", "detProdUCom":
+		return "UN", true
+	}
+	return "", false
+}
+
+// issqnGroupXML renders the ISSQN group of the item, which is empty for goods. The CF-e group
+// informs the nature of the operation and the tax incentive instead of the ISSQN liability.
+func (i *mockItem) issqnGroupXML(tt TemplateType) string {
+	if i.service == nil {
+		return ""
+	}
+	elements := []xmlElement{
+		{"vBC", i.net().String()},
+		{"vAliq", formatRate(i.pISS, 2)},
+		{"vISSQN", i.vISSQN.String()},
+		{"cMunFG", i.cMunFG},
+		{"cListServ", i.service.code},
+	}
+	if tt == CFe {
+		elements = append([]xmlElement{{"vDeducISSQN", "0.00"}}, elements...)
+		elements = append(elements,
+			xmlElement{"cNatOp", "01"},    // 01 = tributação no município
+			xmlElement{"indIncFisc", "2"}, // 2 = não incentivo fiscal
+		)
+	} else {
+		elements = append(elements,
+			xmlElement{"indISS", "1"},       // 1 = exigível
+			xmlElement{"indIncentivo", "2"}, // 2 = não
+		)
+	}
+	return xmlGroup("ISSQN", elements)
+}
+
+// issqnTotXML renders the ISSQNtot group of the document, which is empty when it has no services.
+func (doc *mockDocument) issqnTotXML() string {
+	total := doc.total
+	if total.vServ == 0 {
+		return ""
+	}
+	if doc.templateType == CFe {
+		return xmlGroup("ISSQNtot", []xmlElement{
+			{"vBC", total.vServ.String()},
+			{"vISS", total.vISSQN.String()},
+			{"vPIS", total.vPISServ.String()},
+			{"vCOFINS", total.vCOFINSServ.String()},
+			{"vPISST", "0.00"},
+			{"vCOFINSST", "0.00"},
+		})
+	}
+	elements := []xmlElement{
+		{"vServ", total.vServ.String()},
+		{"vBC", total.vServ.String()},
+		{"vISS", total.vISSQN.String()},
+	}
+	if total.vPISServ > 0 {
+		elements = append(elements, xmlElement{"vPIS", total.vPISServ.String()})
+	}
+	if total.vCOFINSServ > 0 {
+		elements = append(elements, xmlElement{"vCOFINS", total.vCOFINSServ.String()})
+	}
+	elements = append(elements, xmlElement{"dCompet", doc.dhEmi.Format("2006-01-02")})
+	return xmlGroup("ISSQNtot", elements)
+}
@@ -0,0 +1,80 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithServiceItems_NFe(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		xmlBytes, err := NewNFeGenerator().Generate(WithServiceItems(2))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		document := string(xmlBytes)
+
+		if count := strings.Count(document, "<det nItem="); count != 2 {
+			t.Fatalf("Expected 2 items, got %d", count)
+		}
+		if strings.Count(document, "<ICMS>") != 1 || strings.Count(document, "<ISSQN>") != 1 {
+			t.Fatalf("Expected a product with ICMS and a service with ISSQN")
+		}
+		for _, element := range []string{"<ISSQNtot>", "<cListServ>", "933</CFOP>", "<NCM>00</NCM>"} {
+			if !strings.Contains(document, element) {
+				t.Errorf("Expected %s", element)
+			}
+		}
+		if errs := Validate(NFe, xmlBytes); len(errs) > 0 {
+			t.Fatalf("Expected no schema violations, got %v", errs)
+		}
+	}
+}
+
+func TestWithServiceItems_CFe(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		xmlBytes, err := NewCFeGenerator().Generate(WithServiceItems(1, 2))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		document := string(xmlBytes)
+
+		if strings.Contains(document, "<ICMS>") || strings.Count(document, "<ISSQN>") != 2 {
+			t.Fatalf("Expected two services with ISSQN")
+		}
+		for _, element := range []string{"<cNatOp>01</cNatOp>", "<indIncFisc>2</indIncFisc>", "<ISSQNtot>"} {
+			if !strings.Contains(document, element) {
+				t.Errorf("Expected %s", element)
+			}
+		}
+		if errs := Validate(CFe, xmlBytes); len(errs) > 0 {
+			t.Fatalf("Expected no schema violations, got %v", errs)
+		}
+	}
+}
+
+func TestWithServiceItems_Totals(t *testing.T) {
+	doc, _ := newMockDocument(NFe, &generationConfig{services: []int{2}})
+	goods, service := doc.items[0], doc.items[1]
+
+	if doc.total.vProd != goods.vProd || doc.total.vServ != service.vProd {
+		t.Errorf("Expected the goods in vProd and the service in vServ, got %v and %v", doc.total.vProd, doc.total.vServ)
+	}
+	if doc.total.vPIS != goods.vPIS || doc.total.vPISServ != service.vPIS {
+		t.Errorf("Expected the PIS of the service apart from the goods")
+	}
+	if want := goods.net() + goods.vIPI + goods.vICMSST + goods.vFCPST - goods.vICMSDeson + service.vProd; doc.vNF != want {
+		t.Errorf("Expected vNF %v, got %v", want, doc.vNF)
+	}
+}
+
+func TestWithServiceItems_Errors(t *testing.T) {
+	if _, err := NewNFCeGenerator().Generate(WithServiceItems(1)); err == nil {
+		t.Errorf("Expected an error for an NFCe document")
+	}
+	if _, err := NewNFeGenerator().Generate(WithICMS(CST00), WithServiceItems(1)); err == nil {
+		t.Errorf("Expected an error for a service with an ICMS situation")
+	}
+	if _, err := NewNFeGenerator().Generate(WithServiceItems(0)); err == nil {
+		t.Errorf("Expected an error for an invalid item number")
+	}
+}
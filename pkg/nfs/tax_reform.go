@@ -0,0 +1,272 @@
+package nfs
+
+import (
+	"fmt"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// TaxReformLayout is a version of the consumption tax reform (Reforma Tributária do Consumo, RTC)
+// layout, which adds the IBS, CBS and Imposto Seletivo groups to the NF-e and NFC-e (NT 2025.002).
+type TaxReformLayout int
+
+const (
+	// RTC2026 is the 2026 test year: CBS at 0.9% and state IBS at 0.1%, informed alongside the
+	// legacy taxes.
+	RTC2026 TaxReformLayout = iota + 1
+	// RTC2027 is 2027, when the CBS replaces PIS/COFINS at its full rate and the IBS is still
+	// tested at 0.05% for the state and 0.05% for the municipality.
+	RTC2027
+)
+
+// taxReformRates holds the IBS and CBS rates of each TaxReformLayout, in hundredths of percent.
+var taxReformRates = map[TaxReformLayout]struct{ pIBSUF, pIBSMun, pCBS int }{
+	RTC2026: {pIBSUF: 10, pIBSMun: 0, pCBS: 90},
+	RTC2027: {pIBSUF: 5, pIBSMun: 5, pCBS: 880},
+}
+
+// String returns the name of the TaxReformLayout.
+func (l TaxReformLayout) String() string {
+	switch l {
+	case RTC2026:
+		return "RTC2026"
+	case RTC2027:
+		return "RTC2027"
+	}
+	return "Unknown"
+}
+
+// ParseTaxReformLayout converts a string (e.g. "RTC2026") to a TaxReformLayout.
+func ParseTaxReformLayout(s string) (TaxReformLayout, error) {
+	for layout := range taxReformRates {
+		if layout.String() == s {
+			return layout, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid TaxReformLayout: %s", s)
+}
+
+// checkTaxReform fails when the layout is unknown or cannot be issued in a document of the TemplateType.
+func checkTaxReform(tt TemplateType, layout TaxReformLayout) error {
+	if layout == 0 {
+		return nil
+	}
+	if tt == CFe {
+		return fmt.Errorf("tax reform groups are not supported for CFe documents")
+	}
+	if _, ok := taxReformRates[layout]; !ok {
+		return fmt.Errorf("unknown tax reform layout: %d", layout)
+	}
+	return nil
+}
+
+// ibsCBSClass is a tax situation of the IBS and CBS: the CST, its classification (cClassTrib)
+// and whether it reduces the rates (pRedAliq), defers the tax (pDif) or has no tax at all.
+type ibsCBSClass struct {
+	CST        string
+	cClassTrib string
+	pRedAliq   int
+	pDif       int
+	untaxed    bool
+}
+
+// ibsCBSClasses are the situations generated for the items, the first being the most common.
+var ibsCBSClasses = []ibsCBSClass{
+	{CST: "000", cClassTrib: "000001"},                 // tributação integral
+	{CST: "200", cClassTrib: "200034", pRedAliq: 6000}, // alíquota reduzida em 60%
+	{CST: "410", cClassTrib: "410004", untaxed: true},  // imunidade e não incidência
+	{CST: "510", cClassTrib: "510001", pDif: 10000},    // diferimento
+}
+
+// isRates are the Imposto Seletivo rates of the goods harmful to health or the environment.
+var isRates = []int{250, 1000, 2000, 2500}
+
+// reformTaxes holds the IBS, CBS and Imposto Seletivo of an item, or their totals.
+type reformTaxes struct {
+	CST        string
+	cClassTrib string
+	untaxed    bool
+	vBC        cents
+	pIBSUF     int
+	pIBSMun    int
+	pCBS       int
+	pRedAliq   int
+	pDif       int
+	vIBSUF     cents
+	vIBSMun    cents
+	vCBS       cents
+	vDifIBSUF  cents
+	vDifIBSMun cents
+	vDifCBS    cents
+
+	// Imposto Seletivo
+	pIS   int
+	vBCIS cents
+	vIS   cents
+}
+
+// vIBS returns the state and municipal IBS.
+func (r reformTaxes) vIBS() cents {
+	return r.vIBSUF + r.vIBSMun
+}
+
+// calculateTaxReform sets the IBS, CBS and, for some goods, the Imposto Seletivo of the item, over
+// its value after the discount. Deferred taxes are informed in gDif and are not due.
+func (i *mockItem) calculateTaxReform(layout TaxReformLayout) {
+	rates := taxReformRates[layout]
+	class := ibsCBSClasses[0]
+	if gofakeit.Number(1, 3) == 1 {
+		class = ibsCBSClasses[gofakeit.Number(1, len(ibsCBSClasses)-1)]
+	}
+	reform := &reformTaxes{CST: class.CST, cClassTrib: class.cClassTrib, untaxed: class.untaxed}
+	if !class.untaxed {
+		reform.vBC = i.net()
+		reform.pIBSUF, reform.pIBSMun, reform.pCBS = rates.pIBSUF, rates.pIBSMun, rates.pCBS
+		reform.pRedAliq, reform.pDif = class.pRedAliq, class.pDif
+		reform.vIBSUF, reform.vDifIBSUF = reform.tax(reform.pIBSUF)
+		reform.vIBSMun, reform.vDifIBSMun = reform.tax(reform.pIBSMun)
+		reform.vCBS, reform.vDifCBS = reform.tax(reform.pCBS)
+	}
+	if gofakeit.Number(1, 5) == 1 {
+		reform.pIS = isRates[gofakeit.Number(0, len(isRates)-1)]
+		reform.vBCIS = i.net()
+		reform.vIS = reform.vBCIS.applyRate(reform.pIS)
+	}
+	i.reform = reform
+}
+
+// effectiveRate returns the rate after the reduction, in ten-thousandths of percent.
+func (r reformTaxes) effectiveRate(rate int) int {
+	return rate * (10000 - r.pRedAliq) / 100
+}
+
+// tax returns the tax due at the rate, after the reduction, and the deferred part.
+func (r reformTaxes) tax(rate int) (due cents, deferred cents) {
+	value := (r.vBC*cents(r.effectiveRate(rate)) + 500000) / 1000000
+	deferred = value.applyRate(r.pDif)
+	return value - deferred, deferred
+}
+
+// add adds the values of the item taxes to the totals.
+func (r *reformTaxes) add(item reformTaxes) {
+	r.vBC += item.vBC
+	r.vIBSUF += item.vIBSUF
+	r.vIBSMun += item.vIBSMun
+	r.vCBS += item.vCBS
+	r.vDifIBSUF += item.vDifIBSUF
+	r.vDifIBSMun += item.vDifIBSMun
+	r.vDifCBS += item.vDifCBS
+	if item.pIS > 0 {
+		r.pIS = item.pIS // the totals carry the Imposto Seletivo
+	}
+	r.vBCIS += item.vBCIS
+	r.vIS += item.vIS
+}
+
+// isGroupXML renders the Imposto Seletivo group of the item, which is empty when the goods are not subject to it.
+func (i *mockItem) isGroupXML() string {
+	if i.reform == nil || i.reform.pIS == 0 {
+		return ""
+	}
+	x := &xmlBuilder{}
+	x.open("IS")
+	x.element("CSTIS", "000")
+	x.element("cClassTribIS", "000001")
+	x.element("vBCIS", i.reform.vBCIS.String())
+	x.element("pIS", formatRate(i.reform.pIS, 4))
+	x.element("vIS", i.reform.vIS.String())
+	x.close("IS")
+	return x.String()
+}
+
+// ibsCBSGroupXML renders the IBSCBS group of the item. Untaxed situations carry the CST and
+// its classification only.
+func (i *mockItem) ibsCBSGroupXML() string {
+	r := i.reform
+	if r == nil {
+		return ""
+	}
+	x := &xmlBuilder{}
+	x.open("IBSCBS")
+	x.element("CST", r.CST)
+	x.element("cClassTrib", r.cClassTrib)
+	if !r.untaxed {
+		x.open("gIBSCBS")
+		x.element("vBC", r.vBC.String())
+		r.rateGroupXML(x, "gIBSUF", "pIBSUF", "vIBSUF", r.pIBSUF, r.vIBSUF, r.vDifIBSUF)
+		r.rateGroupXML(x, "gIBSMun", "pIBSMun", "vIBSMun", r.pIBSMun, r.vIBSMun, r.vDifIBSMun)
+		x.element("vIBS", r.vIBS().String())
+		r.rateGroupXML(x, "gCBS", "pCBS", "vCBS", r.pCBS, r.vCBS, r.vDifCBS)
+		x.close("gIBSCBS")
+	}
+	x.close("IBSCBS")
+	return x.String()
+}
+
+// rateGroupXML renders the group of one of the taxes: the rate, the deferral, the reduction and the value.
+func (r reformTaxes) rateGroupXML(x *xmlBuilder, group, rateName, valueName string, rate int, value, deferred cents) {
+	x.open(group)
+	x.element(rateName, formatRate(rate, 4))
+	if r.pDif > 0 {
+		x.open("gDif")
+		x.element("pDif", formatRate(r.pDif, 4))
+		x.element("vDif", deferred.String())
+		x.close("gDif")
+	}
+	if r.pRedAliq > 0 {
+		effective := r.effectiveRate(rate)
+		x.open("gRed")
+		x.element("pRedAliq", formatRate(r.pRedAliq, 4))
+		x.element("pAliqEfet", fmt.Sprintf("%d.%04d", effective/10000, effective%10000))
+		x.close("gRed")
+	}
+	x.element(valueName, value.String())
+	x.close(group)
+}
+
+// isTotXML renders the Imposto Seletivo totals, which are empty when no item is subject to it.
+func (i *mockItem) isTotXML() string {
+	if i.reform == nil || i.reform.pIS == 0 {
+		return ""
+	}
+	x := &xmlBuilder{}
+	x.open("ISTot")
+	x.element("vIS", i.reform.vIS.String())
+	x.close("ISTot")
+	return x.String()
+}
+
+// ibsCBSTotXML renders the IBS and CBS totals of the document.
+func (i *mockItem) ibsCBSTotXML() string {
+	r := i.reform
+	if r == nil {
+		return ""
+	}
+	x := &xmlBuilder{}
+	x.open("IBSCBSTot")
+	x.element("vBCIBSCBS", r.vBC.String())
+	x.open("gIBS")
+	x.open("gIBSUF")
+	x.element("vDif", r.vDifIBSUF.String())
+	x.element("vDevTrib", "0.00")
+	x.element("vIBSUF", r.vIBSUF.String())
+	x.close("gIBSUF")
+	x.open("gIBSMun")
+	x.element("vDif", r.vDifIBSMun.String())
+	x.element("vDevTrib", "0.00")
+	x.element("vIBSMun", r.vIBSMun.String())
+	x.close("gIBSMun")
+	x.element("vIBS", r.vIBS().String())
+	x.element("vCredPres", "0.00")
+	x.element("vCredPresCondSus", "0.00")
+	x.close("gIBS")
+	x.open("gCBS")
+	x.element("vDif", r.vDifCBS.String())
+	x.element("vDevTrib", "0.00")
+	x.element("vCBS", r.vCBS.String())
+	x.element("vCredPres", "0.00")
+	x.element("vCredPresCondSus", "0.00")
+	x.close("gCBS")
+	x.close("IBSCBSTot")
+	return x.String()
+}
@@ -0,0 +1,101 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithTaxReform_Groups(t *testing.T) {
+	for _, tt := range []TemplateType{NFe, NFCe, NFeDevolucao} {
+		t.Run(tt.String(), func(t *testing.T) {
+			generator, err := NewTemplateGenerator(tt)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for _, layout := range []TaxReformLayout{RTC2026, RTC2027} {
+				for i := 0; i < 20; i++ {
+					xmlBytes, err := generator.Generate(WithTaxReform(layout), WithICMS(ICMSRandom, ICMSRandom, ICMSRandom))
+					if err != nil {
+						t.Fatalf("Expected no error, got %v", err)
+					}
+					document := string(xmlBytes)
+					if count := strings.Count(document, "<IBSCBS>"); count != 3 {
+						t.Fatalf("Expected an IBSCBS group per item, got %d", count)
+					}
+					if !strings.Contains(document, "<IBSCBSTot>") {
+						t.Fatalf("Expected the IBSCBSTot group")
+					}
+					if strings.Contains(document, "<IS>") != strings.Contains(document, "<ISTot>") {
+						t.Fatalf("Expected ISTot only when an item has the IS group")
+					}
+					if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+						t.Fatalf("Expected no schema violations, got %v", errs)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWithTaxReform_Off(t *testing.T) {
+	xmlBytes, err := NewNFeGenerator().Generate()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, group := range []string{"<IBSCBS>", "<IS>", "<IBSCBSTot>", "<ISTot>", "{%"} {
+		if strings.Contains(string(xmlBytes), group) {
+			t.Errorf("Expected no %s without the tax reform layout", group)
+		}
+	}
+}
+
+func TestWithTaxReform_Errors(t *testing.T) {
+	if _, err := NewCFeGenerator().Generate(WithTaxReform(RTC2026)); err == nil {
+		t.Errorf("Expected an error for a CFe document")
+	}
+	if _, err := NewNFeGenerator().Generate(WithTaxReform(TaxReformLayout(9))); err == nil {
+		t.Errorf("Expected an error for an unknown layout")
+	}
+}
+
+func TestCalculateTaxReform(t *testing.T) {
+	tests := []struct {
+		name                  string
+		class                 ibsCBSClass
+		vIBSUF, vCBS, vDifCBS cents
+		pAliqEfet             string
+	}{
+		{"integral", ibsCBSClasses[0], 100, 900, 0, ""},
+		{"reduced", ibsCBSClasses[1], 40, 360, 0, "0.3600"},
+		{"deferred", ibsCBSClasses[3], 0, 0, 900, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := reformTaxes{vBC: 100000, pRedAliq: tc.class.pRedAliq, pDif: tc.class.pDif}
+			vIBSUF, _ := r.tax(10)
+			vCBS, vDifCBS := r.tax(90)
+			if vIBSUF != tc.vIBSUF || vCBS != tc.vCBS || vDifCBS != tc.vDifCBS {
+				t.Errorf("Expected IBS UF %v, CBS %v and deferred CBS %v, got %v, %v and %v",
+					tc.vIBSUF, tc.vCBS, tc.vDifCBS, vIBSUF, vCBS, vDifCBS)
+			}
+			x := &xmlBuilder{}
+			r.rateGroupXML(x, "gCBS", "pCBS", "vCBS", 90, vCBS, vDifCBS)
+			if tc.pAliqEfet != "" && !strings.Contains(x.String(), "<pAliqEfet>"+tc.pAliqEfet+"</pAliqEfet>") {
+				t.Errorf("Expected pAliqEfet %s, got %s", tc.pAliqEfet, x.String())
+			}
+		})
+	}
+}
+
+func TestParseTaxReformLayout(t *testing.T) {
+	for _, name := range []string{"RTC2026", "RTC2027"} {
+		layout, err := ParseTaxReformLayout(name)
+		if err != nil || layout.String() != name {
+			t.Errorf("Expected %s, got %v (%v)", name, layout, err)
+		}
+	}
+	if _, err := ParseTaxReformLayout("RTC2030"); err == nil {
+		t.Errorf("Expected an error for an unknown layout")
+	}
+}
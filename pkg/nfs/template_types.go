@@ -11,6 +11,8 @@ const (
 	NFe
 	NFCe
 	NFeDevolucao
+	NFeComplementar
+	NFeAjuste
 )
 
 // String returns the string representation of the TemplateType.
@@ -24,6 +26,10 @@ func (tt TemplateType) String() string {
 		return "NFCe"
 	case NFeDevolucao:
 		return "NFeDevolucao"
+	case NFeComplementar:
+		return "NFeComplementar"
+	case NFeAjuste:
+		return "NFeAjuste"
 	default:
 		return "Unknown"
 	}
@@ -41,6 +47,10 @@ func ParseTemplateType(s string) (TemplateType, error) {
 		return NFCe, nil
 	case "NFeDevolucao":
 		return NFeDevolucao, nil
+	case "NFeComplementar":
+		return NFeComplementar, nil
+	case "NFeAjuste":
+		return NFeAjuste, nil
 	default:
 		return -1, fmt.Errorf("invalid TemplateType: %s", s)
 	}
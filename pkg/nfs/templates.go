@@ -1,11 +1,13 @@
 package nfs
 
+import "strings"
+
 const CFeXMLMock = `<?xml version="1.0" encoding="utf-8"?>
 <CFe>
 <infCFe Id="CFe{%accessKey%}" versao="0.08" versaoDadosEnt="0.08" versaoSB="030000">
 <ide>
 <cUF>{%cUF%}</cUF>
-<cNF>{%cNF%}</cNF>
+<cNF>{%cNFCFe%}</cNF>
 <mod>59</mod>
 <nserieSAT>{%nserieSAT%}</nserieSAT>
 <nCFe>{%nCFe%}</nCFe>
@@ -13,15 +15,15 @@ const CFeXMLMock = `<?xml version="1.0" encoding="utf-8"?>
 <hEmi>{%hEmi%}</hEmi>
 <cDV>{%cDV%}</cDV>
 <tpAmb>{%tpAmb%}</tpAmb>
-<CNPJ>{%emitCNPJ%}</CNPJ>
+<CNPJ>{%softwareHouseCNPJ%}</CNPJ>
 <signAC>{%signAC%}</signAC>
 <assinaturaQRCODE>{%assinaturaQRCODE%}</assinaturaQRCODE>
 <numeroCaixa>{%numeroCaixa%}</numeroCaixa>
 </ide>
 <emit>
-<CNPJ>{%CNPJ%}</CNPJ>
-<xNome>{%xNome%}</xNome>
-<xFant>{%xFant%}</xFant>
+<CNPJ>{%emitCNPJ%}</CNPJ>
+<xNome>{%emitXNome%}</xNome>
+<xFant>{%emitXFant%}</xFant>
 <enderEmit>
 <xLgr>{%xLgr%}</xLgr>
 <nro>{%nro%}</nro>
@@ -35,8 +37,9 @@ const CFeXMLMock = `<?xml version="1.0" encoding="utf-8"?>
 <indRatISSQN>{%indRatISSQN%}</indRatISSQN>
 </emit>
 <dest>
+<CNPJ>{%CNPJDest%}</CNPJ>
 <CPF>{%CPF%}</CPF>
-<xNome>{%xNome%}</xNome>
+<xNome>{%destXNome%}</xNome>
 </dest>
 <det nItem="{%nItem%}">
 <prod>
@@ -44,37 +47,33 @@ const CFeXMLMock = `<?xml version="1.0" encoding="utf-8"?>
 <cEAN>{%cEAN%}</cEAN>
 <xProd>{%xProd%}</xProd>
 <NCM>{%NCM%}</NCM>
+<CEST>{%CEST%}</CEST>
 <CFOP>{%CFOP%}</CFOP>
 <uCom>{%uCom%}</uCom>
 <qCom>{%qCom%}</qCom>
 <vUnCom>{%vUnCom%}</vUnCom>
 <vProd>{%vProd%}</vProd>
 <indRegra>{%indRegra%}</indRegra>
+<vDesc>{%vDescItem%}</vDesc>
 <vItem>{%vItem%}</vItem>
 </prod>
 <imposto>
 <vItem12741>{%vItem12741%}</vItem12741>
-<ICMS>
-<ICMS00>
-<Orig>{%Orig%}</Orig>
-<CST>{%CST%}</CST>
-<pICMS>{%pICMS%}</pICMS>
-<vICMS>{%vICMS%}</vICMS>
-</ICMS00>
-</ICMS>
+{%ICMSGroup%}
+{%ISSQNGroup%}
 <PIS>
 <PISAliq>
-<CST>{%CST%}</CST>
+<CST>{%CST_PISAliq%}</CST>
 <vBC>{%vBC%}</vBC>
-<pPIS>{%pPIS%}</pPIS>
+<pPIS>{%pPISSAT%}</pPIS>
 <vPIS>{%vPIS%}</vPIS>
 </PISAliq>
 </PIS>
 <COFINS>
 <COFINSAliq>
-<CST>{%CST%}</CST>
+<CST>{%CST_COFINSAliq%}</CST>
 <vBC>{%vBC%}</vBC>
-<pCOFINS>{%pCOFINS%}</pCOFINS>
+<pCOFINS>{%pCOFINSSAT%}</pCOFINS>
 <vCOFINS>{%vCOFINS%}</vCOFINS>
 </COFINSAliq>
 </COFINS>
@@ -91,6 +90,7 @@ const CFeXMLMock = `<?xml version="1.0" encoding="utf-8"?>
 <vCOFINSST>{%vCOFINSST%}</vCOFINSST>
 <vOutro>{%vOutro%}</vOutro>
 </ICMSTot>
+{%ISSQNTotGroup%}
 <vCFe>{%vCFe%}</vCFe>
 <vCFeLei12741>{%vCFeLei12741%}</vCFeLei12741>
 </total>
@@ -104,16 +104,16 @@ const CFeXMLMock = `<?xml version="1.0" encoding="utf-8"?>
 </pgto>
 <infAdic>
 <infCpl>{%infCpl%}</infCpl>
-</infAdic>
-<obsFisco xCampo="{%xCampo%}" xTexto="{%xTexto%}">
-<xTexto>Comete crime quem sonega</xTexto>
+<obsFisco xCampo="{%obsFiscoXCampo%}">
+<xTexto>{%obsFiscoXTexto%}</xTexto>
 </obsFisco>
+</infAdic>
 </infCFe>
 <Signature xmlns="http://www.w3.org/2000/09/xmldsig#">
 <SignedInfo>
 <CanonicalizationMethod Algorithm="http://www.w3.org/TR/2001/REC-xml-c14n-20010315"></CanonicalizationMethod>
 <SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"></SignatureMethod>
-<Reference URI="#CFe35241006346545001536590011973410076397299542">
+<Reference URI="#CFe{%accessKey%}">
 <Transforms>
 <Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"></Transform>
 <Transform Algorithm="http://www.w3.org/TR/2001/REC-xml-c14n-20010315"></Transform>
@@ -156,6 +156,8 @@ const NFCeXMLMock = `<?xml version="1.0" encoding="utf-8"?>
         <indIntermed>{%indIntermed%}</indIntermed>
         <procEmi>{%procEmi%}</procEmi>
         <verProc>{%verProc%}</verProc>
+        <dhCont>{%dhCont%}</dhCont>
+        <xJust>{%xJust%}</xJust>
       </ide>
       <emit>
         <CNPJ>{%emitCNPJ%}</CNPJ>
@@ -177,7 +179,9 @@ const NFCeXMLMock = `<?xml version="1.0" encoding="utf-8"?>
         <CRT>{%CRT%}</CRT>
       </emit>
       <dest>
+        <CNPJ>{%CNPJDest%}</CNPJ>
         <CPF>{%CPF%}</CPF>
+        <idEstrangeiro>{%destIdEstrangeiro%}</idEstrangeiro>
         <xNome>{%destXNome%}</xNome>
         <enderDest>
           <xLgr>{%xLgrDest%}</xLgr>
@@ -201,6 +205,7 @@ const NFCeXMLMock = `<?xml version="1.0" encoding="utf-8"?>
           <cEAN>{%cEAN%}</cEAN>
           <xProd>{%xProd%}</xProd>
           <NCM>{%NCM%}</NCM>
+          <CEST>{%CEST%}</CEST>
           <CFOP>{%CFOP%}</CFOP>
           <uCom>{%uCom%}</uCom>
           <qCom>{%qCom%}</qCom>
@@ -210,33 +215,24 @@ const NFCeXMLMock = `<?xml version="1.0" encoding="utf-8"?>
           <uTrib>{%uTrib%}</uTrib>
           <qTrib>{%qTrib%}</qTrib>
           <vUnTrib>{%vUnTrib%}</vUnTrib>
-          <vDesc>{%vDesc%}</vDesc>
+          <vDesc>{%vDescItem%}</vDesc>
           <indTot>{%indTot%}</indTot>
+          {%rastroGroup%}
+          {%sectorGroup%}
         </prod>
         <imposto>
           <vTotTrib>{%vTotTrib%}</vTotTrib>
-          <ICMS>
-            <ICMSSN102>
-              <orig>{%orig%}</orig>
-              <CSOSN>{%CSOSN%}</CSOSN>
-            </ICMSSN102>
-          </ICMS>
+          {%ICMSGroup%}
           <PIS>
-            <PISOutr>
-              <CST>{%CST_PIS%}</CST>
-              <vBC>{%vBC_PIS%}</vBC>
-              <pPIS>{%pPIS%}</pPIS>
-              <vPIS>{%vPIS%}</vPIS>
-            </PISOutr>
+            {%PISGroup%}
           </PIS>
+          {%PISSTGroup%}
           <COFINS>
-            <COFINSOutr>
-              <CST>{%CST_COFINS%}</CST>
-              <vBC>{%vBC_COFINS%}</vBC>
-              <pCOFINS>{%pCOFINS%}</pCOFINS>
-              <vCOFINS>{%vCOFINS%}</vCOFINS>
-            </COFINSOutr>
+            {%COFINSGroup%}
           </COFINS>
+          {%COFINSSTGroup%}
+          {%ISGroup%}
+          {%IBSCBSGroup%}
         </imposto>
         <infAdProd>{%infAdProd%}</infAdProd>
       </det>
@@ -263,22 +259,12 @@ const NFCeXMLMock = `<?xml version="1.0" encoding="utf-8"?>
           <vNF>{%vNF%}</vNF>
           <vTotTrib>{%vTotTrib_total%}</vTotTrib>
         </ICMSTot>
+        {%ISTotGroup%}
+        {%IBSCBSTotGroup%}
       </total>
-      <transp>
-        <modFrete>{%modFrete%}</modFrete>
-      </transp>
-      <pag>
-        <detPag>
-          <tPag>{%tPag%}</tPag>
-          <vPag>{%vPag%}</vPag>
-          <card>
-            <tpIntegra>{%tpIntegra%}</tpIntegra>
-            <CNPJ>{%cardCNPJ%}</CNPJ>
-            <tBand>{%tBand%}</tBand>
-            <cAut>{%cAut%}</cAut>
-          </card>
-        </detPag>
-      </pag>
+      {%transpGroup%}
+      {%pagGroup%}
+      {%infIntermedGroup%}
       <infAdic>
         <infCpl>{%infCpl%}</infCpl>
       </infAdic>
@@ -337,7 +323,7 @@ const NFeDevolucaoXMLMock = `<?xml version="1.0" encoding="UTF-8"?>
         <cNF>{%cNF%}</cNF>
         <natOp>{%natOp%}</natOp>
         <mod>55</mod>
-        <serie>55</serie>
+        <serie>{%serie%}</serie>
         <nNF>{%nNF%}</nNF>
         <dhEmi>{%dhEmi%}</dhEmi>
         <dhSaiEnt>{%dhSaiEnt%}</dhSaiEnt>
@@ -348,15 +334,21 @@ const NFeDevolucaoXMLMock = `<?xml version="1.0" encoding="UTF-8"?>
         <tpEmis>{%tpEmis%}</tpEmis>
         <cDV>{%cDV%}</cDV>
         <tpAmb>{%tpAmb%}</tpAmb>
-        <finNFe>{%finNFe%}</finNFe>
+        <finNFe>4</finNFe>
         <indFinal>{%indFinal%}</indFinal>
         <indPres>{%indPres%}</indPres>
         <indIntermed>{%indIntermed%}</indIntermed>
         <procEmi>{%procEmi%}</procEmi>
         <verProc>{%verProc%}</verProc>
+        <dhCont>{%dhCont%}</dhCont>
+        <xJust>{%xJust%}</xJust>
+        <NFref>
+          <refNFe>{%refNFe%}</refNFe>
+        </NFref>
       </ide>
       <emit>
         <CNPJ>{%emitCNPJ%}</CNPJ>
+        <CPF>{%emitCPF%}</CPF>
         <xNome>{%emitXNome%}</xNome>
         <xFant>{%emitXFant%}</xFant>
         <enderEmit>
@@ -376,6 +368,7 @@ const NFeDevolucaoXMLMock = `<?xml version="1.0" encoding="UTF-8"?>
         <CRT>{%CRT%}</CRT>
       </emit>
       <dest>
+        <CNPJ>{%CNPJDest%}</CNPJ>
         <CPF>{%CPF%}</CPF>
         <xNome>{%destXNome%}</xNome>
         <enderDest>
@@ -392,6 +385,7 @@ const NFeDevolucaoXMLMock = `<?xml version="1.0" encoding="UTF-8"?>
           <fone>{%foneDest%}</fone>
         </enderDest>
         <indIEDest>{%indIEDest%}</indIEDest>
+        <IE>{%destIE%}</IE>
       </dest>
       <det nItem="{%nItem%}">
         <prod>
@@ -409,41 +403,23 @@ const NFeDevolucaoXMLMock = `<?xml version="1.0" encoding="UTF-8"?>
           <uTrib>{%uTrib%}</uTrib>
           <qTrib>{%qTrib%}</qTrib>
           <vUnTrib>{%vUnTrib%}</vUnTrib>
+          <vDesc>{%vDescItem%}</vDesc>
           <indTot>{%indTot%}</indTot>
         </prod>
         <imposto>
           <vTotTrib>{%vTotTrib%}</vTotTrib>
-          <ICMS>
-            <ICMSSN102>
-              <orig>{%orig%}</orig>
-              <CSOSN>{%CSOSN%}</CSOSN>
-            </ICMSSN102>
-          </ICMS>
-          <IPI>
-            <cEnq>{%cEnq%}</cEnq>
-            <IPITrib>
-              <CST>{%CST_IPI%}</CST>
-              <vBC>{%vBC_IPI%}</vBC>
-              <pIPI>{%pIPI%}</pIPI>
-              <vIPI>{%vIPI%}</vIPI>
-            </IPITrib>
-          </IPI>
+          {%ICMSGroup%}
+          {%IPIGroup%}
           <PIS>
-            <PISOutr>
-              <CST>{%CST_PIS%}</CST>
-              <vBC>{%vBC_PIS%}</vBC>
-              <pPIS>{%pPIS%}</pPIS>
-              <vPIS>{%vPIS%}</vPIS>
-            </PISOutr>
+            {%PISGroup%}
           </PIS>
+          {%PISSTGroup%}
           <COFINS>
-            <COFINSOutr>
-              <CST>{%CST_COFINS%}</CST>
-              <vBC>{%vBC_COFINS%}</vBC>
-              <pCOFINS>{%pCOFINS%}</pCOFINS>
-              <vCOFINS>{%vCOFINS%}</vCOFINS>
-            </COFINSOutr>
+            {%COFINSGroup%}
           </COFINS>
+          {%COFINSSTGroup%}
+          {%ISGroup%}
+          {%IBSCBSGroup%}
         </imposto>
         <impostoDevol>
           <pDevol>{%pDevol%}</pDevol>
@@ -479,25 +455,12 @@ const NFeDevolucaoXMLMock = `<?xml version="1.0" encoding="UTF-8"?>
           <vNF>{%vNF%}</vNF>
           <vTotTrib>{%vTotTrib_total%}</vTotTrib>
         </ICMSTot>
+        {%ISTotGroup%}
+        {%IBSCBSTotGroup%}
       </total>
-      <transp>
-        <modFrete>{%modFrete%}</modFrete>
-        <vol>
-          <qVol>{%qVol%}</qVol>
-        </vol>
-      </transp>
-      <pag>
-        <detPag>
-          <tPag>{%tPag%}</tPag>
-          <vPag>{%vPag%}</vPag>
-          <card>
-            <tpIntegra>{%tpIntegra%}</tpIntegra>
-            <CNPJ>{%cardCNPJ%}</CNPJ>
-            <tBand>{%tBand%}</tBand>
-            <cAut>{%cAut%}</cAut>
-          </card>
-        </detPag>
-      </pag>
+      {%transpGroup%}
+      {%pagGroup%}
+      {%infIntermedGroup%}
       <infAdic>
         <infAdFisco>{%infAdFisco%}</infAdFisco>
       </infAdic>
@@ -544,29 +507,36 @@ const NFeDevolucaoXMLMock = `<?xml version="1.0" encoding="UTF-8"?>
 </nfeProc>`
 
 const NFeXMLMock = `<NFe xmlns="http://www.portalfiscal.inf.br/nfe">
-<infNFe Id="NFe{%accessKey%}" versao="1.10">
+<infNFe Id="NFe{%accessKey%}" versao="4.00">
 <ide>
 <cUF>{%cUF%}</cUF>
 <cNF>{%cNF%}</cNF>
 <natOp>{%natOp%}</natOp>
-<indPag>{%indPag%}</indPag>
 <mod>55</mod>
 <serie>{%serie%}</serie>
 <nNF>{%nNF%}</nNF>
 <dhEmi>{%dhEmi%}</dhEmi>
-<dSaiEnt>{%dSaiEnt%}</dSaiEnt>
+<dhSaiEnt>{%dhSaiEnt%}</dhSaiEnt>
 <tpNF>{%tpNF%}</tpNF>
+<idDest>{%idDest%}</idDest>
 <cMunFG>{%cMunFG%}</cMunFG>
 <tpImp>{%tpImp%}</tpImp>
 <tpEmis>{%tpEmis%}</tpEmis>
 <cDV>{%cDV%}</cDV>
 <tpAmb>{%tpAmb%}</tpAmb>
 <finNFe>{%finNFe%}</finNFe>
+<indFinal>{%indFinal%}</indFinal>
+<indPres>{%indPres%}</indPres>
+<indIntermed>{%indIntermed%}</indIntermed>
 <procEmi>{%procEmi%}</procEmi>
 <verProc>{%verProc%}</verProc>
+<dhCont>{%dhCont%}</dhCont>
+<xJust>{%xJust%}</xJust>
+{%NFrefGroup%}
 </ide>
 <emit>
 <CNPJ>{%emitCNPJ%}</CNPJ>
+<CPF>{%emitCPF%}</CPF>
 <xNome>{%emitXNome%}</xNome>
 <xFant>{%emitXFant%}</xFant>
 <enderEmit>
@@ -583,9 +553,12 @@ const NFeXMLMock = `<NFe xmlns="http://www.portalfiscal.inf.br/nfe">
 <fone>{%enderEmitFone%}</fone>
 </enderEmit>
 <IE>{%emitIE%}</IE>
+<CRT>{%CRT%}</CRT>
 </emit>
 <dest>
 <CNPJ>{%destCNPJ%}</CNPJ>
+<CPF>{%destCPF%}</CPF>
+<idEstrangeiro>{%destIdEstrangeiro%}</idEstrangeiro>
 <xNome>{%destXNome%}</xNome>
 <enderDest>
 <xLgr>{%enderDestXLgr%}</xLgr>
@@ -600,6 +573,7 @@ const NFeXMLMock = `<NFe xmlns="http://www.portalfiscal.inf.br/nfe">
 <xPais>{%enderDestXPais%}</xPais>
 <fone>{%enderDestFone%}</fone>
 </enderDest>
+<indIEDest>{%indIEDest%}</indIEDest>
 <IE>{%destIE%}</IE>
 </dest>
 <retirada>
@@ -627,6 +601,8 @@ const NFeXMLMock = `<NFe xmlns="http://www.portalfiscal.inf.br/nfe">
 <cProd>{%detProdCProd%}</cProd>
 <cEAN>{%detProdCEAN%}</cEAN>
 <xProd>{%detProdXProd%}</xProd>
+<NCM>{%detProdNCM%}</NCM>
+<CEST>{%detProdCEST%}</CEST>
 <CFOP>{%detProdCFOP%}</CFOP>
 <uCom>{%detProdUCom%}</uCom>
 <qCom>{%detProdQCom%}</qCom>
@@ -636,89 +612,73 @@ const NFeXMLMock = `<NFe xmlns="http://www.portalfiscal.inf.br/nfe">
 <uTrib>{%detProdUTrib%}</uTrib>
 <qTrib>{%detProdQTrib%}</qTrib>
 <vUnTrib>{%detProdVUnTrib%}</vUnTrib>
+<vDesc>{%detProdVDesc%}</vDesc>
+<indTot>{%detProdIndTot%}</indTot>
+{%DIGroup%}
+{%detExportGroup%}
+{%rastroGroup%}
+{%sectorGroup%}
 </prod>
 <imposto>
-<ICMS>
-<ICMS00>
-<orig>{%impostoICMS00orig%}</orig>
-<CST>{%impostoICMS00CST%}</CST>
-<modBC>{%impostoICMS00modBC%}</modBC>
-<vBC>{%impostoICMS00vBC%}</vBC>
-<pICMS>{%impostoICMS00pICMS%}</pICMS>
-<vICMS>{%impostoICMS00vICMS%}</vICMS>
-</ICMS00>
-</ICMS>
+{%ICMSGroup%}
+{%IPIGroup%}
+{%IIGroup%}
+{%ISSQNGroup%}
 <PIS>
-<PISAliq>
-<CST>{%impostoPISAliqCST%}</CST>
-<vBC>{%impostoPISAliqvBC%}</vBC>
-<pPIS>{%impostoPISAliqpPIS%}</pPIS>
-<vPIS>{%impostoPISAliqvPIS%}</vPIS>
-</PISAliq>
+{%PISGroup%}
 </PIS>
+{%PISSTGroup%}
 <COFINS>
-<COFINSAliq>
-<CST>{%impostoCOFINSAliqCST%}</CST>
-<vBC>{%impostoCOFINSAliqvBC%}</vBC>
-<pCOFINS>{%impostoCOFINSAliqpCOFINS%}</pCOFINS>
-<vCOFINS>{%impostoCOFINSAliqvCOFINS%}</vCOFINS>
-</COFINSAliq>
+{%COFINSGroup%}
 </COFINS>
+{%COFINSSTGroup%}
+{%ICMSUFDestGroup%}
+{%ISGroup%}
+{%IBSCBSGroup%}
 </imposto>
 </det>
 <total>
 <ICMSTot>
 <vBC>{%totalICMSTotvBC%}</vBC>
 <vICMS>{%totalICMSTotvICMS%}</vICMS>
+<vICMSDeson>{%totalICMSTotvICMSDeson%}</vICMSDeson>
+<vFCPUFDest>{%totalICMSTotvFCPUFDest%}</vFCPUFDest>
+<vICMSUFDest>{%totalICMSTotvICMSUFDest%}</vICMSUFDest>
+<vICMSUFRemet>{%totalICMSTotvICMSUFRemet%}</vICMSUFRemet>
+<vFCP>{%totalICMSTotvFCP%}</vFCP>
 <vBCST>{%totalICMSTotvBCST%}</vBCST>
 <vST>{%totalICMSTotvST%}</vST>
+<vFCPST>{%totalICMSTotvFCPST%}</vFCPST>
+<vFCPSTRet>{%totalICMSTotvFCPSTRet%}</vFCPSTRet>
 <vProd>{%totalICMSTotvProd%}</vProd>
 <vFrete>{%totalICMSTotvFrete%}</vFrete>
 <vSeg>{%totalICMSTotvSeg%}</vSeg>
 <vDesc>{%totalICMSTotvDesc%}</vDesc>
 <vII>{%totalICMSTotvII%}</vII>
 <vIPI>{%totalICMSTotvIPI%}</vIPI>
+<vIPIDevol>{%totalICMSTotvIPIDevol%}</vIPIDevol>
 <vPIS>{%totalICMSTotvPIS%}</vPIS>
 <vCOFINS>{%totalICMSTotvCOFINS%}</vCOFINS>
 <vOutro>{%totalICMSTotvOutro%}</vOutro>
 <vNF>{%totalICMSTotvNF%}</vNF>
 </ICMSTot>
+{%ISSQNTotGroup%}
+{%ISTotGroup%}
+{%IBSCBSTotGroup%}
 </total>
-<transp>
-<modFrete>{%transpModFrete%}</modFrete>
-<transporta>
-<CNPJ>{%transpTransportaCNPJ%}</CNPJ>
-<xNome>{%transpTransportaXNome%}</xNome>
-<IE>{%transpTransportaIE%}</IE>
-<xEnder>{%transpTransportaXEnder%}</xEnder>
-<xMun>{%transpTransportaXMun%}</xMun>
-<UF>{%transpTransportaUF%}</UF>
-</transporta>
-<veicTransp>
-<placa>{%transpVeicTranspPlaca%}</placa>
-<UF>{%transpVeicTranspUF%}</UF>
-<RNTC>{%transpVeicTranspRNTC%}</RNTC>
-</veicTransp>
-<reboque>
-<placa>{%transpReboquePlaca%}</placa>
-<UF>{%transpReboqueUF%}</UF>
-<RNTC>{%transpReboqueRNTC%}</RNTC>
-</reboque>
-<vol>
-<qVol>{%transpVolQVol%}</qVol>
-<esp>{%transpVolEsp%}</esp>
-<marca>{%transpVolMarca%}</marca>
-<nVol>{%transpVolNVol%}</nVol>
-<pesoL>{%transpVolPesoL%}</pesoL>
-<pesoB>{%transpVolPesoB%}</pesoB>
-<lacres>
-<nLacre>{%transpVolLacresNLacre%}</nLacre>
-</lacres>
-</vol>
-</transp>
+{%transpGroup%}
+{%pagGroup%}
+{%infIntermedGroup%}
 <infAdic>
 <infAdFisco>{%infAdicInfAdFisco%}</infAdFisco>
 </infAdic>
+{%exportaGroup%}
+<infRespTec>
+<CNPJ>{%infRespTecCNPJ%}</CNPJ>
+<xContato>{%infRespTecXContato%}</xContato>
+<email>{%infRespTecEmail%}</email>
+<fone>{%infRespTecFone%}</fone>
+</infRespTec>
 </infNFe>
 <Signature xmlns="http://www.w3.org/2000/09/xmldsig#">
 <SignedInfo>
@@ -741,3 +701,9 @@ const NFeXMLMock = `<NFe xmlns="http://www.portalfiscal.inf.br/nfe">
 </KeyInfo>
 </Signature>
 </NFe>`
+
+// NFeComplementarXMLMock is the NFe template with the purpose of a complementary NF-e (finNFe 2).
+var NFeComplementarXMLMock = strings.Replace(NFeXMLMock, "<finNFe>{%finNFe%}</finNFe>", "<finNFe>2</finNFe>", 1)
+
+// NFeAjusteXMLMock is the NFe template with the purpose of an adjustment NF-e (finNFe 3).
+var NFeAjusteXMLMock = strings.Replace(NFeXMLMock, "<finNFe>{%finNFe%}</finNFe>", "<finNFe>3</finNFe>", 1)
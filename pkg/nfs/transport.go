@@ -0,0 +1,186 @@
+package nfs
+
+import (
+	"fmt"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+	"github.com/mayckol/brfiscalfaker/pkg/br_locale"
+)
+
+// volumeKinds are usual kinds of volumes (esp) with their tare, in hundredths of percent of the net weight.
+var volumeKinds = []struct {
+	name string
+	tare int
+}{
+	{"CAIXA", 800},
+	{"CAIXA DE PAPELAO", 600},
+	{"PALETE", 1500},
+	{"FARDO", 300},
+	{"SACO", 200},
+	{"TAMBOR", 1000},
+	{"VOLUME", 500},
+}
+
+// mockTransport holds the transport of the goods by the freight mode: the carrier, the vehicle with
+// its trailers and the volumes. Without freight (modFrete 9) there is nothing but the mode.
+type mockTransport struct {
+	modFrete string
+	carrier  *mockCarrier
+	vehicle  *mockVehicle
+	trailers []mockVehicle
+	volumes  []mockVolume
+}
+
+// mockCarrier is the carrier (transporta): a company or a self-employed driver with a CPF.
+type mockCarrier struct {
+	party   mockParty
+	name    string
+	address string
+}
+
+// mockVehicle is a road vehicle or trailer, with its plate, the UF of its registration and the RNTRC of the carrier.
+type mockVehicle struct {
+	plate string
+	uf    string
+	RNTC  string
+}
+
+// mockVolume is a set of volumes of the same kind, with its weights in grams and its seals.
+type mockVolume struct {
+	qVol   int
+	esp    string
+	marca  string
+	nVol   string
+	pesoL  int64
+	pesoB  int64
+	lacres []string
+}
+
+// applyTransport sets the transport of the goods. NFC-e documents are presential sales without
+// freight, as are complements and adjustments, which carry no goods. The own transport of the emitter
+// (3) or of the recipient (4) is made with their vehicles; the vehicle and trailers are informed in
+// operations within the state only.
+func (doc *mockDocument) applyTransport() {
+	t := &mockTransport{modFrete: "9"}
+	doc.transport = t
+	if doc.templateType == NFCe || doc.complementary() {
+		return
+	}
+	modes := []string{"0", "0", "1", "1", "2", "3", "9"}
+	if doc.indIEDest == "1" && doc.idDest != "3" {
+		modes = append(modes, "4")
+	}
+	t.modFrete = gofakeit.RandomString(modes)
+	if t.modFrete == "9" {
+		return
+	}
+
+	switch t.modFrete {
+	case "3":
+		t.carrier = &mockCarrier{party: doc.emit, name: doc.emitName}
+	case "4":
+		t.carrier = &mockCarrier{party: doc.dest, name: doc.destName}
+		if t.carrier.party.CNPJ != "" {
+			t.carrier.party.CPF = ""
+		}
+	default:
+		city := randomMunicipality("")
+		t.carrier = &mockCarrier{party: mockParty{city: city}}
+		if gofakeit.Number(1, 4) == 1 {
+			// A self-employed driver, who is not an ICMS contributor.
+			t.carrier.party.CPF = br_documents.CPF()
+			t.carrier.name = br_locale.PersonName()
+		} else {
+			t.carrier.party.CNPJ = br_documents.CNPJ()
+			t.carrier.party.IE = br_documents.IE(city.uf)
+			t.carrier.name = br_locale.LastName() + " Transportes LTDA"
+		}
+	}
+	t.carrier.address = fmt.Sprintf("%s, %s", xLgr(), nro())
+
+	if doc.idDest == "1" {
+		rntrc := gofakeit.Numerify("########")
+		t.vehicle = &mockVehicle{plate: br_documents.Plate(), uf: t.carrier.party.city.uf, RNTC: rntrc}
+		for range gofakeit.Number(0, 2) {
+			t.trailers = append(t.trailers, mockVehicle{plate: br_documents.Plate(), uf: t.carrier.party.city.uf, RNTC: rntrc})
+		}
+	}
+
+	first := 1
+	for range gofakeit.Number(1, 3) {
+		kind := volumeKinds[gofakeit.Number(0, len(volumeKinds)-1)]
+		v := mockVolume{
+			qVol:  gofakeit.Number(1, 50),
+			esp:   kind.name,
+			marca: doc.emitName,
+		}
+		v.nVol = fmt.Sprintf("%d-%d", first, first+v.qVol-1)
+		first += v.qVol
+		v.pesoL = int64(v.qVol) * int64(gofakeit.Number(500, 30000))
+		v.pesoB = v.pesoL + v.pesoL*int64(kind.tare)/10000
+		for range gofakeit.Number(0, 2) {
+			v.lacres = append(v.lacres, gofakeit.Numerify("LAC#########"))
+		}
+		t.volumes = append(t.volumes, v)
+	}
+}
+
+// formatWeight formats a weight given in grams as kilograms with three decimals.
+func formatWeight(grams int64) string {
+	return fmt.Sprintf("%d.%03d", grams/1000, grams%1000)
+}
+
+// transpGroupXML renders the transport group of the document.
+func (doc *mockDocument) transpGroupXML() string {
+	t := doc.transport
+	x := &xmlBuilder{}
+	x.open("transp")
+	x.element("modFrete", t.modFrete)
+	if c := t.carrier; c != nil {
+		x.open("transporta")
+		if c.party.CPF != "" {
+			x.element("CPF", c.party.CPF)
+		} else {
+			x.element("CNPJ", c.party.CNPJ)
+		}
+		x.element("xNome", c.name)
+		if c.party.IE != "" {
+			x.element("IE", c.party.IE)
+		}
+		x.element("xEnder", c.address)
+		x.element("xMun", c.party.city.name)
+		x.element("UF", c.party.city.uf)
+		x.close("transporta")
+	}
+	if t.vehicle != nil {
+		for i, vehicle := range append([]mockVehicle{*t.vehicle}, t.trailers...) {
+			name := "veicTransp"
+			if i > 0 {
+				name = "reboque"
+			}
+			x.open(name)
+			x.element("placa", vehicle.plate)
+			x.element("UF", vehicle.uf)
+			x.element("RNTC", vehicle.RNTC)
+			x.close(name)
+		}
+	}
+	for _, v := range t.volumes {
+		x.open("vol")
+		x.element("qVol", fmt.Sprint(v.qVol))
+		x.element("esp", v.esp)
+		x.element("marca", v.marca)
+		x.element("nVol", v.nVol)
+		x.element("pesoL", formatWeight(v.pesoL))
+		x.element("pesoB", formatWeight(v.pesoB))
+		for _, lacre := range v.lacres {
+			x.open("lacres")
+			x.element("nLacre", lacre)
+			x.close("lacres")
+		}
+		x.close("vol")
+	}
+	x.close("transp")
+	return x.String()
+}
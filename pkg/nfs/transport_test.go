@@ -0,0 +1,125 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+func TestApplyTransport(t *testing.T) {
+	modes := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		doc, _ := newMockDocument(NFe, &generationConfig{})
+		tr := doc.transport
+		modes[tr.modFrete] = true
+		if tr.modFrete == "9" {
+			if tr.carrier != nil || tr.vehicle != nil || len(tr.volumes) > 0 {
+				t.Fatalf("Expected no carrier, vehicle nor volumes without freight, got %+v", tr)
+			}
+			continue
+		}
+
+		c := tr.carrier
+		if c == nil || (c.party.CNPJ == "") == (c.party.CPF == "") {
+			t.Fatalf("Expected a carrier with a CNPJ or a CPF by mode %s, got %+v", tr.modFrete, c)
+		}
+		if c.party.CNPJ != "" && !br_documents.ValidateCNPJ(c.party.CNPJ) || c.party.CPF != "" && !br_documents.ValidateCPF(c.party.CPF) {
+			t.Errorf("Expected a valid carrier document, got %+v", c.party)
+		}
+		if c.party.IE != "" && !br_documents.ValidateIE(c.party.city.uf, c.party.IE) {
+			t.Errorf("Expected a valid IE of %s, got %s", c.party.city.uf, c.party.IE)
+		}
+		switch tr.modFrete {
+		case "3":
+			if c.party.CNPJ != doc.emit.CNPJ {
+				t.Errorf("Expected the emitter as the carrier of its own transport")
+			}
+		case "4":
+			if c.party.CNPJ != doc.dest.CNPJ || doc.indIEDest != "1" {
+				t.Errorf("Expected the contributor recipient as the carrier of its own transport")
+			}
+		}
+
+		if (tr.vehicle != nil) != (doc.idDest == "1") {
+			t.Errorf("Expected the vehicle in operations within the state only, got %+v by idDest %s", tr.vehicle, doc.idDest)
+		}
+		vehicles := tr.trailers
+		if tr.vehicle != nil {
+			vehicles = append(vehicles, *tr.vehicle)
+		}
+		for _, vehicle := range vehicles {
+			if !br_documents.ValidatePlate(vehicle.plate) || len(vehicle.RNTC) != 8 {
+				t.Errorf("Expected a valid plate and RNTRC, got %+v", vehicle)
+			}
+		}
+		if len(tr.volumes) == 0 {
+			t.Fatalf("Expected the volumes of the goods")
+		}
+		for _, v := range tr.volumes {
+			if v.pesoB < v.pesoL || v.qVol < 1 {
+				t.Errorf("Expected the gross weight %d above the net weight %d", v.pesoB, v.pesoL)
+			}
+		}
+	}
+	for _, mode := range []string{"0", "1", "2", "3", "9"} {
+		if !modes[mode] {
+			t.Errorf("Expected the freight mode %s to be generated", mode)
+		}
+	}
+}
+
+func TestApplyTransport_WithoutFreight(t *testing.T) {
+	for _, tt := range []TemplateType{NFCe, NFeComplementar, NFeAjuste} {
+		doc, _ := newMockDocument(tt, &generationConfig{})
+		if doc.transport.modFrete != "9" || doc.transport.carrier != nil {
+			t.Errorf("Expected no freight in %v documents, got %+v", tt, doc.transport)
+		}
+	}
+}
+
+func TestTranspGroup_Schema(t *testing.T) {
+	for _, tt := range []TemplateType{NFe, NFCe, NFeDevolucao} {
+		t.Run(tt.String(), func(t *testing.T) {
+			generator, err := NewTemplateGenerator(tt)
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			for i := 0; i < 30; i++ {
+				xmlBytes, err := generator.Generate()
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if !strings.Contains(string(xmlBytes), "<modFrete>") {
+					t.Fatalf("Expected the transport group")
+				}
+				if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+					t.Fatalf("Expected no schema violations, got %v", errs)
+				}
+			}
+		})
+	}
+}
+
+func TestPlate(t *testing.T) {
+	mercosul, legacy := false, false
+	for i := 0; i < 100; i++ {
+		plate := br_documents.Plate()
+		if !br_documents.ValidatePlate(plate) {
+			t.Fatalf("Expected a valid plate, got %s", plate)
+		}
+		if plate[4] >= 'A' {
+			mercosul = true
+		} else {
+			legacy = true
+		}
+	}
+	if !mercosul || !legacy {
+		t.Errorf("Expected Mercosul and legacy plates")
+	}
+	for _, invalid := range []string{"", "AB1234", "ABC12345", "abc1d23", "ABC1DD3", "1BC1234"} {
+		if br_documents.ValidatePlate(invalid) {
+			t.Errorf("Expected %q to be invalid", invalid)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package nfs
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mayckol/brfiscalfaker/pkg/universe"
+)
+
+// checkUniverse fails when the document of the TemplateType cannot be issued among the parties of
+// the universe: CF-e documents, referenced invoices and rural producers keep parties of their own,
+// and some company must be able to issue the document with the chosen situations.
+func checkUniverse(tt TemplateType, cfg *generationConfig) error {
+	if cfg.universe == nil {
+		return nil
+	}
+	switch {
+	case tt == CFe:
+		return fmt.Errorf("universes are not supported for %v documents", tt)
+	case cfg.referencedInvoice != nil:
+		return fmt.Errorf("universes are not supported with a referenced invoice, whose parties are kept")
+	case cfg.CPF != "" || cfg.CNPJ != "":
+		return fmt.Errorf("universes are not supported with a custom CPF or CNPJ")
+	case emitterSpecs[cfg.emitter].person:
+		return fmt.Errorf("%v is not supported with a universe, whose emitters are companies", cfg.emitter)
+	}
+	if len(universeEmitters(cfg)) == 0 {
+		return fmt.Errorf("no company of the universe can issue the document with the chosen emitter profile, situations and contingency")
+	}
+	return nil
+}
+
+// universeEmitters returns the companies of the universe that can issue the document: the ones of
+// the CRT of the emitter profile or of the regime of the chosen situations, located in a state
+// served by the contingency mode.
+func universeEmitters(cfg *generationConfig) []universe.Company {
+	profile, hasProfile := emitterSpecs[cfg.emitter]
+	simples, hasSituations := simplesNacional(cfg.icms)
+	states := contingencySpecs[cfg.contingency].states
+
+	var candidates []universe.Company
+	for _, company := range cfg.universe.Companies() {
+		companySimples := company.CRT == "1" || company.CRT == "4"
+		switch {
+		case hasProfile && company.CRT != profile.CRT:
+		case hasSituations && companySimples != simples:
+		case regularPIS(cfg.pis) && company.CRT != "3":
+		case len(states) > 0 && !slices.Contains(states, company.Address.Municipality.UF):
+		default:
+			candidates = append(candidates, company)
+		}
+	}
+	return candidates
+}
+
+// applyUniverseEmitter makes the document issued by a company of the universe, with its address.
+func (doc *mockDocument) applyUniverseEmitter(cfg *generationConfig) {
+	candidates := universeEmitters(cfg)
+	company := candidates[gofakeit.Number(0, len(candidates)-1)]
+	doc.emit = mockParty{CNPJ: company.CNPJ, IE: company.IE, city: newMunicipality(company.Address.Municipality)}
+	doc.emit.address = &company.Address
+	doc.emitName = company.Name
+}
+
+// applyUniverse takes the regime and the products of the emitter from the universe, and picks the
+// recipient among its companies, for recipients with a CNPJ, or its consumers, for recipients with a
+// CPF. The recipient lives where the operation requires, which is the emitter state in NFC-e
+// documents; the random recipient is kept when the universe has none there, as are recipients
+// abroad and the other establishment of the emitter in transfers.
+func (doc *mockDocument) applyUniverse(u *universe.Universe) {
+	if company, ok := u.Company(doc.emit.CNPJ); ok {
+		doc.company = &company
+	}
+	if doc.operation == OperationTransferencia || doc.dest.idEstrangeiro != "" || doc.recipient == RecipientNone {
+		return
+	}
+
+	location := operationSpecs[doc.operation].location
+	if doc.templateType == NFCe {
+		location = sameState
+	}
+	located := func(address universe.Address) bool {
+		switch location {
+		case sameState:
+			return address.Municipality.UF == doc.emit.city.uf
+		case otherState:
+			return address.Municipality.UF != doc.emit.city.uf
+		}
+		return true
+	}
+
+	if doc.dest.CNPJ != "" {
+		var candidates []universe.Company
+		for _, company := range u.Companies() {
+			if company.CNPJ != doc.emit.CNPJ && located(company.Address) {
+				candidates = append(candidates, company)
+			}
+		}
+		if len(candidates) == 0 {
+			return
+		}
+		company := candidates[gofakeit.Number(0, len(candidates)-1)]
+		doc.dest = mockParty{CNPJ: company.CNPJ, city: newMunicipality(company.Address.Municipality)}
+		if doc.indIEDest == "1" {
+			doc.dest.IE = company.IE
+		}
+		doc.dest.address = &company.Address
+		doc.destName, doc.destEmail = company.Name, company.Email
+	} else {
+		var candidates []universe.Consumer
+		for _, consumer := range u.Consumers() {
+			if located(consumer.Address) {
+				candidates = append(candidates, consumer)
+			}
+		}
+		if len(candidates) == 0 {
+			return
+		}
+		consumer := candidates[gofakeit.Number(0, len(candidates)-1)]
+		doc.dest = mockParty{CPF: consumer.CPF, city: newMunicipality(consumer.Address.Municipality)}
+		doc.dest.address = &consumer.Address
+		doc.destName, doc.destEmail = consumer.Name, consumer.Email
+	}
+	if doc.tpAmb == "2" {
+		doc.destName = homologationRecipientName
+	}
+}
+
+// companyProduct gives the item a product sold by the emitter, with its code and GTIN. Items subject
+// to ICMS-ST get a product listed for ST, from the catalog when the emitter sells none.
+func (i *mockItem) companyProduct(company *universe.Company, st bool) {
+	var candidates []universe.Product
+	for _, product := range company.Products {
+		if !st || product.CEST != "" {
+			candidates = append(candidates, product)
+		}
+	}
+	if len(candidates) == 0 {
+		i.product = randomProduct(st)
+		i.gtin = productGTIN(i.product)
+		return
+	}
+	product := candidates[gofakeit.Number(0, len(candidates)-1)]
+	i.product = newCatalogProduct(product.Product)
+	i.code = product.Code
+	i.gtin = product.GTIN
+}
+
+// street returns the street (xLgr) of the party, which is random unless it comes from a universe.
+func (p mockParty) street() string {
+	if p.address == nil {
+		return xLgr()
+	}
+	return p.address.Street
+}
+
+// number returns the number (nro) of the address of the party.
+func (p mockParty) number() string {
+	if p.address == nil {
+		return nro()
+	}
+	return p.address.Number
+}
+
+// complement returns the complement (xCpl) of the address of the party.
+func (p mockParty) complement() string {
+	if p.address == nil {
+		return xCpl()
+	}
+	return p.address.Complement
+}
+
+// neighborhood returns the neighborhood (xBairro) of the address of the party.
+func (p mockParty) neighborhood() string {
+	if p.address == nil {
+		return xBairro()
+	}
+	return p.address.Neighborhood
+}
+
+// postalCode returns the CEP of the address of the party.
+func (p mockParty) postalCode() string {
+	if p.address == nil {
+		return CEP()
+	}
+	return p.address.CEP
+}
+
+// phone returns the phone (fone) of the party, with an area code of its state.
+func (p mockParty) phone() string {
+	if p.address == nil {
+		return fone(p.city.uf)
+	}
+	return p.address.Phone
+}
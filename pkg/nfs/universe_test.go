@@ -0,0 +1,96 @@
+package nfs
+
+import (
+	"html"
+	"regexp"
+	"testing"
+
+	"github.com/mayckol/brfiscalfaker/pkg/universe"
+)
+
+func TestWithUniverse(t *testing.T) {
+	u := universe.New(7, 10, 40)
+	emitRe := regexp.MustCompile(`(?s)<emit>\s*<CNPJ>([0-9]+)</CNPJ>\s*<xNome>([^<]+)</xNome>.*?<xLgr>([^<]+)</xLgr>\s*<nro>([^<]+)</nro>.*?<IE>([0-9]+)</IE>\s*<CRT>([0-9])</CRT>`)
+	destRe := regexp.MustCompile(`(?s)<dest>\s*<(CNPJ|CPF)>([0-9]+)</`)
+	for _, tt := range []TemplateType{NFe, NFCe, NFeDevolucao} {
+		t.Run(tt.String(), func(t *testing.T) {
+			generator, err := NewTemplateGenerator(tt)
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			emitters := make(map[string]int)
+			for i := 0; i < 40; i++ {
+				xmlBytes, err := generator.Generate(WithUniverse(u))
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				document := string(xmlBytes)
+				match := emitRe.FindStringSubmatch(document)
+				if match == nil {
+					t.Fatalf("Expected the emitter\n%s", document)
+				}
+				company, ok := u.Company(match[1])
+				if !ok {
+					t.Fatalf("Expected an emitter of the universe, got %s", match[1])
+				}
+				if html.UnescapeString(match[2]) != company.Name || match[3] != company.Address.Street || match[4] != company.Address.Number ||
+					match[5] != company.IE || match[6] != company.CRT {
+					t.Errorf("Expected the data of %s, got %v", company.Name, match[2:])
+				}
+				emitters[company.CNPJ]++
+
+				dest := destRe.FindStringSubmatch(document)
+				if dest == nil {
+					t.Fatalf("Expected the recipient\n%s", document)
+				}
+				if _, ok := u.Consumer(dest[2]); dest[1] == "CPF" && !ok {
+					t.Errorf("Expected a consumer of the universe, got %s", dest[2])
+				}
+				if _, ok := u.Company(dest[2]); dest[1] == "CNPJ" && (!ok || dest[2] == company.CNPJ) {
+					t.Errorf("Expected another company of the universe, got %s", dest[2])
+				}
+				if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+					t.Fatalf("Expected no schema violations, got %v", errs)
+				}
+			}
+			if len(emitters) == 40 {
+				t.Errorf("Expected repeated emitters, got %v", emitters)
+			}
+		})
+	}
+}
+
+func TestWithUniverse_Options(t *testing.T) {
+	u := universe.New(1, 30, 30)
+	crtRe := regexp.MustCompile(`<CRT>([0-9])</CRT>`)
+	for i := 0; i < 20; i++ {
+		xmlBytes, err := NewNFeGenerator().Generate(WithUniverse(u), WithEmitterProfile(EmitterRegimeNormal), WithSequence(NewSequence(1, 1)))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if crt := crtRe.FindStringSubmatch(string(xmlBytes))[1]; crt != "3" {
+			t.Errorf("Expected a company of the Regime Normal, got CRT %s", crt)
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		tt      TemplateType
+		options []Option
+	}{
+		{"CFe", CFe, nil},
+		{"ProdutorRural", NFe, []Option{WithEmitterProfile(EmitterProdutorRural)}},
+		{"CNPJ", NFe, []Option{WithCNPJ("11222333000181")}},
+		{"NoCompanies", NFe, []Option{WithUniverse(universe.New(1, 0, 10))}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			generator, err := NewTemplateGenerator(tc.tt)
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			if _, err := generator.Generate(append([]Option{WithUniverse(u)}, tc.options...)...); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,496 @@
+package nfs
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError describes a single schema violation found in a document.
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+// Error implements the error interface.
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Validate checks the XML document against the structural rules of the official
+// schema for the given TemplateType (nfe_v4.00.xsd for NF-e and NFC-e, CFe_v0.08.xsd for CF-e).
+// It returns every violation found, or nil when the document is valid.
+func Validate(tt TemplateType, document []byte) []ValidationError {
+	rule, ok := schemaFor(tt)
+	if !ok {
+		return []ValidationError{{Path: "/", Message: fmt.Sprintf("unsupported template type: %v", tt)}}
+	}
+
+	root, err := parseXMLTree(document)
+	if err != nil {
+		return []ValidationError{{Path: "/", Message: fmt.Sprintf("malformed XML: %v", err)}}
+	}
+
+	v := &validator{}
+	if v.matchSequence("", []particle{rule}, []*xmlNode{root}) == 0 && len(v.errors) == 0 {
+		v.report("/", fmt.Sprintf("unexpected root element <%s>", root.name))
+	}
+	return v.errors
+}
+
+// DetectTemplateType inspects the root of the document to find out which TemplateType it belongs to.
+// NF-e documents with finNFe 2, 3 and 4 are reported as NFeComplementar, NFeAjuste and NFeDevolucao.
+func DetectTemplateType(document []byte) (TemplateType, error) {
+	root, err := parseXMLTree(document)
+	if err != nil {
+		return -1, fmt.Errorf("malformed XML: %v", err)
+	}
+
+	switch root.name {
+	case "CFe":
+		return CFe, nil
+	case "nfeProc", "NFe":
+		switch root.find("infNFe", "ide", "mod").textOr("") {
+		case "65":
+			return NFCe, nil
+		case "55":
+			switch root.find("infNFe", "ide", "finNFe").textOr("") {
+			case "2":
+				return NFeComplementar, nil
+			case "3":
+				return NFeAjuste, nil
+			case "4":
+				return NFeDevolucao, nil
+			}
+			return NFe, nil
+		}
+		return -1, fmt.Errorf("unknown document model")
+	default:
+		return -1, fmt.Errorf("unknown root element: %s", root.name)
+	}
+}
+
+// xmlNode is a minimal element tree used by the validator.
+type xmlNode struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*xmlNode
+}
+
+// find walks down the tree following the given element names, skipping an nfeProc/NFe wrapper.
+// It returns nil when any step is missing.
+func (n *xmlNode) find(names ...string) *xmlNode {
+	current := n
+	if current != nil && current.name == "nfeProc" && len(names) > 0 && names[0] != "NFe" && names[0] != "protNFe" {
+		current = current.child("NFe")
+	}
+	for _, name := range names {
+		if current == nil {
+			return nil
+		}
+		current = current.child(name)
+	}
+	return current
+}
+
+// child returns the first direct child with the given name.
+func (n *xmlNode) child(name string) *xmlNode {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// textOr returns the element text or the fallback when the node is nil.
+func (n *xmlNode) textOr(fallback string) string {
+	if n == nil {
+		return fallback
+	}
+	return n.text
+}
+
+// parseXMLTree decodes the document into an xmlNode tree, ignoring namespaces.
+func parseXMLTree(document []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: make(map[string]string)}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, fmt.Errorf("multiple root elements")
+				}
+				root = node
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("empty document")
+	}
+	return root, nil
+}
+
+// valueType describes the lexical rules of a simple XSD type.
+type valueType struct {
+	pattern *regexp.Regexp
+	values  []string
+	minLen  int
+	maxLen  int
+	check   func(string) bool
+}
+
+// validate reports why the value does not conform to the type, or "" when it does.
+func (t *valueType) validate(value string) string {
+	length := utf8.RuneCountInString(value)
+	if t.minLen > 0 && length < t.minLen {
+		return fmt.Sprintf("value %q is shorter than %d characters", value, t.minLen)
+	}
+	if t.maxLen > 0 && length > t.maxLen {
+		return fmt.Sprintf("value %q is longer than %d characters", value, t.maxLen)
+	}
+	if t.pattern != nil && !t.pattern.MatchString(value) {
+		if len(t.values) > 0 {
+			return fmt.Sprintf("value %q is not one of %s", value, strings.Join(t.values, ", "))
+		}
+		return fmt.Sprintf("value %q does not match pattern %s", value, t.pattern.String())
+	}
+	if t.check != nil && !t.check(value) {
+		return fmt.Sprintf("value %q is not valid", value)
+	}
+	return ""
+}
+
+// length returns a copy of the type restricted to the given length.
+func (t *valueType) length(minLen, maxLen int) *valueType {
+	clone := *t
+	clone.minLen, clone.maxLen = minLen, maxLen
+	return &clone
+}
+
+// checked returns a copy of the type with an additional check.
+func (t *valueType) checked(check func(string) bool) *valueType {
+	clone := *t
+	clone.check = check
+	return &clone
+}
+
+// pattern returns a valueType that must fully match the regular expression.
+func pattern(expr string) *valueType {
+	return &valueType{pattern: regexp.MustCompile(`^(?:` + expr + `)$`)}
+}
+
+// enum returns a valueType accepting only the listed values.
+func enum(values ...string) *valueType {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = regexp.QuoteMeta(value)
+	}
+	t := pattern(strings.Join(quoted, "|"))
+	t.values = values
+	return t
+}
+
+// tString returns the schema TString type restricted to the given length.
+func tString(minLen, maxLen int) *valueType {
+	return &valueType{
+		pattern: regexp.MustCompile(`^(?:[!-\x{FF}][ -\x{FF}]*[!-\x{FF}]|[!-\x{FF}])$`),
+		minLen:  minLen,
+		maxLen:  maxLen,
+	}
+}
+
+// particle is a piece of a content model: an element or a choice between sequences.
+type particle interface {
+	match(v *validator, path string, nodes []*xmlNode, i int) int
+	names() []string
+}
+
+// elementRule describes an element, its occurrence and either its simple value or its children.
+type elementRule struct {
+	name     string
+	min, max int
+	value    *valueType
+	attrs    []attributeRule
+	content  []particle
+	anything bool
+}
+
+// attributeRule describes an attribute of an element.
+type attributeRule struct {
+	name     string
+	required bool
+	value    *valueType
+}
+
+// choiceRule describes an xs:choice between sequences.
+type choiceRule struct {
+	min, max int
+	options  [][]particle
+}
+
+// el returns a required simple element.
+func el(name string, value *valueType) *elementRule {
+	return &elementRule{name: name, min: 1, max: 1, value: value}
+}
+
+// group returns a required complex element with the given sequence of children.
+func group(name string, content ...particle) *elementRule {
+	return &elementRule{name: name, min: 1, max: 1, content: content}
+}
+
+// opaque returns a required element whose content is not checked.
+func opaque(name string) *elementRule {
+	return &elementRule{name: name, min: 1, max: 1, anything: true}
+}
+
+// optional marks the element as minOccurs="0".
+func (e *elementRule) optional() *elementRule {
+	clone := *e
+	clone.min = 0
+	return &clone
+}
+
+// repeated sets the element occurrence bounds.
+func (e *elementRule) repeated(min, max int) *elementRule {
+	clone := *e
+	clone.min, clone.max = min, max
+	return &clone
+}
+
+// attr adds a required attribute to the element.
+func (e *elementRule) attr(name string, value *valueType) *elementRule {
+	clone := *e
+	clone.attrs = append(append([]attributeRule{}, e.attrs...), attributeRule{name: name, required: true, value: value})
+	return &clone
+}
+
+// optionalAttr adds an optional attribute to the element.
+func (e *elementRule) optionalAttr(name string, value *valueType) *elementRule {
+	clone := *e
+	clone.attrs = append(append([]attributeRule{}, e.attrs...), attributeRule{name: name, value: value})
+	return &clone
+}
+
+// choice returns a required choice between the given sequences.
+func choice(options ...[]particle) *choiceRule {
+	return &choiceRule{min: 1, max: 1, options: options}
+}
+
+// optional marks the choice as minOccurs="0".
+func (c *choiceRule) optional() *choiceRule {
+	clone := *c
+	clone.min = 0
+	return &clone
+}
+
+// repeated sets the choice occurrence bounds.
+func (c *choiceRule) repeated(min, max int) *choiceRule {
+	clone := *c
+	clone.min, clone.max = min, max
+	return &clone
+}
+
+// optionalSeq returns an optional sequence of particles.
+func optionalSeq(particles ...particle) *choiceRule {
+	return choice(particles).optional()
+}
+
+// seq groups particles into a sequence used as a choice option.
+func seq(particles ...particle) []particle {
+	return particles
+}
+
+// concat joins sequences into a new one.
+func concat(sequences ...[]particle) []particle {
+	var joined []particle
+	for _, sequence := range sequences {
+		joined = append(joined, sequence...)
+	}
+	return joined
+}
+
+func (e *elementRule) names() []string {
+	return []string{e.name}
+}
+
+func (e *elementRule) match(v *validator, path string, nodes []*xmlNode, i int) int {
+	count := 0
+	for i < len(nodes) && nodes[i].name == e.name && (e.max < 0 || count < e.max) {
+		elementPath := path + "/" + e.name
+		if e.max != 1 {
+			elementPath = fmt.Sprintf("%s[%d]", elementPath, count+1)
+		}
+		v.validateElement(elementPath, e, nodes[i])
+		count++
+		i++
+	}
+	if count < e.min {
+		v.report(path, fmt.Sprintf("missing required element <%s>%s", e.name, foundSuffix(nodes, i)))
+	}
+	return i
+}
+
+func (c *choiceRule) names() []string {
+	var names []string
+	for _, option := range c.options {
+		names = append(names, sequenceNames(option)...)
+	}
+	return names
+}
+
+func (c *choiceRule) match(v *validator, path string, nodes []*xmlNode, i int) int {
+	count := 0
+	for i < len(nodes) && (c.max < 0 || count < c.max) {
+		option := c.optionFor(nodes[i].name)
+		if option == nil {
+			break
+		}
+		i = v.matchSequence(path, option, nodes[i:]) + i
+		count++
+	}
+	if count < c.min {
+		v.report(path, fmt.Sprintf("expected one of <%s>%s", strings.Join(c.names(), ">, <"), foundSuffix(nodes, i)))
+	}
+	return i
+}
+
+// optionFor returns the option that can start with the given element name.
+func (c *choiceRule) optionFor(name string) []particle {
+	for _, option := range c.options {
+		for _, candidate := range sequenceNames(option) {
+			if candidate == name {
+				return option
+			}
+		}
+	}
+	return nil
+}
+
+// sequenceNames returns the element names that may start the sequence.
+func sequenceNames(sequence []particle) []string {
+	var names []string
+	for _, p := range sequence {
+		names = append(names, p.names()...)
+		if !isOptional(p) {
+			break
+		}
+	}
+	return names
+}
+
+// isOptional reports whether the particle may be absent.
+func isOptional(p particle) bool {
+	switch r := p.(type) {
+	case *elementRule:
+		return r.min == 0
+	case *choiceRule:
+		return r.min == 0
+	}
+	return false
+}
+
+// foundSuffix describes the node found where something else was expected.
+func foundSuffix(nodes []*xmlNode, i int) string {
+	if i < len(nodes) {
+		return fmt.Sprintf(", found <%s>", nodes[i].name)
+	}
+	return ""
+}
+
+// validator accumulates errors while walking the document.
+type validator struct {
+	errors []ValidationError
+}
+
+func (v *validator) report(path, message string) {
+	if path == "" {
+		path = "/"
+	}
+	v.errors = append(v.errors, ValidationError{Path: path, Message: message})
+}
+
+// matchSequence matches the particles against the nodes and returns how many nodes were consumed.
+func (v *validator) matchSequence(path string, sequence []particle, nodes []*xmlNode) int {
+	i := 0
+	for _, p := range sequence {
+		i = p.match(v, path, nodes, i)
+	}
+	return i
+}
+
+// validateElement checks the attributes and content of a node against its rule.
+func (v *validator) validateElement(path string, rule *elementRule, node *xmlNode) {
+	for _, attr := range rule.attrs {
+		value, ok := node.attrs[attr.name]
+		if !ok {
+			if attr.required {
+				v.report(path, fmt.Sprintf("missing required attribute %s", attr.name))
+			}
+			continue
+		}
+		if attr.value != nil {
+			if problem := attr.value.validate(value); problem != "" {
+				v.report(path+"/@"+attr.name, problem)
+			}
+		}
+	}
+
+	switch {
+	case rule.anything:
+		return
+	case rule.value != nil:
+		if len(node.children) > 0 {
+			v.report(path, "unexpected child elements in simple element")
+			return
+		}
+		if problem := rule.value.validate(node.text); problem != "" {
+			v.report(path, problem)
+		}
+	default:
+		consumed := v.matchSequence(path, rule.content, node.children)
+		for _, extra := range node.children[consumed:] {
+			v.report(path, fmt.Sprintf("unexpected element <%s>", extra.name))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package nfs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mayckol/brfiscalfaker/utils"
+)
+
+func TestValidate_GeneratedDocuments(t *testing.T) {
+	const documentsPerType = 200
+
+	for _, tt := range []TemplateType{CFe, NFe, NFCe, NFeDevolucao, NFeComplementar, NFeAjuste} {
+		t.Run(tt.String(), func(t *testing.T) {
+			generator, err := NewTemplateGenerator(tt)
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+
+			for i := 0; i < documentsPerType; i++ {
+				xmlBytes, err := generator.Generate()
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+
+				if errs := Validate(tt, xmlBytes); len(errs) > 0 {
+					t.Fatalf("Expected a schema-valid document, got %d errors (first: %v)\n%s", len(errs), errs[0], xmlBytes)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsViolations(t *testing.T) {
+	xmlBytes, err := NewNFeGenerator().Generate()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		document string
+		path     string
+	}{
+		{
+			name:     "empty required tag",
+			document: utils.ReplaceElement(string(xmlBytes), "natOp", ""),
+			path:     "/NFe/infNFe/ide/natOp",
+		},
+		{
+			name:     "wrong field format",
+			document: utils.ReplaceElement(string(xmlBytes), "CFOP", "51O2"),
+			path:     "/NFe/infNFe/det[1]/prod/CFOP",
+		},
+		{
+			name:     "missing required tag",
+			document: strings.Replace(string(xmlBytes), "<mod>55</mod>", "", 1),
+			path:     "/NFe/infNFe/ide",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := Validate(NFe, []byte(tc.document))
+			if len(errs) == 0 {
+				t.Fatalf("Expected validation errors, got none")
+			}
+
+			found := false
+			for _, e := range errs {
+				if strings.HasPrefix(e.Path, tc.path) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected an error at %s, got %v", tc.path, errs)
+			}
+		})
+	}
+}
+
+func TestValidate_MalformedXML(t *testing.T) {
+	errs := Validate(NFe, []byte("<NFe><infNFe>"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, "malformed XML") {
+		t.Errorf("Expected a single malformed XML error, got %v", errs)
+	}
+}
+
+func TestDetectTemplateType(t *testing.T) {
+	for _, tt := range []TemplateType{CFe, NFe, NFCe, NFeDevolucao, NFeComplementar, NFeAjuste} {
+		generator, _ := NewTemplateGenerator(tt)
+		xmlBytes, err := generator.Generate()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		detected, err := DetectTemplateType(xmlBytes)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if detected != tt {
+			t.Errorf("Expected %v, got %v", tt, detected)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package nfs
+
+import (
+	"html"
+	"strings"
+)
+
+// xmlElement is a simple element of a group.
+type xmlElement struct {
+	name  string
+	value string
+}
+
+// xmlBuilder builds the lines of an XML fragment, nesting the groups by two spaces. The values are
+// escaped, so the callers pass them as generated.
+type xmlBuilder struct {
+	lines []string
+	depth int
+}
+
+// open starts the group and nests the following lines in it.
+func (x *xmlBuilder) open(name string) {
+	x.lines = append(x.lines, strings.Repeat("  ", x.depth)+"<"+name+">")
+	x.depth++
+}
+
+// close ends the innermost group.
+func (x *xmlBuilder) close(name string) {
+	x.depth--
+	x.lines = append(x.lines, strings.Repeat("  ", x.depth)+"</"+name+">")
+}
+
+// element adds a simple element.
+func (x *xmlBuilder) element(name, value string) {
+	x.lines = append(x.lines, strings.Repeat("  ", x.depth)+"<"+name+">"+html.EscapeString(value)+"</"+name+">")
+}
+
+// elements adds the simple elements in order.
+func (x *xmlBuilder) elements(elements []xmlElement) {
+	for _, e := range elements {
+		x.element(e.name, e.value)
+	}
+}
+
+func (x *xmlBuilder) String() string {
+	return strings.Join(x.lines, "\n")
+}
+
+// xmlGroup renders a group of simple elements.
+func xmlGroup(name string, elements []xmlElement) string {
+	x := &xmlBuilder{}
+	x.open(name)
+	x.elements(elements)
+	x.close(name)
+	return x.String()
+}
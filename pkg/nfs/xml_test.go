@@ -0,0 +1,25 @@
+package nfs
+
+import "testing"
+
+func TestXMLBuilder(t *testing.T) {
+	x := &xmlBuilder{}
+	x.open("transp")
+	x.open("vol")
+	x.element("marca", `Silva & Filhos <"ME">`)
+	x.close("vol")
+	x.close("transp")
+
+	expected := "<transp>\n  <vol>\n    <marca>Silva &amp; Filhos &lt;&#34;ME&#34;&gt;</marca>\n  </vol>\n</transp>"
+	if got := x.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestXMLGroup(t *testing.T) {
+	expected := "<exporta>\n  <UFSaidaPais>SP</UFSaidaPais>\n  <xLocExporta>Porto D&#39;Ávila</xLocExporta>\n</exporta>"
+	got := xmlGroup("exporta", []xmlElement{{"UFSaidaPais", "SP"}, {"xLocExporta", "Porto D'Ávila"}})
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
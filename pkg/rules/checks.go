@@ -0,0 +1,575 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+)
+
+// homologationRecipientName is the recipient name required in the homologation environment (tpAmb 2).
+const homologationRecipientName = "NF-E EMITIDA EM AMBIENTE DE HOMOLOGACAO - SEM VALOR FISCAL"
+
+// municipalityDigitExceptions are IBGE codes whose check digit does not follow the rule,
+// accepted by SEFAZ as they are.
+var municipalityDigitExceptions = map[string]bool{
+	"2201919": true, "2201988": true, "2202251": true, "2611533": true, "3117836": true,
+	"3152131": true, "4305871": true, "5203939": true, "5203962": true,
+}
+
+// returnCFOPs are the last three digits of the CFOPs of returns (devolução) and their reversals.
+var returnCFOPs = map[string]bool{
+	"201": true, "202": true, "203": true, "204": true, "208": true, "209": true, "210": true,
+	"211": true, "212": true, "410": true, "411": true, "412": true, "413": true, "503": true,
+	"504": true, "505": true, "506": true, "553": true, "555": true, "556": true, "660": true,
+	"661": true, "662": true, "918": true, "919": true, "921": true,
+}
+
+// none is returned by checks that found no violation.
+var none []string
+
+// failIf returns the detail when the condition holds.
+func failIf(condition bool, format string, args ...interface{}) []string {
+	if !condition {
+		return none
+	}
+	return []string{fmt.Sprintf(format, args...)}
+}
+
+func checkEmitterUF(d *document) []string {
+	uf, ok := br_documents.UFByCode(d.InfNFe.Ide.CUF)
+	return failIf(ok && uf != d.InfNFe.Emit.Ender.UF, "cUF %s is %s, emitter is in %s", d.InfNFe.Ide.CUF, uf, d.InfNFe.Emit.Ender.UF)
+}
+
+func checkEmitterCNPJ(d *document) []string {
+	cnpj := d.InfNFe.Emit.CNPJ
+	return failIf(cnpj != "" && !br_documents.ValidateCNPJ(cnpj), "CNPJ %s", cnpj)
+}
+
+func checkEmitterCPF(d *document) []string {
+	cpf := d.InfNFe.Emit.CPF
+	return failIf(cpf != "" && !br_documents.ValidateCPF(cpf), "CPF %s", cpf)
+}
+
+func checkEmitterIE(d *document) []string {
+	ie, uf := d.InfNFe.Emit.IE, d.InfNFe.Emit.Ender.UF
+	return failIf(ie != "" && ie != "ISENTO" && !br_documents.ValidateIE(uf, ie), "IE %s for %s", ie, uf)
+}
+
+func checkRecipientCNPJ(d *document) []string {
+	if d.InfNFe.Dest == nil {
+		return none
+	}
+	cnpj := d.InfNFe.Dest.CNPJ
+	return failIf(cnpj != "" && !br_documents.ValidateCNPJ(cnpj), "CNPJ %s", cnpj)
+}
+
+func checkRecipientCPF(d *document) []string {
+	if d.InfNFe.Dest == nil {
+		return none
+	}
+	cpf := d.InfNFe.Dest.CPF
+	return failIf(cpf != "" && !br_documents.ValidateCPF(cpf), "CPF %s", cpf)
+}
+
+func checkRecipientIE(d *document) []string {
+	if d.InfNFe.Dest == nil {
+		return none
+	}
+	ie, uf := d.InfNFe.Dest.IE, d.destUF()
+	return failIf(ie != "" && uf != "EX" && !br_documents.ValidateIE(uf, ie), "IE %s for %s", ie, uf)
+}
+
+// accessKeyOf returns the access key from the Id attribute, or from the protocol when it is absent.
+func accessKeyOf(d *document) string {
+	if key := strings.TrimPrefix(d.InfNFe.ID, "NFe"); key != "" {
+		return key
+	}
+	if d.ProtNFe != nil {
+		return d.ProtNFe.InfProt.ChNFe
+	}
+	return ""
+}
+
+func checkAccessKeyDigit(d *document) []string {
+	key := accessKeyOf(d)
+	return failIf(len(key) == 44 && !br_documents.ValidateAccessKey(key), "chave %s", key)
+}
+
+func checkAccessKey(d *document) []string {
+	ide := d.InfNFe.Ide
+	key := accessKeyOf(d)
+
+	emitted, ok := parseTime(ide.DhEmi)
+	if !ok {
+		return none // reported by the schema validation
+	}
+	yearMonth := emitted.Format("0601")
+	serie, _ := strconv.Atoi(ide.Serie)
+	number, _ := strconv.Atoi(ide.NNF)
+	expected := fmt.Sprintf("%s%s%014s%s%03d%09d%s%08s%s", ide.CUF, yearMonth, d.emitterDocument(), ide.Mod, serie, number, ide.TpEmis, ide.CNF, ide.CDV)
+	return failIf(key != expected, "chave %s, ide fields give %s", key, expected)
+}
+
+func checkEmissionAfterReceipt(d *document) []string {
+	emitted, ok := parseTime(d.InfNFe.Ide.DhEmi)
+	// SEFAZ tolerates clocks up to 5 minutes ahead.
+	return failIf(ok && emitted.After(d.reference.Add(5*time.Minute)), "dhEmi %s", d.InfNFe.Ide.DhEmi)
+}
+
+func checkEmissionTooOld(d *document) []string {
+	emitted, ok := parseTime(d.InfNFe.Ide.DhEmi)
+	return failIf(ok && emitted.Before(d.reference.AddDate(0, 0, -30)), "dhEmi %s", d.InfNFe.Ide.DhEmi)
+}
+
+func checkNFCeEmissionDelayed(d *document) []string {
+	if !d.isNFCe() || d.InfNFe.Ide.TpEmis != "1" {
+		return none
+	}
+	emitted, ok := parseTime(d.InfNFe.Ide.DhEmi)
+	return failIf(ok && emitted.Before(d.reference.Add(-5*time.Minute)), "dhEmi %s", d.InfNFe.Ide.DhEmi)
+}
+
+// recipientMunicipality returns the recipient municipality code, or "" when there is none.
+func recipientMunicipality(d *document) string {
+	if d.InfNFe.Dest == nil || d.InfNFe.Dest.Ender == nil || d.InfNFe.Dest.Ender.UF == "EX" {
+		return ""
+	}
+	return d.InfNFe.Dest.Ender.CMun
+}
+
+// checkMunicipalityDigit returns a check of the IBGE check digit of a municipality code.
+func checkMunicipalityDigit(code func(d *document) string) func(d *document) []string {
+	return func(d *document) []string {
+		value := code(d)
+		return failIf(value != "" && !validMunicipality(value), "cMun %s", value)
+	}
+}
+
+// checkMunicipalityUF returns a check that a municipality code belongs to a state.
+func checkMunicipalityUF(codeAndUF func(d *document) (string, string)) func(d *document) []string {
+	return func(d *document) []string {
+		value, uf := codeAndUF(d)
+		if len(value) < 2 || uf == "" {
+			return none
+		}
+		ufCode, _ := br_documents.UFCode(uf)
+		return failIf(value[:2] != ufCode, "cMun %s, UF %s", value, uf)
+	}
+}
+
+// validMunicipality checks the IBGE check digit: weights 1 and 2 alternated over the first
+// six digits, adding up the digits of each product, modulo 10.
+func validMunicipality(code string) bool {
+	if municipalityDigitExceptions[code] {
+		return true
+	}
+	if len(code) != 7 {
+		return false
+	}
+	sum := 0
+	for i, char := range code[:6] {
+		if char < '0' || char > '9' {
+			return false
+		}
+		product := int(char-'0') * (1 + i%2)
+		sum += product/10 + product%10
+	}
+	return int(code[6]-'0') == (10-sum%10)%10
+}
+
+func checkHomologationRecipient(d *document) []string {
+	dest := d.InfNFe.Dest
+	if d.InfNFe.Ide.TpAmb != "2" || dest == nil || dest.XNome == "" {
+		return none
+	}
+	return failIf(dest.XNome != homologationRecipientName, "xNome %s", dest.XNome)
+}
+
+func checkNFCeDANFE(d *document) []string {
+	tpImp := d.InfNFe.Ide.TpImp
+	return failIf(d.isNFCe() && tpImp != "4" && tpImp != "5", "tpImp %s", tpImp)
+}
+
+func checkNFeDANFE(d *document) []string {
+	tpImp := d.InfNFe.Ide.TpImp
+	return failIf(!d.isNFCe() && (tpImp == "4" || tpImp == "5"), "tpImp %s", tpImp)
+}
+
+func checkNFCeEmissionType(d *document) []string {
+	tpEmis := d.InfNFe.Ide.TpEmis
+	return failIf(d.isNFCe() && tpEmis != "1" && tpEmis != "9", "tpEmis %s", tpEmis)
+}
+
+func checkNFCePurpose(d *document) []string {
+	finNFe := d.InfNFe.Ide.FinNFe
+	return failIf(d.isNFCe() && finNFe != "1", "finNFe %s", finNFe)
+}
+
+func checkNFCeFinalConsumer(d *document) []string {
+	indFinal := d.InfNFe.Ide.IndFinal
+	return failIf(d.isNFCe() && indFinal != "1", "indFinal %s", indFinal)
+}
+
+func checkNFCePresence(d *document) []string {
+	indPres := d.InfNFe.Ide.IndPres
+	return failIf(d.isNFCe() && indPres != "1" && indPres != "4", "indPres %s", indPres)
+}
+
+// checkNonContributorFinalConsumer does not apply to foreign trade, whose parties abroad are
+// never ICMS contributors.
+func checkNonContributorFinalConsumer(d *document) []string {
+	dest := d.InfNFe.Dest
+	if dest == nil || dest.IndIEDest != "9" || d.InfNFe.Ide.IdDest == "3" {
+		return none
+	}
+	return failIf(d.InfNFe.Ide.IndFinal != "1", "indIEDest 9, indFinal %s", d.InfNFe.Ide.IndFinal)
+}
+
+// southSoutheast lists the states of the South and Southeast regions, except Espírito Santo,
+// whose sales to the other states are taxed at 7%.
+var southSoutheast = map[string]bool{"MG": true, "PR": true, "RJ": true, "RS": true, "SC": true, "SP": true}
+
+// owesDIFAL reports whether the document is an interstate sale to a non-contributor final consumer,
+// whose items owe the destination state the rate difference (ICMSUFDest).
+func owesDIFAL(d *document) bool {
+	ide, dest := d.InfNFe.Ide, d.InfNFe.Dest
+	return !d.isNFCe() && ide.TpNF == "1" && ide.IdDest == "2" && ide.IndFinal == "1" && dest != nil && dest.IndIEDest == "9"
+}
+
+// taxedICMS reports whether the ICMS group taxes the operation without ICMS-ST.
+func taxedICMS(group taxGroup) bool {
+	switch group.CST + group.CSOSN {
+	case "00", "20", "90", "101", "102", "900":
+		return true
+	}
+	return false
+}
+
+// eachDIFAL returns the details of the items with an ICMSUFDest group for which fails reports a problem.
+func eachDIFAL(d *document, fails func(item det) (string, bool)) []string {
+	var details []string
+	for _, item := range d.InfNFe.Det {
+		if item.Imposto.ICMSUFDest == nil {
+			continue
+		}
+		if detail, failed := fails(item); failed {
+			details = append(details, fmt.Sprintf("item %s, %s", item.NItem, detail))
+		}
+	}
+	return details
+}
+
+func checkDIFALOwnRate(d *document) []string {
+	return eachDIFAL(d, func(item det) (string, bool) {
+		inter := decimal(item.Imposto.ICMSUFDest.PICMSInter)
+		for _, group := range item.Imposto.ICMS.Groups {
+			if group.PICMS != "" && decimal(group.PICMS) > inter {
+				return fmt.Sprintf("pICMS %s, pICMSInter %s", group.PICMS, item.Imposto.ICMSUFDest.PICMSInter), true
+			}
+		}
+		return "", false
+	})
+}
+
+func checkDIFALMissing(d *document) []string {
+	if !owesDIFAL(d) {
+		return none
+	}
+	var details []string
+	for _, item := range d.InfNFe.Det {
+		for _, group := range item.Imposto.ICMS.Groups {
+			if taxedICMS(group) && item.Imposto.ICMSUFDest == nil {
+				details = append(details, fmt.Sprintf("item %s, %s", item.NItem, group.XMLName.Local))
+			}
+		}
+	}
+	return details
+}
+
+func checkDIFALUnexpected(d *document) []string {
+	if owesDIFAL(d) {
+		return none
+	}
+	return eachDIFAL(d, func(item det) (string, bool) { return "ICMSUFDest", true })
+}
+
+// importedOrigin reports whether the goods origin is taxed at 4% in interstate operations (Resolução do Senado 13/2012).
+func importedOrigin(orig string) bool {
+	return orig == "1" || orig == "2" || orig == "3" || orig == "8"
+}
+
+// itemOrigin returns the origin of the goods informed in the ICMS group of the item.
+func itemOrigin(item det) string {
+	if groups := item.Imposto.ICMS.Groups; len(groups) > 0 {
+		return groups[0].Orig
+	}
+	return ""
+}
+
+func checkDIFALImportedRate(d *document) []string {
+	return eachDIFAL(d, func(item det) (string, bool) {
+		orig, inter := itemOrigin(item), amount(item.Imposto.ICMSUFDest.PICMSInter)
+		return fmt.Sprintf("orig %s, pICMSInter %s", orig, item.Imposto.ICMSUFDest.PICMSInter), importedOrigin(orig) != (inter == 400)
+	})
+}
+
+func checkDIFALInterstateRate(d *document) []string {
+	expected := int64(1200)
+	if southSoutheast[d.InfNFe.Emit.Ender.UF] && !southSoutheast[d.destUF()] {
+		expected = 700
+	}
+	return eachDIFAL(d, func(item det) (string, bool) {
+		inter := amount(item.Imposto.ICMSUFDest.PICMSInter)
+		if importedOrigin(itemOrigin(item)) {
+			return "", false
+		}
+		return fmt.Sprintf("pICMSInter %s from %s to %s", item.Imposto.ICMSUFDest.PICMSInter, d.InfNFe.Emit.Ender.UF, d.destUF()), inter != expected
+	})
+}
+
+func checkDIFALPartition(d *document) []string {
+	return eachDIFAL(d, func(item det) (string, bool) {
+		part := item.Imposto.ICMSUFDest.PICMSInterPart
+		return "pICMSInterPart " + part, amount(part) != 10000
+	})
+}
+
+func checkInterstateSameUF(d *document) []string {
+	uf := d.destUF()
+	return failIf(d.InfNFe.Ide.IdDest == "2" && uf == d.InfNFe.Emit.Ender.UF, "idDest 2, UF %s", uf)
+}
+
+func checkInternalDifferentUF(d *document) []string {
+	uf := d.destUF()
+	return failIf(d.InfNFe.Ide.IdDest == "1" && uf != "" && uf != d.InfNFe.Emit.Ender.UF, "idDest 1, UF %s to %s", d.InfNFe.Emit.Ender.UF, uf)
+}
+
+func checkReturnReference(d *document) []string {
+	return failIf(d.InfNFe.Ide.FinNFe == "4" && len(d.InfNFe.Ide.NFref) == 0, "finNFe 4 without NFref")
+}
+
+func checkComplementReference(d *document) []string {
+	return failIf(d.InfNFe.Ide.FinNFe == "2" && len(d.InfNFe.Ide.NFref) == 0, "finNFe 2 without NFref")
+}
+
+// eachCFOP returns the details of the items whose CFOP matches.
+func eachCFOP(d *document, matches func(cfop string) bool) []string {
+	var details []string
+	for _, item := range d.InfNFe.Det {
+		if cfop := item.Prod.CFOP; len(cfop) == 4 && matches(cfop) {
+			details = append(details, fmt.Sprintf("item %s, CFOP %s", item.NItem, cfop))
+		}
+	}
+	return details
+}
+
+func checkReturnCFOP(d *document) []string {
+	if d.InfNFe.Ide.FinNFe != "4" {
+		return none
+	}
+	return eachCFOP(d, func(cfop string) bool { return !returnCFOPs[cfop[1:]] })
+}
+
+func checkReturnCFOPOutsideReturn(d *document) []string {
+	if d.InfNFe.Ide.FinNFe == "4" {
+		return none
+	}
+	return eachCFOP(d, func(cfop string) bool { return returnCFOPs[cfop[1:]] })
+}
+
+func checkEntryCFOPOnExit(d *document) []string {
+	if d.InfNFe.Ide.TpNF != "1" {
+		return none
+	}
+	return eachCFOP(d, func(cfop string) bool { return cfop[0] >= '1' && cfop[0] <= '3' })
+}
+
+func checkExitCFOPOnEntry(d *document) []string {
+	if d.InfNFe.Ide.TpNF != "0" {
+		return none
+	}
+	return eachCFOP(d, func(cfop string) bool { return cfop[0] >= '5' && cfop[0] <= '7' })
+}
+
+func checkIntrastateCFOP(d *document) []string {
+	dest := d.InfNFe.Dest
+	uf := d.destUF()
+	if dest == nil || dest.IndIEDest != "1" || uf == "" || uf == d.InfNFe.Emit.Ender.UF {
+		return none
+	}
+	return eachCFOP(d, func(cfop string) bool { return cfop[0] == '1' || cfop[0] == '5' })
+}
+
+func checkNonIntrastateCFOP(d *document) []string {
+	uf := d.destUF()
+	if uf == "" || uf != d.InfNFe.Emit.Ender.UF {
+		return none
+	}
+	return eachCFOP(d, func(cfop string) bool { return cfop[0] != '1' && cfop[0] != '5' })
+}
+
+// eachICMS returns the details of the items whose ICMS group matches.
+func eachICMS(d *document, matches func(group taxGroup) bool) []string {
+	var details []string
+	for _, item := range d.InfNFe.Det {
+		for _, group := range item.Imposto.ICMS.Groups {
+			if matches(group) {
+				details = append(details, fmt.Sprintf("item %s, %s", item.NItem, group.XMLName.Local))
+			}
+		}
+	}
+	return details
+}
+
+func checkCSTForSimples(d *document) []string {
+	if !d.simples() {
+		return none
+	}
+	return eachICMS(d, func(group taxGroup) bool { return group.CST != "" })
+}
+
+func checkCSOSNOutsideSimples(d *document) []string {
+	if d.simples() || d.InfNFe.Emit.CRT == "" {
+		return none
+	}
+	return eachICMS(d, func(group taxGroup) bool { return group.CSOSN != "" })
+}
+
+// checkItemValue reports the items whose vProd differs by more than one cent from quantity times unit value.
+// Complementary and adjustment NF-e (finNFe 2 and 3) inform values without quantity, so they are not checked.
+func checkItemValue(d *document, values func(p prod) (string, string)) []string {
+	if finNFe := d.InfNFe.Ide.FinNFe; finNFe == "2" || finNFe == "3" {
+		return none
+	}
+	var details []string
+	for _, item := range d.InfNFe.Det {
+		quantity, unitValue := values(item.Prod)
+		if quantity == "" || unitValue == "" {
+			continue
+		}
+		expected := decimal(quantity) * decimal(unitValue)
+		if math.Abs(float64(amount(item.Prod.VProd))-expected*100) > 1+1e-6 {
+			details = append(details, fmt.Sprintf("item %s, vProd %s, %s x %s = %.2f", item.NItem, item.Prod.VProd, quantity, unitValue, expected))
+		}
+	}
+	return details
+}
+
+func checkCommercialValue(d *document) []string {
+	return checkItemValue(d, func(p prod) (string, string) { return p.QCom, p.VUnCom })
+}
+
+func checkTaxableValue(d *document) []string {
+	return checkItemValue(d, func(p prod) (string, string) { return p.QTrib, p.VUnTrib })
+}
+
+// totals holds the ICMSTot of the document next to the values calculated from its items.
+type totals struct {
+	doc                                  *icmsTot
+	vBC, vICMS, vBCST, vST, vProd        int64
+	vFrete, vSeg, vDesc, vII, vIPI, vPIS int64
+	vCOFINS, vOutro, vNF                 int64
+}
+
+// calculateTotals adds up the item values. Services (items with ISSQN) are totaled in ISSQNtot,
+// so their value, PIS and COFINS stay out of ICMSTot. vNF is calculated from the informed totals,
+// as SEFAZ does: products minus discounts and relieved ICMS, plus ST, freight, insurance,
+// other expenses, II, IPI and services.
+func calculateTotals(d *document) *totals {
+	tot := &d.InfNFe.Total.ICMSTot
+	t := &totals{doc: tot}
+	for _, item := range d.InfNFe.Det {
+		p := item.Prod
+		service := item.Imposto.ISSQN != nil
+		if p.IndTot != "0" && !service {
+			t.vProd += amount(p.VProd)
+		}
+		t.vFrete += amount(p.VFrete)
+		t.vSeg += amount(p.VSeg)
+		t.vDesc += amount(p.VDesc)
+		t.vOutro += amount(p.VOutro)
+
+		for _, group := range item.Imposto.ICMS.Groups {
+			t.vBC += amount(group.VBC)
+			t.vICMS += amount(group.VICMS)
+			t.vBCST += amount(group.VBCST)
+			t.vST += amount(group.VICMSST)
+		}
+		for _, group := range item.Imposto.IPI.Groups {
+			t.vIPI += amount(group.VIPI)
+		}
+		if item.Imposto.II != nil {
+			t.vII += amount(item.Imposto.II.VII)
+		}
+		if service {
+			continue
+		}
+		for _, group := range item.Imposto.PIS.Groups {
+			t.vPIS += amount(group.VPIS)
+		}
+		for _, group := range item.Imposto.COFINS.Groups {
+			t.vCOFINS += amount(group.VCOFINS)
+		}
+	}
+
+	t.vNF = amount(tot.VProd) - amount(tot.VDesc) - amount(tot.VICMSDeson) + amount(tot.VST) + amount(tot.VFCPST) +
+		amount(tot.VFrete) + amount(tot.VSeg) + amount(tot.VOutro) + amount(tot.VII) + amount(tot.VIPI) + amount(tot.VIPIDevol)
+	if services := d.InfNFe.Total.ISSQNtot; services != nil {
+		t.vNF += amount(services.VServ)
+	}
+	return t
+}
+
+// checkTotal returns a check comparing an informed total to the value calculated from the items.
+func checkTotal(field func(t *totals) (string, int64)) func(d *document) []string {
+	return func(d *document) []string {
+		informed, calculated := field(calculateTotals(d))
+		return failIf(amount(informed) != calculated, "informed %s, calculated %s", informed, formatCents(calculated))
+	}
+}
+
+// payments returns the sum of the payments and the change, and whether the document has
+// payments at all ("90 - sem pagamento" is not a payment).
+func payments(d *document) (paid int64, change int64, ok bool) {
+	if d.InfNFe.Pag == nil {
+		return 0, 0, false
+	}
+	for _, detPag := range d.InfNFe.Pag.DetPag {
+		if detPag.TPag == "90" {
+			return 0, 0, false
+		}
+		paid += amount(detPag.VPag)
+	}
+	return paid, amount(d.InfNFe.Pag.VTroco), len(d.InfNFe.Pag.DetPag) > 0
+}
+
+func checkPaymentsBelowTotal(d *document) []string {
+	paid, _, ok := payments(d)
+	if !ok {
+		return none
+	}
+	vNF := amount(d.InfNFe.Total.ICMSTot.VNF)
+	return failIf(paid < vNF, "payments %s, vNF %s", formatCents(paid), formatCents(vNF))
+}
+
+func checkMissingChange(d *document) []string {
+	paid, change, ok := payments(d)
+	if !ok {
+		return none
+	}
+	vNF := amount(d.InfNFe.Total.ICMSTot.VNF)
+	return failIf(paid > vNF && change == 0, "payments %s, vNF %s", formatCents(paid), formatCents(vNF))
+}
+
+func checkChangeValue(d *document) []string {
+	paid, change, ok := payments(d)
+	if !ok || change == 0 {
+		return none
+	}
+	vNF := amount(d.InfNFe.Total.ICMSTot.VNF)
+	return failIf(paid-change != vNF, "payments %s, vTroco %s, vNF %s", formatCents(paid), formatCents(change), formatCents(vNF))
+}
@@ -0,0 +1,261 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateTimeLayout is the layout of the TDateTimeUTC fields (dhEmi, dhRecbto).
+const dateTimeLayout = "2006-01-02T15:04:05-07:00"
+
+// document holds the parts of an NF-e/NFC-e inspected by the rules.
+// Elements are matched by local name, so the namespace is not required.
+type document struct {
+	XMLName xml.Name
+	NFe     nfe      `xml:"NFe"`
+	InfNFe  infNFe   `xml:"infNFe"`
+	ProtNFe *protNFe `xml:"protNFe"`
+
+	// reference is the moment the document is considered received by SEFAZ.
+	reference time.Time
+}
+
+type nfe struct {
+	InfNFe infNFe `xml:"infNFe"`
+}
+
+type protNFe struct {
+	InfProt struct {
+		ChNFe    string `xml:"chNFe"`
+		DhRecbto string `xml:"dhRecbto"`
+	} `xml:"infProt"`
+}
+
+type infNFe struct {
+	ID    string `xml:"Id,attr"`
+	Ide   ide    `xml:"ide"`
+	Emit  emit   `xml:"emit"`
+	Dest  *dest  `xml:"dest"`
+	Det   []det  `xml:"det"`
+	Total total  `xml:"total"`
+	Pag   *pag   `xml:"pag"`
+}
+
+type ide struct {
+	CUF      string  `xml:"cUF"`
+	CNF      string  `xml:"cNF"`
+	Mod      string  `xml:"mod"`
+	Serie    string  `xml:"serie"`
+	NNF      string  `xml:"nNF"`
+	DhEmi    string  `xml:"dhEmi"`
+	TpNF     string  `xml:"tpNF"`
+	IdDest   string  `xml:"idDest"`
+	CMunFG   string  `xml:"cMunFG"`
+	TpImp    string  `xml:"tpImp"`
+	TpEmis   string  `xml:"tpEmis"`
+	CDV      string  `xml:"cDV"`
+	TpAmb    string  `xml:"tpAmb"`
+	FinNFe   string  `xml:"finNFe"`
+	IndFinal string  `xml:"indFinal"`
+	IndPres  string  `xml:"indPres"`
+	NFref    []nfRef `xml:"NFref"`
+}
+
+type nfRef struct {
+	RefNFe string `xml:"refNFe"`
+}
+
+type emit struct {
+	CNPJ  string  `xml:"CNPJ"`
+	CPF   string  `xml:"CPF"`
+	Ender address `xml:"enderEmit"`
+	IE    string  `xml:"IE"`
+	CRT   string  `xml:"CRT"`
+}
+
+type dest struct {
+	CNPJ      string   `xml:"CNPJ"`
+	CPF       string   `xml:"CPF"`
+	XNome     string   `xml:"xNome"`
+	Ender     *address `xml:"enderDest"`
+	IndIEDest string   `xml:"indIEDest"`
+	IE        string   `xml:"IE"`
+}
+
+type address struct {
+	CMun string `xml:"cMun"`
+	UF   string `xml:"UF"`
+}
+
+type det struct {
+	NItem   string  `xml:"nItem,attr"`
+	Prod    prod    `xml:"prod"`
+	Imposto imposto `xml:"imposto"`
+}
+
+type prod struct {
+	CFOP    string `xml:"CFOP"`
+	QCom    string `xml:"qCom"`
+	VUnCom  string `xml:"vUnCom"`
+	VProd   string `xml:"vProd"`
+	QTrib   string `xml:"qTrib"`
+	VUnTrib string `xml:"vUnTrib"`
+	VFrete  string `xml:"vFrete"`
+	VSeg    string `xml:"vSeg"`
+	VDesc   string `xml:"vDesc"`
+	VOutro  string `xml:"vOutro"`
+	IndTot  string `xml:"indTot"`
+}
+
+type imposto struct {
+	ICMS       taxGroups   `xml:"ICMS"`
+	IPI        taxGroups   `xml:"IPI"`
+	II         *taxGroup   `xml:"II"`
+	ISSQN      *taxGroup   `xml:"ISSQN"`
+	PIS        taxGroups   `xml:"PIS"`
+	COFINS     taxGroups   `xml:"COFINS"`
+	ICMSUFDest *icmsUFDest `xml:"ICMSUFDest"`
+}
+
+// icmsUFDest holds the ICMS owed to the destination state on sales to non-contributors (DIFAL).
+type icmsUFDest struct {
+	PICMSInter     string `xml:"pICMSInter"`
+	PICMSInterPart string `xml:"pICMSInterPart"`
+}
+
+// taxGroups holds the group chosen inside a tax element, such as ICMS00 or ICMSSN102 inside ICMS.
+type taxGroups struct {
+	Groups []taxGroup `xml:",any"`
+}
+
+// taxGroup holds the values of any tax group; the fields absent from the group stay empty.
+type taxGroup struct {
+	XMLName    xml.Name
+	Orig       string `xml:"orig"`
+	CST        string `xml:"CST"`
+	CSOSN      string `xml:"CSOSN"`
+	VBC        string `xml:"vBC"`
+	PICMS      string `xml:"pICMS"`
+	VICMS      string `xml:"vICMS"`
+	VICMSDeson string `xml:"vICMSDeson"`
+	VBCST      string `xml:"vBCST"`
+	VICMSST    string `xml:"vICMSST"`
+	VIPI       string `xml:"vIPI"`
+	VII        string `xml:"vII"`
+	VPIS       string `xml:"vPIS"`
+	VCOFINS    string `xml:"vCOFINS"`
+}
+
+type total struct {
+	ICMSTot  icmsTot `xml:"ICMSTot"`
+	ISSQNtot *struct {
+		VServ string `xml:"vServ"`
+	} `xml:"ISSQNtot"`
+}
+
+type icmsTot struct {
+	VBC        string `xml:"vBC"`
+	PICMS      string `xml:"pICMS"`
+	VICMS      string `xml:"vICMS"`
+	VICMSDeson string `xml:"vICMSDeson"`
+	VBCST      string `xml:"vBCST"`
+	VST        string `xml:"vST"`
+	VFCPST     string `xml:"vFCPST"`
+	VProd      string `xml:"vProd"`
+	VFrete     string `xml:"vFrete"`
+	VSeg       string `xml:"vSeg"`
+	VDesc      string `xml:"vDesc"`
+	VII        string `xml:"vII"`
+	VIPI       string `xml:"vIPI"`
+	VIPIDevol  string `xml:"vIPIDevol"`
+	VPIS       string `xml:"vPIS"`
+	VCOFINS    string `xml:"vCOFINS"`
+	VOutro     string `xml:"vOutro"`
+	VNF        string `xml:"vNF"`
+}
+
+type pag struct {
+	DetPag []struct {
+		TPag string `xml:"tPag"`
+		VPag string `xml:"vPag"`
+	} `xml:"detPag"`
+	VTroco string `xml:"vTroco"`
+}
+
+// parseDocument decodes an NF-e or NFC-e, either bare (<NFe>) or authorized (<nfeProc>).
+func parseDocument(data []byte) (*document, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var doc document
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.XMLName.Local == "nfeProc" {
+		doc.InfNFe = doc.NFe.InfNFe
+	}
+	return &doc, nil
+}
+
+// simples reports whether the emitter takes the CSOSN groups: the Simples Nacional (CRT 1) and MEIs (CRT 4).
+func (d *document) simples() bool {
+	return d.InfNFe.Emit.CRT == "1" || d.InfNFe.Emit.CRT == "4"
+}
+
+// emitterDocument returns the emitter CNPJ, or its CPF for individuals.
+func (d *document) emitterDocument() string {
+	if d.InfNFe.Emit.CNPJ != "" {
+		return d.InfNFe.Emit.CNPJ
+	}
+	return d.InfNFe.Emit.CPF
+}
+
+// isNFCe reports whether the document is an NFC-e (model 65).
+func (d *document) isNFCe() bool {
+	return d.InfNFe.Ide.Mod == "65"
+}
+
+// destUF returns the recipient state, or "" when the document has no recipient address.
+func (d *document) destUF() string {
+	if d.InfNFe.Dest == nil || d.InfNFe.Dest.Ender == nil {
+		return ""
+	}
+	return d.InfNFe.Dest.Ender.UF
+}
+
+// parseTime parses a TDateTimeUTC value.
+func parseTime(value string) (time.Time, bool) {
+	t, err := time.Parse(dateTimeLayout, strings.TrimSpace(value))
+	return t, err == nil
+}
+
+// decimal parses a decimal field, treating an absent or invalid value as zero.
+func decimal(value string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// amount parses a monetary field in cents.
+func amount(value string) int64 {
+	return toCents(decimal(value))
+}
+
+// toCents rounds a value to cents.
+func toCents(value float64) int64 {
+	return int64(math.Round(value * 100))
+}
+
+// formatCents formats a value in cents with two decimals.
+func formatCents(value int64) string {
+	return strconv.FormatFloat(float64(value)/100, 'f', 2, 64)
+}
@@ -0,0 +1,192 @@
+// Package rules checks NF-e and NFC-e documents against the business rules SEFAZ applies
+// when authorizing them, as described in the Manual de Orientação do Contribuinte.
+// Each violation carries the cStat and xMotivo SEFAZ would answer with.
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mayckol/brfiscalfaker/pkg/nfs"
+)
+
+// Violation describes a rejection SEFAZ would return for the document.
+type Violation struct {
+	CStat   string
+	Message string
+	Detail  string
+}
+
+// Error implements the error interface.
+func (v Violation) Error() string {
+	if v.Detail == "" {
+		return fmt.Sprintf("%s %s", v.CStat, v.Message)
+	}
+	return fmt.Sprintf("%s %s (%s)", v.CStat, v.Message, v.Detail)
+}
+
+// rule is a numbered SEFAZ validation. check returns one detail per occurrence of the violation.
+type rule struct {
+	cStat   string
+	message string
+	check   func(d *document) []string
+}
+
+// Option defines a function type for Checker configuration options.
+type Option func(*Checker)
+
+// WithReferenceTime returns an Option that sets the moment documents without a protocol
+// are considered received, used by the emission date rules. It defaults to the current time.
+func WithReferenceTime(t time.Time) Option {
+	return func(c *Checker) {
+		c.now = func() time.Time { return t }
+	}
+}
+
+// Checker checks documents against the rules. It remembers the documents it has seen,
+// so that sending the same numbering twice is reported as a duplicate (cStat 204 or 539).
+type Checker struct {
+	now  func() time.Time
+	seen map[string]string
+}
+
+// NewChecker returns a Checker with no documents seen.
+func NewChecker(options ...Option) *Checker {
+	c := &Checker{now: time.Now, seen: make(map[string]string)}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+// Check returns every rule the document violates, in the order SEFAZ applies them,
+// so the first Violation is the cStat the document would be rejected with.
+// It returns nil when the document would be authorized.
+func Check(document []byte, options ...Option) []Violation {
+	return NewChecker(options...).Check(document)
+}
+
+// Check returns every rule the document violates, in the order SEFAZ applies them,
+// including duplicates of the documents previously checked.
+// It returns nil when the document would be authorized.
+func (c *Checker) Check(document []byte) []Violation {
+	doc, err := parseDocument(document)
+	if err != nil {
+		return []Violation{{CStat: "243", Message: "Rejeição: XML Mal Formado", Detail: err.Error()}}
+	}
+
+	var violations []Violation
+	switch doc.XMLName.Local {
+	case "NFe", "nfeProc":
+		tt, err := nfs.DetectTemplateType(document)
+		if err == nil {
+			if errs := nfs.Validate(tt, document); len(errs) > 0 {
+				violations = append(violations, Violation{CStat: "225", Message: "Rejeição: Falha no Schema XML da NFe", Detail: errs[0].Error()})
+			}
+		} else {
+			violations = append(violations, Violation{CStat: "225", Message: "Rejeição: Falha no Schema XML da NFe", Detail: err.Error()})
+		}
+	default:
+		return []Violation{{CStat: "225", Message: "Rejeição: Falha no Schema XML da NFe", Detail: fmt.Sprintf("unexpected root element <%s>", doc.XMLName.Local)}}
+	}
+
+	doc.reference = c.now()
+	if doc.ProtNFe != nil {
+		if received, ok := parseTime(doc.ProtNFe.InfProt.DhRecbto); ok {
+			doc.reference = received
+		}
+	}
+
+	for _, r := range rules {
+		for _, detail := range r.check(doc) {
+			violations = append(violations, Violation{CStat: r.cStat, Message: r.message, Detail: detail})
+		}
+	}
+	violations = append(violations, c.checkDuplicate(doc)...)
+	return violations
+}
+
+// checkDuplicate reports a document whose numbering was already seen, with the same access key (204)
+// or a different one (539), and remembers it otherwise.
+func (c *Checker) checkDuplicate(d *document) []Violation {
+	ide := d.InfNFe.Ide
+	numbering := fmt.Sprintf("%s|%s|%s|%s|%s|%s", ide.TpAmb, ide.CUF, d.emitterDocument(), ide.Mod, ide.Serie, ide.NNF)
+	key := accessKeyOf(d)
+
+	previous, ok := c.seen[numbering]
+	if !ok {
+		c.seen[numbering] = key
+		return nil
+	}
+	if previous == key {
+		return []Violation{{CStat: "204", Message: "Rejeição: Duplicidade de NF-e", Detail: "chNFe " + key}}
+	}
+	return []Violation{{CStat: "539", Message: "Rejeição: Duplicidade de NF-e com diferença na Chave de Acesso", Detail: "chNFe " + previous}}
+}
+
+// rules lists the rules in the order SEFAZ validates them.
+var rules = []rule{
+	{"247", "Rejeição: Sigla da UF do Emitente diverge da UF autorizadora", checkEmitterUF},
+	{"207", "Rejeição: CNPJ do emitente inválido", checkEmitterCNPJ},
+	{"401", "Rejeição: CPF do remetente inválido", checkEmitterCPF},
+	{"209", "Rejeição: IE do emitente inválida", checkEmitterIE},
+	{"208", "Rejeição: CNPJ do destinatário inválido", checkRecipientCNPJ},
+	{"237", "Rejeição: CPF do destinatário inválido", checkRecipientCPF},
+	{"210", "Rejeição: IE do destinatário inválida", checkRecipientIE},
+	{"253", "Rejeição: Digito Verificador da chave de acesso composta inválida", checkAccessKeyDigit},
+	{"502", "Rejeição: Erro na Chave de Acesso - Campo Id não corresponde à concatenação dos campos correspondentes", checkAccessKey},
+	{"703", "Rejeição: Data-Hora de Emissão posterior ao horário de recebimento", checkEmissionAfterReceipt},
+	{"228", "Rejeição: Data de Emissão muito atrasada", checkEmissionTooOld},
+	{"704", "Rejeição: NFC-e com Data-Hora de emissão atrasada", checkNFCeEmissionDelayed},
+	{"270", "Rejeição: Código Município do Fato Gerador: dígito inválido", checkMunicipalityDigit(func(d *document) string { return d.InfNFe.Ide.CMunFG })},
+	{"271", "Rejeição: Código Município do Fato Gerador: difere da UF do emitente", checkMunicipalityUF(func(d *document) (string, string) { return d.InfNFe.Ide.CMunFG, d.InfNFe.Emit.Ender.UF })},
+	{"272", "Rejeição: Código Município do Emitente: dígito inválido", checkMunicipalityDigit(func(d *document) string { return d.InfNFe.Emit.Ender.CMun })},
+	{"273", "Rejeição: Código Município do Emitente: difere da UF do emitente", checkMunicipalityUF(func(d *document) (string, string) { return d.InfNFe.Emit.Ender.CMun, d.InfNFe.Emit.Ender.UF })},
+	{"274", "Rejeição: Código Município do Destinatário: dígito inválido", checkMunicipalityDigit(recipientMunicipality)},
+	{"275", "Rejeição: Código Município do Destinatário: difere da UF do Destinatário", checkMunicipalityUF(func(d *document) (string, string) { return recipientMunicipality(d), d.destUF() })},
+	{"598", "Rejeição: NF-e emitida em ambiente de homologação com Razão Social do destinatário diferente de NF-E EMITIDA EM AMBIENTE DE HOMOLOGACAO - SEM VALOR FISCAL", checkHomologationRecipient},
+	{"709", "Rejeição: NFC-e com formato de DANFE inválido", checkNFCeDANFE},
+	{"710", "Rejeição: NF-e com formato de DANFE inválido", checkNFeDANFE},
+	{"714", "Rejeição: NFC-e com opção de contingência inválida (tpEmis=2, 4 (a critério da UF) ou 5)", checkNFCeEmissionType},
+	{"715", "Rejeição: NFC-e com finalidade inválida", checkNFCePurpose},
+	{"716", "Rejeição: NFC-e em operação não destinada a consumidor final", checkNFCeFinalConsumer},
+	{"717", "Rejeição: NFC-e em operação não presencial", checkNFCePresence},
+	{"696", "Rejeição: Operação com não contribuinte deve indicar operação com consumidor final", checkNonContributorFinalConsumer},
+	{"693", "Rejeição: Alíquota de ICMS superior a definida para a operação interestadual", checkDIFALOwnRate},
+	{"694", "Rejeição: Não informado o grupo de ICMS para a UF de destino", checkDIFALMissing},
+	{"695", "Rejeição: Informado indevidamente o grupo de ICMS para a UF de destino", checkDIFALUnexpected},
+	{"697", "Rejeição: Alíquota interestadual do ICMS com origem diferente do previsto", checkDIFALImportedRate},
+	{"698", "Rejeição: Alíquota interestadual do ICMS incompatível com as UF envolvidas na operação", checkDIFALInterstateRate},
+	{"699", "Rejeição: Percentual do ICMS Interestadual para a UF de destino difere do previsto para o ano da Data de Emissão", checkDIFALPartition},
+	{"772", "Rejeição: Operação Interestadual e UF de destino igual à UF do emitente", checkInterstateSameUF},
+	{"773", "Rejeição: Operação Interna e UF de destino difere da UF do emitente", checkInternalDifferentUF},
+	{"321", "Rejeição: NF-e de devolução de mercadoria não possui documento fiscal referenciado", checkReturnReference},
+	{"254", "Rejeição: NF-e complementar não possui NF referenciada", checkComplementReference},
+	{"327", "Rejeição: CFOP inválido para NF-e com finalidade de devolução", checkReturnCFOP},
+	{"328", "Rejeição: CFOP de devolução informado em NF-e que não tem finalidade de devolução", checkReturnCFOPOutsideReturn},
+	{"518", "Rejeição: CFOP de entrada para NF-e de saída", checkEntryCFOPOnExit},
+	{"519", "Rejeição: CFOP de saída para NF-e de entrada", checkExitCFOPOnEntry},
+	{"521", "Rejeição: CFOP de Operação Estadual e UF do emitente difere da UF do destinatário para destinatário contribuinte do ICMS", checkIntrastateCFOP},
+	{"523", "Rejeição: CFOP não é de Operação Estadual e UF emitente igual à UF destinatário", checkNonIntrastateCFOP},
+	{"590", "Rejeição: Informado CST para emissor do Simples Nacional (CRT=1)", checkCSTForSimples},
+	{"591", "Rejeição: Informado CSOSN para emissor que não é do Simples Nacional (CRT diferente de 1)", checkCSOSNOutsideSimples},
+	{"629", "Rejeição: Valor do Produto difere do produto Valor Unitário de Comercialização e Quantidade Comercial", checkCommercialValue},
+	{"630", "Rejeição: Valor do Produto difere do produto Valor Unitário de Tributação e Quantidade Tributável", checkTaxableValue},
+	{"531", "Rejeição: Total da BC ICMS difere do somatório dos itens", checkTotal(func(t *totals) (string, int64) { return t.doc.VBC, t.vBC })},
+	{"532", "Rejeição: Total do ICMS difere do somatório dos itens", checkTotal(func(t *totals) (string, int64) { return t.doc.VICMS, t.vICMS })},
+	{"533", "Rejeição: Total da BC ICMS-ST difere do somatório dos itens", checkTotal(func(t *totals) (string, int64) { return t.doc.VBCST, t.vBCST })},
+	{"534", "Rejeição: Total do ICMS-ST difere do somatório dos itens", checkTotal(func(t *totals) (string, int64) { return t.doc.VST, t.vST })},
+	{"564", "Rejeição: Total do Produto / Serviço difere do somatório dos itens", checkTotal(func(t *totals) (string, int64) { return t.doc.VProd, t.vProd })},
+	{"535", "Rejeição: Total do Frete difere do somatório dos itens", checkTotal(func(t *totals) (string, int64) { return t.doc.VFrete, t.vFrete })},
+	{"536", "Rejeição: Total do Seguro difere do somatório dos itens", checkTotal(func(t *totals) (string, int64) { return t.doc.VSeg, t.vSeg })},
+	{"537", "Rejeição: Total do Desconto difere do somatório dos itens", checkTotal(func(t *totals) (string, int64) { return t.doc.VDesc, t.vDesc })},
+	{"601", "Rejeição: Total do II difere do somatório dos itens", checkTotal(func(t *totals) (string, int64) { return t.doc.VII, t.vII })},
+	{"538", "Rejeição: Total do IPI difere do somatório dos itens", checkTotal(func(t *totals) (string, int64) { return t.doc.VIPI, t.vIPI })},
+	{"602", "Rejeição: Total do PIS difere do somatório dos itens sujeitos ao ICMS", checkTotal(func(t *totals) (string, int64) { return t.doc.VPIS, t.vPIS })},
+	{"603", "Rejeição: Total do COFINS difere do somatório dos itens sujeitos ao ICMS", checkTotal(func(t *totals) (string, int64) { return t.doc.VCOFINS, t.vCOFINS })},
+	{"604", "Rejeição: Total do vOutro difere do somatório dos itens", checkTotal(func(t *totals) (string, int64) { return t.doc.VOutro, t.vOutro })},
+	{"610", "Rejeição: Total da NF difere do somatório dos Valores compõe o valor Total da NF.", checkTotal(func(t *totals) (string, int64) { return t.doc.VNF, t.vNF })},
+	{"865", "Rejeição: Total dos pagamentos menor que o total da nota", checkPaymentsBelowTotal},
+	{"866", "Rejeição: Ausência de troco quando o valor dos pagamentos informados for maior que o total da nota", checkMissingChange},
+	{"869", "Rejeição: Valor do troco incorreto", checkChangeValue},
+}
@@ -0,0 +1,312 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mayckol/brfiscalfaker/pkg/nfs"
+	"github.com/mayckol/brfiscalfaker/pkg/universe"
+	"github.com/mayckol/brfiscalfaker/utils"
+)
+
+// generatedCase generates documents of the template type with the options, which must be free of
+// violations.
+type generatedCase struct {
+	name      string
+	tt        nfs.TemplateType
+	options   []nfs.Option
+	documents int
+	reference bool // each document references a new NF-e
+}
+
+// generatedCases returns a case for each template type and for each option of the generators.
+func generatedCases() []generatedCase {
+	var cases []generatedCase
+	for _, tt := range []nfs.TemplateType{nfs.NFe, nfs.NFCe, nfs.NFeDevolucao, nfs.NFeComplementar, nfs.NFeAjuste} {
+		cases = append(cases, generatedCase{name: tt.String(), tt: tt, documents: 200})
+	}
+	for situation := nfs.CST00; situation <= nfs.CSOSN900; situation++ {
+		for _, tt := range []nfs.TemplateType{nfs.NFe, nfs.NFCe, nfs.NFeDevolucao} {
+			cases = append(cases, generatedCase{name: situation.String() + "/" + tt.String(), tt: tt,
+				options: []nfs.Option{nfs.WithICMS(situation, nfs.ICMSRandom)}, documents: 10})
+		}
+	}
+	for pis := nfs.PISAliq; pis <= nfs.PISST; pis++ {
+		for _, ipi := range []nfs.IPISituation{nfs.IPITrib, nfs.IPINT} {
+			cases = append(cases, generatedCase{name: pis.String() + "/" + ipi.String(), tt: nfs.NFe,
+				options: []nfs.Option{nfs.WithPIS(pis, nfs.PISRandom), nfs.WithIPI(ipi)}, documents: 10})
+		}
+	}
+	for kind := nfs.RecipientCPF; kind <= nfs.RecipientNone; kind++ {
+		for _, tt := range []nfs.TemplateType{nfs.NFe, nfs.NFCe} {
+			if kind == nfs.RecipientNone && tt == nfs.NFe {
+				continue
+			}
+			cases = append(cases, generatedCase{name: kind.String() + "/" + tt.String(), tt: tt,
+				options: []nfs.Option{nfs.WithRecipientKind(kind)}, documents: 20})
+		}
+	}
+	for profile := nfs.EmitterSimplesNacional; profile <= nfs.EmitterProdutorRural; profile++ {
+		cases = append(cases, generatedCase{name: profile.String(), tt: nfs.NFe,
+			options: []nfs.Option{nfs.WithEmitterProfile(profile), nfs.WithICMS(nfs.ICMSRandom)}, documents: 20})
+	}
+	for contingency := nfs.ContingencyFSDA; contingency <= nfs.ContingencyOfflineNFCe; contingency++ {
+		tt := nfs.NFe
+		if contingency == nfs.ContingencyOfflineNFCe {
+			tt = nfs.NFCe
+		}
+		cases = append(cases, generatedCase{name: contingency.String(), tt: tt,
+			options: []nfs.Option{nfs.WithContingency(contingency), nfs.WithICMS(nfs.ICMSRandom)}, documents: 20})
+	}
+	for operation := nfs.OperationVendaInterna; operation <= nfs.OperationImportacao; operation++ {
+		cases = append(cases, generatedCase{name: operation.String(), tt: nfs.NFe,
+			options: []nfs.Option{nfs.WithOperation(operation), nfs.WithICMS(nfs.ICMSRandom)}, documents: 30})
+	}
+
+	sequence := nfs.NewSequence(1, 1)
+	sequence.SetGapRate(10)
+	u := universe.New(3, 10, 30)
+	return append(cases,
+		generatedCase{name: "ServiceItems", tt: nfs.NFe,
+			options: []nfs.Option{nfs.WithICMS(nfs.ICMSRandom, nfs.ICMSRandom), nfs.WithServiceItems(2, 3)}, documents: 50},
+		generatedCase{name: "ProductSectors", tt: nfs.NFe,
+			options: []nfs.Option{nfs.WithProductSector(nfs.Fuel | nfs.Medicine | nfs.NewVehicle | nfs.Weapon)}, documents: 50},
+		generatedCase{name: "Payments", tt: nfs.NFCe,
+			options: []nfs.Option{nfs.WithPayments(nfs.PaymentCash, nfs.PaymentPIX, nfs.PaymentCash)}, documents: 50},
+		generatedCase{name: "Marketplace/NFe", tt: nfs.NFe, options: []nfs.Option{nfs.WithMarketplace("", "")}, documents: 25},
+		generatedCase{name: "Marketplace/NFCe", tt: nfs.NFCe, options: []nfs.Option{nfs.WithMarketplace("", "")}, documents: 25},
+		generatedCase{name: "Sequence", tt: nfs.NFCe, options: []nfs.Option{nfs.WithSequence(sequence)}, documents: 30},
+		generatedCase{name: "Universe/NFe", tt: nfs.NFe, options: []nfs.Option{nfs.WithUniverse(u)}, documents: 30},
+		generatedCase{name: "Universe/NFCe", tt: nfs.NFCe, options: []nfs.Option{nfs.WithUniverse(u)}, documents: 30},
+		generatedCase{name: "ReferencedInvoice", tt: nfs.NFeDevolucao,
+			options: []nfs.Option{nfs.WithPartialReturn(50)}, documents: 30, reference: true},
+		generatedCase{name: "ReferencedInvoice/NFeComplementar", tt: nfs.NFeComplementar,
+			options: []nfs.Option{nfs.WithICMS(nfs.ICMSRandom, nfs.ICMSRandom)}, documents: 30, reference: true},
+		generatedCase{name: "ReferencedInvoice/NFeAjuste", tt: nfs.NFeAjuste,
+			options: []nfs.Option{nfs.WithICMS(nfs.ICMSRandom, nfs.ICMSRandom)}, documents: 30, reference: true},
+	)
+}
+
+func TestCheck_GeneratedDocuments(t *testing.T) {
+	for _, tc := range generatedCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			generator, err := nfs.NewTemplateGenerator(tc.tt)
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+
+			checker := NewChecker()
+			for i := 0; i < tc.documents; i++ {
+				options := tc.options
+				if tc.reference {
+					original, err := nfs.NewNFeGenerator().Generate(nfs.WithICMS(nfs.ICMSRandom, nfs.ICMSRandom))
+					if err != nil {
+						t.Fatalf("Expected no error, got %v", err)
+					}
+					options = append([]nfs.Option{nfs.WithReferencedInvoice(original)}, options...)
+				}
+				xmlBytes, err := generator.Generate(options...)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+
+				if violations := checker.Check(xmlBytes); len(violations) > 0 {
+					t.Fatalf("Expected no violations, got %d (first: %v)\n%s", len(violations), violations[0], xmlBytes)
+				}
+			}
+		})
+	}
+}
+
+func TestCheck_ReportsViolations(t *testing.T) {
+	xmlBytes, err := nfs.NewNFeGenerator().Generate()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	document := string(xmlBytes)
+	recipient := strings.Index(document, "<dest>")
+	key := document[strings.Index(document, `Id="NFe`)+7:][:44]
+	wrongDV := key[:43] + string('0'+(key[43]-'0'+1)%10)
+
+	tests := []struct {
+		name     string
+		document string
+		cStat    string
+	}{
+		{
+			name:     "schema",
+			document: utils.ReplaceElement(document, "tpAmb", "3"),
+			cStat:    "225",
+		},
+		{
+			name:     "invalid emitter CNPJ",
+			document: utils.ReplaceElement(document, "CNPJ", "11111111111111"),
+			cStat:    "207",
+		},
+		{
+			name:     "invalid emitter IE",
+			document: utils.ReplaceElement(document, "IE", "1234"),
+			cStat:    "209",
+		},
+		{
+			name:     "wrong access key check digit",
+			document: strings.ReplaceAll(document, key, wrongDV),
+			cStat:    "253",
+		},
+		{
+			name:     "access key does not match ide",
+			document: utils.ReplaceElement(document, "cNF", "00000000"),
+			cStat:    "502",
+		},
+		{
+			name:     "product value differs from quantity times unit value",
+			document: utils.ReplaceElement(document, "qCom", "999.0000"),
+			cStat:    "629",
+		},
+		{
+			name:     "total differs from the sum of its parts",
+			document: utils.ReplaceElement(document, "vNF", "0.01"),
+			cStat:    "610",
+		},
+		{
+			name:     "homologation recipient name",
+			document: utils.ReplaceElement(document[:recipient], "tpAmb", "2") + utils.ReplaceElement(document[recipient:], "xNome", "Fulano de Tal"),
+			cStat:    "598",
+		},
+		{
+			name:     "entry CFOP on an exit",
+			document: utils.ReplaceElement(document, "CFOP", "1102"),
+			cStat:    "518",
+		},
+		{
+			name:     "return CFOP outside a return",
+			document: utils.ReplaceElement(document, "CFOP", "5202"),
+			cStat:    "328",
+		},
+		{
+			name:     "change without exceeding payments",
+			document: strings.Replace(document, "</pag>", "<vTroco>1.00</vTroco></pag>", 1),
+			cStat:    "869",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := Check([]byte(tc.document))
+			if !hasCStat(violations, tc.cStat) {
+				t.Errorf("Expected cStat %s, got %v", tc.cStat, violations)
+			}
+		})
+	}
+}
+
+func TestCheck_FaultReports(t *testing.T) {
+	const documentsPerFault = 10
+
+	for fault := nfs.FaultWrongAccessKeyDV; fault <= nfs.FaultCFOPMismatch; fault++ {
+		for _, tt := range []nfs.TemplateType{nfs.NFe, nfs.NFCe, nfs.NFeDevolucao, nfs.NFeComplementar, nfs.NFeAjuste} {
+			t.Run(fault.String()+"/"+tt.String(), func(t *testing.T) {
+				generator, err := nfs.NewTemplateGenerator(tt)
+				if err != nil {
+					t.Fatalf("Failed to create generator: %v", err)
+				}
+				checked := 0
+				for i := 0; i < documentsPerFault; i++ {
+					xmlBytes, err := generator.Generate(nfs.WithFault(fault))
+					if err != nil && strings.Contains(err.Error(), "not supported") {
+						continue // e.g. an NFC-e without a recipient, or an item without quantity
+					}
+					if err != nil {
+						t.Fatalf("Expected no error, got %v", err)
+					}
+
+					report := fault.Report(xmlBytes)
+					if violations := Check(xmlBytes); len(violations) == 0 || violations[0].CStat != report.CStat {
+						t.Fatalf("Expected first cStat %s, got %v\n%s", report.CStat, violations, xmlBytes)
+					}
+					checked++
+				}
+				if checked == 0 {
+					t.Skipf("%v is not supported for %v documents", fault, tt)
+				}
+			})
+		}
+	}
+}
+
+func TestCheck_DIFAL(t *testing.T) {
+	var document string
+	for i := 0; i < 2000 && !strings.Contains(document, "<ICMSUFDest>"); i++ {
+		xmlBytes, err := nfs.NewNFeGenerator().Generate()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		document = string(xmlBytes)
+	}
+	if !strings.Contains(document, "<ICMSUFDest>") {
+		t.Fatalf("Expected an interstate sale to a non-contributor")
+	}
+
+	if violations := Check([]byte(document)); len(violations) > 0 {
+		t.Fatalf("Expected no violations, got %v\n%s", violations, document)
+	}
+	if violations := Check([]byte(utils.ReplaceElement(document, "pICMSInterPart", "80.00"))); !hasCStat(violations, "699") {
+		t.Errorf("Expected cStat 699, got %v", violations)
+	}
+	if violations := Check([]byte(utils.ReplaceElement(document, "indIEDest", "1"))); !hasCStat(violations, "695") {
+		t.Errorf("Expected cStat 695, got %v", violations)
+	}
+}
+
+func TestCheck_MalformedXML(t *testing.T) {
+	violations := Check([]byte("<NFe><infNFe>"))
+	if len(violations) != 1 || violations[0].CStat != "243" {
+		t.Errorf("Expected a single 243 violation, got %v", violations)
+	}
+}
+
+func TestCheck_EmissionTooOld(t *testing.T) {
+	xmlBytes, err := nfs.NewNFeGenerator().Generate()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	violations := Check(xmlBytes, WithReferenceTime(time.Now().AddDate(0, 2, 0)))
+	if !hasCStat(violations, "228") {
+		t.Errorf("Expected cStat 228, got %v", violations)
+	}
+}
+
+func TestChecker_Duplicates(t *testing.T) {
+	xmlBytes, err := nfs.NewNFeGenerator().Generate()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	checker := NewChecker()
+	if violations := checker.Check(xmlBytes); len(violations) > 0 {
+		t.Fatalf("Expected no violations, got %v", violations)
+	}
+	if violations := checker.Check(xmlBytes); !hasCStat(violations, "204") {
+		t.Errorf("Expected cStat 204, got %v", violations)
+	}
+
+	document := string(xmlBytes)
+	key := document[strings.Index(document, `Id="NFe`)+7:][:44]
+	otherKey := key[:35] + "00000000" + key[43:]
+	changed := strings.Replace(utils.ReplaceElement(document, "cNF", "00000000"), key, otherKey, -1)
+	if violations := checker.Check([]byte(changed)); !hasCStat(violations, "539") {
+		t.Errorf("Expected cStat 539, got %v", violations)
+	}
+}
+
+// hasCStat reports whether any of the violations has the cStat.
+func hasCStat(violations []Violation, cStat string) bool {
+	for _, v := range violations {
+		if v.CStat == cStat {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+// Package universe creates a fixed set of companies and consumers that trade with each other, so that
+// the same parties appear with the same data across a batch of generated documents.
+package universe
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/mayckol/brfiscalfaker/pkg/br_documents"
+	"github.com/mayckol/brfiscalfaker/pkg/br_locale"
+)
+
+// Address is the address of a party, with its phone, as in the TEndereco type of the NF-e.
+type Address struct {
+	Street       string // xLgr
+	Number       string // nro
+	Complement   string // xCpl
+	Neighborhood string // xBairro
+	Municipality br_locale.Municipality
+	CEP          string
+	Phone        string // fone, with the area code of the state
+}
+
+// Product is a product sold by a company: a product of the catalog with the code (cProd) and the
+// GTIN the company gives it. Products sold in bulk have no GTIN.
+type Product struct {
+	br_locale.Product
+	Code string
+	GTIN string
+}
+
+// Company is a company of the universe, with its tax regime (CRT) and the products it sells.
+type Company struct {
+	CNPJ      string
+	IE        string
+	Name      string // xNome
+	TradeName string // xFant
+	CRT       string // 1 Simples Nacional, 2 excess of the sublimit, 3 Regime Normal, 4 MEI
+	Address   Address
+	Email     string
+	Products  []Product
+}
+
+// Consumer is a person of the universe, who buys from its companies.
+type Consumer struct {
+	CPF     string
+	Name    string
+	Address Address
+	Email   string
+}
+
+// Universe is a fixed set of companies and consumers. A Universe is not changed after it is created
+// and is safe for concurrent use.
+type Universe struct {
+	companies []Company
+	consumers []Consumer
+}
+
+// regimes are the CRTs of the companies. Most of them are in the Simples Nacional.
+var regimes = []string{"1", "1", "1", "1", "2", "3", "3", "3", "4"}
+
+// New creates a universe of the number of companies and consumers. The same seed gives the same
+// universe. Consumers live in the municipalities of the companies, as their customers.
+func New(seed int64, companies, consumers int) *Universe {
+	r := rand.New(rand.NewSource(seed))
+	documents := br_documents.New(r.Int63())
+	locale := br_locale.New(r.Int63())
+	u := &Universe{}
+
+	cnpjs := make(map[string]bool)
+	for len(u.companies) < companies {
+		company := Company{CNPJ: documents.CNPJ()}
+		if cnpjs[company.CNPJ] {
+			continue
+		}
+		cnpjs[company.CNPJ] = true
+		company.Name = locale.CompanyName()
+		company.TradeName = br_locale.TradeName(company.Name)
+		company.CRT = regimes[r.Intn(len(regimes))]
+		company.Address = newAddress(r, locale, locale.Municipality(""))
+		company.IE = documents.IE(company.Address.Municipality.UF)
+		company.Email = locale.Email(company.Name)
+		company.Products = newProducts(r, documents)
+		u.companies = append(u.companies, company)
+	}
+
+	cpfs := make(map[string]bool)
+	for len(u.consumers) < consumers {
+		consumer := Consumer{CPF: documents.CPF()}
+		if cpfs[consumer.CPF] {
+			continue
+		}
+		cpfs[consumer.CPF] = true
+		consumer.Name = locale.PersonName()
+		municipality := locale.Municipality("")
+		if len(u.companies) > 0 {
+			municipality = u.companies[r.Intn(len(u.companies))].Address.Municipality
+		}
+		consumer.Address = newAddress(r, locale, municipality)
+		consumer.Email = locale.Email(consumer.Name)
+		u.consumers = append(u.consumers, consumer)
+	}
+	return u
+}
+
+// newAddress generates an address in the municipality.
+func newAddress(r *rand.Rand, locale *br_locale.Generator, municipality br_locale.Municipality) Address {
+	return Address{
+		Street:       locale.Street(),
+		Number:       strconv.Itoa(r.Intn(9999) + 1),
+		Complement:   locale.Complement(),
+		Neighborhood: locale.Neighborhood(),
+		Municipality: municipality,
+		CEP:          fmt.Sprintf("%08d", r.Intn(100000000)),
+		Phone:        locale.Phone(municipality.UF),
+	}
+}
+
+// newProducts picks from 4 to 12 products of the catalog, each with its code and GTIN.
+func newProducts(r *rand.Rand, documents *br_documents.Generator) []Product {
+	catalog := br_locale.Products()
+	r.Shuffle(len(catalog), func(i, j int) { catalog[i], catalog[j] = catalog[j], catalog[i] })
+	products := make([]Product, 4+r.Intn(9))
+	for i := range products {
+		products[i] = Product{Product: catalog[i], Code: fmt.Sprintf("%05d", r.Intn(99999)+1)}
+		if !catalog[i].Fractional {
+			products[i].GTIN = documents.GTIN()
+		}
+	}
+	return products
+}
+
+// Companies returns a copy of the companies of the universe.
+func (u *Universe) Companies() []Company {
+	companies := make([]Company, len(u.companies))
+	for i, company := range u.companies {
+		companies[i] = company.clone()
+	}
+	return companies
+}
+
+// clone returns a copy of the company that does not share its products.
+func (c Company) clone() Company {
+	c.Products = append([]Product(nil), c.Products...)
+	return c
+}
+
+// Consumers returns the consumers of the universe.
+func (u *Universe) Consumers() []Consumer {
+	return append([]Consumer(nil), u.consumers...)
+}
+
+// Company returns the company of the universe with the CNPJ.
+func (u *Universe) Company(cnpj string) (Company, bool) {
+	for _, company := range u.companies {
+		if company.CNPJ == cnpj {
+			return company.clone(), true
+		}
+	}
+	return Company{}, false
+}
+
+// Consumer returns the consumer of the universe with the CPF.
+func (u *Universe) Consumer(cpf string) (Consumer, bool) {
+	for _, consumer := range u.consumers {
+		if consumer.CPF == cpf {
+			return consumer, true
+		}
+	}
+	return Consumer{}, false
+}
@@ -0,0 +1,44 @@
+package universe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNew_Deterministic(t *testing.T) {
+	u := New(42, 20, 50)
+	if !reflect.DeepEqual(u, New(42, 20, 50)) {
+		t.Fatalf("Expected the same universe for the same seed")
+	}
+	if reflect.DeepEqual(u.Companies(), New(43, 20, 50).Companies()) {
+		t.Errorf("Expected another universe for another seed")
+	}
+	if len(u.Companies()) != 20 || len(u.Consumers()) != 50 {
+		t.Fatalf("Expected 20 companies and 50 consumers, got %d and %d", len(u.Companies()), len(u.Consumers()))
+	}
+	for _, company := range u.Companies() {
+		if _, ok := u.Company(company.CNPJ); !ok {
+			t.Errorf("Expected to find the company %s", company.CNPJ)
+		}
+	}
+	for _, consumer := range u.Consumers() {
+		if _, ok := u.Consumer(consumer.CPF); !ok {
+			t.Errorf("Expected to find the consumer %s", consumer.CPF)
+		}
+	}
+}
+
+func TestUniverse_Unchanged(t *testing.T) {
+	u := New(1, 3, 0)
+	companies := u.Companies()
+	companies[0].Name = "Changed"
+	companies[0].Products[0].Code = "changed"
+	company, _ := u.Company(companies[0].CNPJ)
+	company.Products[1].Code = "changed"
+
+	for _, company := range u.Companies() {
+		if company.Name == "Changed" || company.Products[0].Code == "changed" || company.Products[1].Code == "changed" {
+			t.Errorf("Expected the universe to be unchanged, got %+v", company)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package utils
+
+import "strings"
+
+// ReplaceElement replaces the text of the first element with the given name. The document is returned
+// as it is when it has no such element.
+func ReplaceElement(document, name, value string) string {
+	start := strings.Index(document, "<"+name+">")
+	end := strings.Index(document, "</"+name+">")
+	if start < 0 || end < start {
+		return document
+	}
+	return document[:start+len(name)+2] + value + document[end:]
+}